@@ -2,19 +2,27 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 
 	botHandlers "github.com/AndrePim/telegram_english_learn_bot/internal/bot"
 	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
 	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
 	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	migrateDown := flag.Int("migrate-down", 0, "откатить указанное количество миграций и выйти")
+	flag.Parse()
+
 	// Загружаем переменные окружения из .env файла (если он существует)
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using system environment variables")
@@ -23,14 +31,25 @@ func main() {
 	// Получаем конфигурацию из переменных окружения
 	config := getConfig()
 
+	// Откат миграций выполняется отдельным запуском без старта бота. База
+	// открывается без миграций, чтобы откат шел от текущей версии схемы,
+	// а не от последней известной бинарнику.
+	if *migrateDown > 0 {
+		db, err := openDatabase(config, repository.WithoutMigrate())
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+
+		if err := db.MigrateDown(*migrateDown); err != nil {
+			log.Fatalf("Failed to roll back migrations: %v", err)
+		}
+		log.Printf("Rolled back %d migration(s)", *migrateDown)
+		return
+	}
+
 	// Подключаемся к базе данных
-	db, err := repository.NewDatabase(
-		config.DBHost,
-		config.DBPort,
-		config.DBUser,
-		config.DBPassword,
-		config.DBName,
-	)
+	db, err := openDatabase(config)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -39,17 +58,30 @@ func main() {
 	// Инициализируем репозитории
 	userRepo := repository.NewUserRepository(db)
 	wordRepo := repository.NewWordRepository(db)
+	reviewLogRepo := repository.NewReviewLogRepository(db)
+	quizSessionRepo := repository.NewQuizSessionRepository(db)
 
 	// Инициализируем сервисы
 	userService := service.NewUserService(userRepo)
-	wordService := service.NewWordService(wordRepo)
+	wordService := service.NewWordService(wordRepo, reviewLogRepo)
+	if err := wordService.SetNewWordRatio(config.NewWordRatio); err != nil {
+		log.Fatalf("Invalid QUIZ_NEW_WORD_RATIO: %v", err)
+	}
+	quizService := service.NewQuizService(wordService, quizSessionRepo)
+	typedQuizService := service.NewTypedQuizService(wordService, userService)
+	addWizardService := service.NewAddWizardService(wordService, userService)
+	wordEditService := service.NewWordEditService(wordService, userService)
+	deckEditService := service.NewDeckEditService(wordService, userService)
 
 	// Инициализируем обработчики бота
-	handlers := botHandlers.NewBotHandlers(userService, wordService)
+	handlers := botHandlers.NewBotHandlers(userService, wordService, quizService, typedQuizService,
+		addWizardService, wordEditService, deckEditService)
 
-	// Создаем бота
+	// Создаем бота. poll_answer перечисляем явно: ответы на опросы теста
+	// приходят отдельными обновлениями без сообщения и callback.
 	opts := []bot.Option{
 		bot.WithDefaultHandler(handlers.DefaultHandler),
+		bot.WithAllowedUpdates(bot.AllowedUpdates{"message", "callback_query", "poll_answer"}),
 	}
 
 	b, err := bot.New(config.BotToken, opts...)
@@ -58,18 +90,59 @@ func main() {
 	}
 
 	// Регистрируем обработчики команд
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/start", bot.MatchTypeExact, handlers.StartHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/help", bot.MatchTypeExact, handlers.HelpHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/add", bot.MatchTypePrefix, handlers.AddHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/words", bot.MatchTypePrefix, handlers.WordsHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/quiz", bot.MatchTypeExact, handlers.QuizHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/review", bot.MatchTypeExact, handlers.ReviewHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/delete", bot.MatchTypePrefix, handlers.DeleteHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/stats", bot.MatchTypeExact, handlers.StatsHandler)
-	b.RegisterHandler(bot.HandlerTypeMessageText, "/image", bot.MatchTypePrefix, handlers.ImageHandler)
+	commands := []struct {
+		name    string
+		match   bot.MatchType
+		handler bot.HandlerFunc
+	}{
+		{"/start", bot.MatchTypePrefix, handlers.StartHandler},
+		{"/help", bot.MatchTypeExact, handlers.HelpHandler},
+		{"/add", bot.MatchTypePrefix, handlers.AddHandler},
+		{"/words", bot.MatchTypePrefix, handlers.WordsHandler},
+		{"/quiz", bot.MatchTypePrefix, handlers.QuizHandler},
+		{"/type", bot.MatchTypeExact, handlers.TypeHandler},
+		{"/review", bot.MatchTypePrefix, handlers.ReviewHandler},
+		{"/delete", bot.MatchTypePrefix, handlers.DeleteHandler},
+		{"/duplicates", bot.MatchTypeExact, handlers.DuplicatesHandler},
+		{"/edit", bot.MatchTypePrefix, handlers.EditHandler},
+		{"/find", bot.MatchTypePrefix, handlers.FindHandler},
+		{"/tags", bot.MatchTypeExact, handlers.TagsHandler},
+		{"/tag", bot.MatchTypePrefix, handlers.TagHandler},
+		{"/untag", bot.MatchTypePrefix, handlers.UntagHandler},
+		{"/decks", bot.MatchTypeExact, handlers.DecksHandler},
+		{"/deck", bot.MatchTypePrefix, handlers.DeckHandler},
+		{"/newdeck", bot.MatchTypePrefix, handlers.NewDeckHandler},
+		{"/move", bot.MatchTypePrefix, handlers.MoveHandler},
+		{"/stats", bot.MatchTypeExact, handlers.StatsHandler},
+		{"/image", bot.MatchTypePrefix, handlers.ImageHandler},
+		{"/settings", bot.MatchTypeExact, handlers.SettingsHandler},
+	}
+	registered := make([]string, 0, len(commands)+3)
+	for _, command := range commands {
+		b.RegisterHandler(bot.HandlerTypeMessageText, command.name, command.match, command.handler)
+		registered = append(registered, command.name)
+	}
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "settings_", bot.MatchTypePrefix, handlers.SettingsCallbackHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "type_", bot.MatchTypePrefix, handlers.TypeCallbackHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "review_", bot.MatchTypePrefix, handlers.ReviewCallbackHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "add_", bot.MatchTypePrefix, handlers.AddCallbackHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "dup_", bot.MatchTypePrefix, handlers.DuplicatesCallbackHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "edit_", bot.MatchTypePrefix, handlers.EditCallbackHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "words_", bot.MatchTypePrefix, handlers.WordsCallbackHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "find_", bot.MatchTypePrefix, handlers.FindCallbackHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "tags_", bot.MatchTypePrefix, handlers.TagsCallbackHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "deck_", bot.MatchTypePrefix, handlers.DeckCallbackHandler)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "import_", bot.MatchTypePrefix, handlers.ImportCallbackHandler)
 	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, "", bot.MatchTypePrefix, handlers.CallbackHandler)
-
-	log.Println("Registered handlers: /start, /help, /add, /words, /quiz, /review, /delete, /stats, /image, callback")
+	b.RegisterHandlerMatchFunc(func(update *models.Update) bool {
+		return update.Message != nil && update.Message.From != nil && update.Message.Document != nil
+	}, handlers.DocumentHandler)
+	b.RegisterHandlerMatchFunc(func(update *models.Update) bool {
+		return update.PollAnswer != nil
+	}, handlers.PollAnswerHandler)
+
+	registered = append(registered, "callback", "document", "poll_answer")
+	log.Printf("Registered handlers: %s", strings.Join(registered, ", "))
 	// Создаем контекст для graceful shutdown
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
@@ -83,22 +156,42 @@ func main() {
 // Config содержит конфигурацию бота и базы данных
 type Config struct {
 	BotToken   string
+	DBDriver   string
+	DBPath     string
 	DBHost     string
 	DBPort     string
 	DBUser     string
 	DBPassword string
 	DBName     string
+
+	NewWordRatio float64 // Доля вопросов теста по новым словам
 }
 
 // getConfig загружает конфигурацию из переменных окружения
 func getConfig() *Config {
 	return &Config{
 		BotToken:   getEnv("BOT_TOKEN", ""),
+		DBDriver:   getEnv("DB_DRIVER", repository.DriverPostgres),
+		DBPath:     getEnv("DB_PATH", "english_bot.db"),
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "5432"),
 		DBUser:     getEnv("DB_USER", "user"),
 		DBPassword: getEnv("DB_PASSWORD", "password"),
 		DBName:     getEnv("DB_NAME", "english_bot_db"),
+
+		NewWordRatio: getEnvFloat("QUIZ_NEW_WORD_RATIO", service.DefaultNewWordRatio),
+	}
+}
+
+// openDatabase подключается к базе данных выбранного драйвера
+func openDatabase(config *Config, opts ...repository.DatabaseOption) (*repository.Database, error) {
+	switch config.DBDriver {
+	case repository.DriverPostgres:
+		return repository.NewDatabase(config.DBHost, config.DBPort, config.DBUser, config.DBPassword, config.DBName, opts...)
+	case repository.DriverSQLite:
+		return repository.NewSQLiteDatabase(config.DBPath, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q", config.DBDriver)
 	}
 }
 
@@ -109,3 +202,17 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvFloat получает числовую переменную окружения или возвращает значение по умолчанию
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return parsed
+}
@@ -0,0 +1,173 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// startAddWizard начинает пошаговое добавление слова
+func (h *BotHandlers) startAddWizard(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if err := h.addWizardService.Start(update.Message.From.ID); err != nil {
+		log.Printf("Failed to start add wizard: %v", err)
+		sendText(ctx, b, update.Message.Chat.ID, "Ошибка при добавлении слова. Попробуйте еще раз.")
+		return
+	}
+
+	sendAddPrompt(ctx, b, update.Message.Chat.ID, &service.AddWizardStep{Step: service.AddStepWord})
+}
+
+// handleAddInput принимает ответ на текущий шаг добавления слова
+func (h *BotHandlers) handleAddInput(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	step, err := h.addWizardService.Input(update.Message.From.ID, update.Message.Text)
+	if err != nil {
+		if errors.Is(err, service.ErrAddFieldRequired) {
+			sendText(ctx, b, chatID, "Это поле обязательно. Введите текст или нажмите «Отмена».")
+			return
+		}
+		if errors.Is(err, service.ErrAddChoiceRequired) {
+			sendText(ctx, b, chatID, "Такое слово уже есть. Выберите вариант кнопкой под сообщением выше.")
+			return
+		}
+		log.Printf("Failed to handle add wizard input: %v", err)
+		sendText(ctx, b, chatID, "Ошибка при добавлении слова. Попробуйте еще раз.")
+		return
+	}
+
+	sendAddPrompt(ctx, b, chatID, step)
+}
+
+// AddCallbackHandler обрабатывает кнопки «Пропустить» и «Отмена» при добавлении слова,
+// а также выбор, что сделать со словом, которое уже есть в словаре (add_dup_<выбор>)
+func (h *BotHandlers) AddCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	userID := callback.From.ID
+
+	msg := callback.Message.Message
+	if msg == nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	if choice, ok := strings.CutPrefix(callback.Data, "add_dup_"); ok {
+		word, err := h.addWizardService.ResolveDuplicate(userID, choice)
+		if err != nil {
+			answerCallback(ctx, b, callback.ID, addWizardErrorText(err))
+			return
+		}
+		answerCallback(ctx, b, callback.ID, "")
+		editText(ctx, b, msg.Chat.ID, msg.ID, duplicateResolvedText(choice, word))
+		return
+	}
+
+	switch callback.Data {
+	case "add_skip":
+		step, err := h.addWizardService.Skip(userID)
+		if err != nil {
+			answerCallback(ctx, b, callback.ID, addWizardErrorText(err))
+			return
+		}
+		answerCallback(ctx, b, callback.ID, "")
+		sendAddPrompt(ctx, b, msg.Chat.ID, step)
+	case "add_cancel":
+		if err := h.addWizardService.Cancel(userID); err != nil {
+			answerCallback(ctx, b, callback.ID, addWizardErrorText(err))
+			return
+		}
+		answerCallback(ctx, b, callback.ID, "")
+		sendText(ctx, b, msg.Chat.ID, "❌ Добавление слова отменено.")
+	}
+}
+
+// sendAddPrompt отправляет вопрос следующего шага или подтверждение сохранения
+func sendAddPrompt(ctx context.Context, b *bot.Bot, chatID int64, step *service.AddWizardStep) {
+	if step.Done() {
+		sendText(ctx, b, chatID, fmt.Sprintf("✅ Слово '%s' добавлено!", step.Draft.Word))
+		return
+	}
+
+	cancel := models.InlineKeyboardButton{Text: "❌ Отмена", CallbackData: "add_cancel"}
+	buttons := []models.InlineKeyboardButton{cancel}
+
+	if step.Step == service.AddStepDuplicate {
+		sendDuplicatePrompt(ctx, b, chatID, step)
+		return
+	}
+
+	var text string
+	switch step.Step {
+	case service.AddStepWord:
+		text = "📝 Введите английское слово или фразу:"
+	case service.AddStepTranslation:
+		text = fmt.Sprintf("Введите перевод для «%s»:", step.Draft.Word)
+	default:
+		text = fmt.Sprintf("Добавьте пример или контекст для «%s» (необязательно):", step.Draft.Word)
+		buttons = []models.InlineKeyboardButton{{Text: "⏭ Пропустить", CallbackData: "add_skip"}, cancel}
+	}
+
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{buttons}},
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// sendDuplicatePrompt предлагает выбрать, что сделать с уже существующим словом
+func sendDuplicatePrompt(ctx context.Context, b *bot.Bot, chatID int64, step *service.AddWizardStep) {
+	text := fmt.Sprintf("⚠️ Слово «%s» уже есть в словаре.\nСейчас: %s — %s\nНовый перевод: %s\n\nЧто сделать?",
+		step.Draft.Word, step.Existing.Word, step.Existing.Translation, step.Draft.Translation)
+
+	keyboard := [][]models.InlineKeyboardButton{
+		{{Text: "👌 Оставить как есть", CallbackData: "add_dup_" + service.DuplicateKeep}},
+		{{Text: "➕ Добавить перевод как вариант", CallbackData: "add_dup_" + service.DuplicateAlternative}},
+		{{Text: "🔁 Заменить перевод", CallbackData: "add_dup_" + service.DuplicateReplace}},
+	}
+
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// duplicateResolvedText описывает, что стало со словом после выбора пользователя
+func duplicateResolvedText(choice string, word *repository.Word) string {
+	switch choice {
+	case service.DuplicateAlternative:
+		return fmt.Sprintf("✅ Перевод добавлен: %s — %s", word.Word, word.Translation)
+	case service.DuplicateReplace:
+		return fmt.Sprintf("✅ Перевод заменен: %s — %s", word.Word, word.Translation)
+	default:
+		return fmt.Sprintf("👌 Слово оставлено без изменений: %s — %s", word.Word, word.Translation)
+	}
+}
+
+// addWizardErrorText возвращает сообщение пользователю для ошибки кнопки добавления слова
+func addWizardErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrNoAddWizard):
+		return "Добавление слова уже завершено. Начните заново командой /add"
+	case errors.Is(err, service.ErrAddFieldRequired):
+		return "Этот шаг нельзя пропустить."
+	case errors.Is(err, service.ErrAddChoiceRequired):
+		return "Сначала выберите, что сделать со словом."
+	default:
+		log.Printf("Failed to handle add wizard button: %v", err)
+		return "Ошибка при добавлении слова. Попробуйте еще раз."
+	}
+}
@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAddWizard_AsksFieldsInOrder(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.AddHandler(context.Background(), b, textUpdate("/add"))
+	if text := api.LastText(t); !strings.Contains(text, "Введите английское слово") {
+		t.Fatalf("Expected word prompt, got %q", text)
+	}
+
+	h.DefaultHandler(context.Background(), b, textUpdate("apple"))
+	if text := api.LastText(t); !strings.Contains(text, "Введите перевод для «apple»") {
+		t.Fatalf("Expected translation prompt, got %q", text)
+	}
+
+	h.DefaultHandler(context.Background(), b, textUpdate("яблоко"))
+	sends := api.Calls("sendMessage")
+	if markup := sends[len(sends)-1].Params["reply_markup"]; !strings.Contains(markup, "add_skip") ||
+		!strings.Contains(markup, "add_cancel") {
+		t.Fatalf("Expected Skip and Cancel buttons on the context step, got %s", markup)
+	}
+
+	h.AddCallbackHandler(context.Background(), b, callbackUpdate("add_skip", ""))
+	if text := api.LastText(t); !strings.Contains(text, "Слово 'apple' добавлено") {
+		t.Errorf("Expected confirmation, got %q", text)
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 1 || words[0].Translation != "яблоко" || words[0].Context != "" {
+		t.Errorf("Unexpected words: %+v", words)
+	}
+
+	// После сохранения обычный текст снова не понятен боту
+	h.DefaultHandler(context.Background(), b, textUpdate("pear"))
+	if text := api.LastText(t); !strings.Contains(text, "не понимаю") {
+		t.Errorf("Expected unknown command reply after the wizard, got %q", text)
+	}
+}
+
+func TestAddWizard_Cancel(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.AddHandler(context.Background(), b, textUpdate("/add"))
+	h.DefaultHandler(context.Background(), b, textUpdate("apple"))
+	h.AddCallbackHandler(context.Background(), b, callbackUpdate("add_cancel", ""))
+
+	if text := api.LastText(t); !strings.Contains(text, "отменено") {
+		t.Errorf("Expected cancel confirmation, got %q", text)
+	}
+	if words, _ := wordService.GetUserWords(testUserID); len(words) != 0 {
+		t.Errorf("Expected no words after cancel, got %+v", words)
+	}
+
+	// Повторное нажатие на старую кнопку только показывает уведомление
+	h.AddCallbackHandler(context.Background(), b, callbackUpdate("add_cancel", ""))
+	answers := api.Calls("answerCallbackQuery")
+	if text := answers[len(answers)-1].Params["text"]; !strings.Contains(text, "уже завершено") {
+		t.Errorf("Expected wizard finished notice, got %q", text)
+	}
+}
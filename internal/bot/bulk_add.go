@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Ограничения отчета о добавлении нескольких слов, чтобы он поместился
+// в одно сообщение Telegram (до 4096 символов)
+const (
+	addReportLines     = 20 // Сколько строк перечислять, остальные только считаются
+	addReportItemLimit = 60 // Длина слова и перевода в строке отчета
+)
+
+// addWords добавляет слова из многострочного сообщения и отчитывается по каждой строке
+func (h *BotHandlers) addWords(ctx context.Context, b *bot.Bot, update *models.Update, text string) {
+	results, err := h.wordService.AddWords(update.Message.From.ID, text)
+	if err != nil {
+		log.Printf("Failed to add words: %v", err)
+		sendText(ctx, b, update.Message.Chat.ID,
+			"Ошибка при добавлении слов. Ни одно слово не добавлено, попробуйте еще раз.")
+		return
+	}
+
+	sendText(ctx, b, update.Message.Chat.ID, addWordsReport(results))
+}
+
+// addWordsReport формирует отчет о добавлении нескольких слов: итоги и первые
+// addReportLines строк
+func addWordsReport(results []service.LineResult) string {
+	counts := make(map[string]int)
+	var lines []string
+	for _, result := range results {
+		counts[result.Status]++
+		switch result.Status {
+		case service.LineAdded:
+			lines = append(lines, fmt.Sprintf("✅ %d. %s — %s", result.Line,
+				truncateRunes(result.Word.Word, addReportItemLimit), truncateRunes(result.Word.Translation, addReportItemLimit)))
+		case service.LineDuplicate:
+			lines = append(lines, fmt.Sprintf("♻️ %d. %s — уже есть в словаре", result.Line,
+				truncateRunes(result.Word.Word, addReportItemLimit)))
+		default:
+			lines = append(lines, fmt.Sprintf("⚠️ %d. Не разобрана: нужен формат «слово - перевод - контекст»", result.Line))
+		}
+	}
+	if len(lines) > addReportLines {
+		more := len(lines) - addReportLines
+		lines = append(lines[:addReportLines], fmt.Sprintf("…и еще %d", more))
+	}
+
+	return fmt.Sprintf("📥 Добавлено: %d, уже было: %d, ошибок: %d\n\n%s", counts[service.LineAdded],
+		counts[service.LineDuplicate], counts[service.LineInvalid], strings.Join(lines, "\n"))
+}
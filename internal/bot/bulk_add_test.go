@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAddHandler_MultipleLines(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+
+	h.AddHandler(context.Background(), b, textUpdate("/add pear - груша\napple - apple-ru\nplum\n\nlemon - лимон - sour"))
+
+	report := api.LastText(t)
+	for _, want := range []string{
+		"Добавлено: 2, уже было: 1, ошибок: 1",
+		"✅ 1. pear — груша",
+		"♻️ 2. apple — уже есть в словаре",
+		"⚠️ 3. Не разобрана",
+		"✅ 5. lemon — лимон",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got %q", want, report)
+		}
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 3 {
+		t.Errorf("Expected 3 words, got %d", len(words))
+	}
+}
+
+func TestAddHandler_LongBatchFitsOneMessage(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, fmt.Sprintf("%s - перевод", strings.Repeat("w", 200)+strconv.Itoa(i)))
+	}
+	for i := 0; i < 100; i++ {
+		lines = append(lines, "строка без перевода")
+	}
+	h.AddHandler(context.Background(), b, textUpdate("/add "+strings.Join(lines, "\n")))
+
+	report := api.LastText(t)
+	if n := utf8.RuneCountInString(report); n > 4096 {
+		t.Errorf("Expected the report to fit one message, got %d characters", n)
+	}
+	for _, want := range []string{"Добавлено: 100, уже было: 0, ошибок: 100", "…и еще 180"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got %q", want, report)
+		}
+	}
+	if words, _ := wordService.GetUserWords(testUserID); len(words) != 100 {
+		t.Errorf("Expected 100 words, got %d", len(words))
+	}
+}
@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// offerSharedDeck показывает колоду по ссылке t.me/<бот>?start=deck_<код>
+// и предлагает импортировать ее копию
+func (h *BotHandlers) offerSharedDeck(ctx context.Context, b *bot.Bot, chatID, userID int64, code string) {
+	deck, err := h.wordService.SharedDeck(code)
+	if err != nil {
+		sendText(ctx, b, chatID, shareErrorText(err))
+		return
+	}
+	if deck.UserID == userID {
+		sendText(ctx, b, chatID, fmt.Sprintf("🔗 Это ссылка на вашу колоду «%s»: отправьте ее тем, с кем хотите "+
+			"поделиться словами. Импортов: %d. Настройки колоды: /decks", deck.Name, deck.Imports))
+		return
+	}
+
+	text := fmt.Sprintf("📥 Вам прислали колоду «%s»\n", deck.Name)
+	if deck.Description != "" {
+		text += deck.Description + "\n"
+	}
+	text += fmt.Sprintf("\nЯзыки: %s → %s\nСлов: %d\n\n", deck.SourceLang, deck.TargetLang, deck.Words)
+	text += "Импортировать копию? Слова начнут изучаться с нуля, а слова, которые у вас уже есть, " +
+		"не повторятся. Что еще умеет бот: /help"
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   text,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "📥 Импортировать", CallbackData: "deck_import_" + code}},
+		}},
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// importSharedDeck обрабатывает кнопку deck_import_<код>: копирует колоду пользователю
+func (h *BotHandlers) importSharedDeck(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, code string) {
+	msg := callback.Message.Message
+
+	result, err := h.wordService.ImportSharedDeck(callback.From.ID, code)
+	if err != nil {
+		answerCallback(ctx, b, callback.ID, shareErrorText(err))
+		return
+	}
+	answerCallback(ctx, b, callback.ID, "Колода импортирована")
+
+	text := fmt.Sprintf("📥 Колода «%s» импортирована и выбрана активной. Слов: %d.", result.Deck.Name, result.Deck.Words)
+	if result.Skipped > 0 {
+		text += fmt.Sprintf("\nУже были в вашем словаре и не скопированы: %d.", result.Skipped)
+	}
+	text += "\n\nНачните с /review или /quiz. Все колоды: /decks"
+	editText(ctx, b, msg.Chat.ID, msg.ID, text)
+}
+
+// shareDeckCallback обрабатывает кнопки deck_share_<id> и deck_unshare_<id>:
+// публикует колоду или отзывает ссылку на нее
+func (h *BotHandlers) shareDeckCallback(userID int64, action string, deckID int) (*repository.Deck, string, error) {
+	if action == "unshare" {
+		deck, err := h.wordService.RevokeDeckShare(userID, deckID)
+		return deck, "🚫 Ссылка отозвана: по ней колоду больше не импортировать.", err
+	}
+	deck, err := h.wordService.ShareDeck(userID, deckID)
+	return deck, "🔗 Ссылка готова: отправьте ее тем, с кем хотите поделиться колодой.", err
+}
+
+// deckShareText описывает ссылку на опубликованную колоду и число импортов
+func deckShareText(ctx context.Context, b *bot.Bot, deck *repository.Deck) string {
+	if deck.ShareCode == "" {
+		if deck.Imports == 0 {
+			return ""
+		}
+		return fmt.Sprintf("Импортов: %d\n", deck.Imports)
+	}
+
+	me, err := b.GetMe(ctx)
+	if err != nil {
+		log.Printf("Failed to get bot info: %v", err)
+		return fmt.Sprintf("Ссылка: /start %s%s\nИмпортов: %d\n", service.DeckSharePayload, deck.ShareCode, deck.Imports)
+	}
+	return fmt.Sprintf("Ссылка: %s\nИмпортов: %d\n", service.DeckShareLink(me.Username, deck.ShareCode), deck.Imports)
+}
+
+// shareErrorText возвращает понятное пользователю описание ошибки ссылки на колоду
+func shareErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrShareNotFound):
+		return "Ссылка на колоду недействительна или отозвана."
+	case errors.Is(err, service.ErrOwnDeck):
+		return "Это ваша колода, импортировать ее не нужно."
+	default:
+		return deckErrorText(err)
+	}
+}
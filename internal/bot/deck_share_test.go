@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStartHandler_ImportsSharedDeck(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "train")
+	deck, _ := wordService.ActiveDeck(testUserID)
+
+	h.DeckCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("deck_share_%d", deck.ID), "🗂"))
+	deck, _ = wordService.GetDeck(testUserID, deck.ID)
+	edits := api.Calls("editMessageText")
+	card := edits[len(edits)-1]
+	if !strings.Contains(card.Params["text"], "https://t.me/test_bot?start=deck_"+deck.ShareCode) ||
+		!strings.Contains(card.Params["reply_markup"], fmt.Sprintf("deck_unshare_%d", deck.ID)) {
+		t.Fatalf("Expected the share link and a revoke button, got %q", card.Params["text"])
+	}
+
+	// Ученик открывает ссылку: бот показывает колоду и кнопку импорта
+	student := textUpdate("/start deck_" + deck.ShareCode)
+	student.Message.From.ID, student.Message.Chat.ID = testUserID+1, testUserID+1
+	h.StartHandler(context.Background(), b, student)
+	sends := api.Calls("sendMessage")
+	offer := sends[len(sends)-1]
+	if !strings.Contains(offer.Params["text"], "Вам прислали колоду «Основная»") ||
+		!strings.Contains(offer.Params["reply_markup"], "deck_import_"+deck.ShareCode) {
+		t.Fatalf("Expected an import offer, got %q", offer.Params["text"])
+	}
+
+	callback := callbackUpdate("deck_import_"+deck.ShareCode, offer.Params["text"])
+	callback.CallbackQuery.From.ID = testUserID + 1
+	h.DeckCallbackHandler(context.Background(), b, callback)
+	if text := api.LastText(t); !strings.Contains(text, "импортирована") || !strings.Contains(text, "Слов: 2") {
+		t.Fatalf("Expected the import report, got %q", text)
+	}
+	if words, _ := wordService.GetDeckWords(testUserID + 1); len(words) != 2 {
+		t.Errorf("Expected the student to get both words, got %v", words)
+	}
+
+	h.DeckHandler(context.Background(), b, textUpdate("/deck"))
+	if text := api.LastText(t); !strings.Contains(text, "Импортов: 1") {
+		t.Errorf("Expected the owner to see one import, got %q", text)
+	}
+
+	h.DeckCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("deck_unshare_%d", deck.ID), "🗂"))
+	h.StartHandler(context.Background(), b, student)
+	if text := api.LastText(t); !strings.Contains(text, "недействительна или отозвана") {
+		t.Errorf("Expected the revoked link to fail, got %q", text)
+	}
+}
+
+func TestStartHandler_OwnSharedDeck(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+	deck, _ := wordService.ActiveDeck(testUserID)
+	deck, _ = wordService.ShareDeck(testUserID, deck.ID)
+
+	h.StartHandler(context.Background(), b, textUpdate("/start deck_"+deck.ShareCode))
+	if text := api.LastText(t); !strings.Contains(text, "ссылка на вашу колоду «Основная»") {
+		t.Errorf("Expected the owner notice, got %q", text)
+	}
+
+	h.StartHandler(context.Background(), b, textUpdate("/start"))
+	if text := api.LastText(t); !strings.Contains(text, "Привет, Test!") {
+		t.Errorf("Expected the welcome message, got %q", text)
+	}
+}
@@ -0,0 +1,500 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// deckFieldNames — названия полей колоды на кнопках и в подсказках /deck
+var deckFieldNames = map[string]string{
+	service.DeckFieldName:        "✏️ Название",
+	service.DeckFieldDescription: "📝 Описание",
+	service.DeckFieldLanguages:   "🌐 Языки",
+}
+
+// deckSchedulerDefault — кнопка алгоритма колоды, который берется из /settings
+const deckSchedulerDefault = "default"
+
+// noDecksText подсказывает, как появляется первая колода
+const noDecksText = "У вас пока нет колод. Колода «" + service.DefaultDeckName + "» появится вместе с первым словом " +
+	"из /add, или создайте свою: /newdeck Название"
+
+// moveUsage подсказывает формат команды /move
+const moveUsage = "Используйте формат: /move [номера] Колода\nПример: /move 1,3 Travel\n\n" +
+	"Номера слов — в списке /words активной колоды."
+
+// DecksHandler обрабатывает команду /decks: показывает колоды пользователя
+// с кнопками выбора активной колоды и настроек
+func (h *BotHandlers) DecksHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+	log.Printf("Received /decks command from user %d", userID)
+
+	decks, err := h.wordService.GetDecks(userID)
+	if err != nil {
+		log.Printf("Failed to get user decks: %v", err)
+		sendText(ctx, b, update.Message.Chat.ID, "Ошибка при получении колод.")
+		return
+	}
+	if len(decks) == 0 {
+		sendText(ctx, b, update.Message.Chat.ID, noDecksText)
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        decksText(decks),
+		ReplyMarkup: decksKeyboard(decks),
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// DeckHandler обрабатывает команду /deck [Название]: без названия показывает
+// активную колоду с настройками, с названием — делает эту колоду активной
+func (h *BotHandlers) DeckHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+	name := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/deck"))
+
+	if name == "" {
+		deck, err := h.wordService.ActiveDeck(userID)
+		if err != nil {
+			log.Printf("Failed to get active deck: %v", err)
+			sendText(ctx, b, chatID, "Ошибка при получении колоды.")
+			return
+		}
+		if deck == nil {
+			sendText(ctx, b, chatID, noDecksText)
+			return
+		}
+		h.sendDeckCard(ctx, b, chatID, deck, "")
+		return
+	}
+
+	deck, err := h.wordService.FindDeck(userID, name)
+	if err == nil {
+		deck, err = h.wordService.UseDeck(userID, deck.ID)
+	}
+	if err != nil {
+		sendText(ctx, b, chatID, deckErrorText(err))
+		return
+	}
+	h.sendDeckCard(ctx, b, chatID, deck, fmt.Sprintf("✅ Активная колода: «%s».", deck.Name))
+}
+
+// NewDeckHandler обрабатывает команду /newdeck Название: создает колоду и делает ее активной
+func (h *BotHandlers) NewDeckHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	name := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/newdeck"))
+
+	if name == "" {
+		sendText(ctx, b, chatID, "Используйте формат: /newdeck Название\nПример: /newdeck Travel")
+		return
+	}
+
+	deck, err := h.wordService.CreateDeck(update.Message.From.ID, name)
+	if err != nil {
+		sendText(ctx, b, chatID, deckErrorText(err))
+		return
+	}
+	h.sendDeckCard(ctx, b, chatID, deck,
+		fmt.Sprintf("✅ Колода «%s» создана и выбрана. Новые слова из /add попадут в нее.", deck.Name))
+}
+
+// MoveHandler обрабатывает команду /move N[,N…] Колода: переносит слова
+// активной колоды в другую колоду
+func (h *BotHandlers) MoveHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	numbers, name, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/move")), " ")
+	name = strings.TrimSpace(name)
+	if numbers == "" || name == "" {
+		sendText(ctx, b, chatID, moveUsage)
+		return
+	}
+
+	deck, err := h.wordService.FindDeck(userID, name)
+	if err != nil {
+		sendText(ctx, b, chatID, deckErrorText(err))
+		return
+	}
+
+	var wordIDs []int
+	for _, number := range strings.Split(numbers, ",") {
+		word, errText := h.wordByNumber(userID, strings.TrimSpace(number))
+		if word == nil {
+			sendText(ctx, b, chatID, errText)
+			return
+		}
+		wordIDs = append(wordIDs, word.ID)
+	}
+
+	if _, err := h.wordService.MoveWords(userID, wordIDs, deck.ID); err != nil {
+		sendText(ctx, b, chatID, deckErrorText(err))
+		return
+	}
+	sendText(ctx, b, chatID, fmt.Sprintf("📦 Слов перенесено в колоду «%s»: %d.", deck.Name, len(wordIDs)))
+}
+
+// DeckCallbackHandler обрабатывает кнопки /decks и /deck. ID колоды всегда
+// идет последним: deck_use_<id> делает колоду активной, deck_show_<id>
+// показывает ее настройки, deck_edit_<поле>_<id> ждет новое значение поля,
+// deck_new_<n>_<id> и deck_sched_<алгоритм>_<id> меняют лимит новых слов и
+// алгоритм, deck_share_<id> и deck_unshare_<id> публикуют колоду и отзывают
+// ссылку, deck_del_<id> спрашивает подтверждение, а deck_delok_<id> удаляет колоду.
+// Кнопка deck_import_<код> из /start импортирует чужую колоду.
+func (h *BotHandlers) DeckCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	userID := callback.From.ID
+
+	msg := callback.Message.Message
+	if msg == nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	if callback.Data == "deck_cancel" {
+		if err := h.deckEditService.Cancel(userID); err != nil {
+			log.Printf("Failed to cancel deck edit: %v", err)
+		}
+		answerCallback(ctx, b, callback.ID, "")
+		editText(ctx, b, msg.Chat.ID, msg.ID, "Изменение колоды отменено. Настройки колоды: /deck")
+		return
+	}
+
+	parts := strings.Split(callback.Data, "_")
+	if len(parts) == 3 && parts[1] == "import" {
+		h.importSharedDeck(ctx, b, callback, parts[2])
+		return
+	}
+	deckID, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || len(parts) < 3 {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	var deck *repository.Deck
+	note := ""
+	switch {
+	case len(parts) == 3 && parts[1] == "show":
+		deck, err = h.wordService.GetDeck(userID, deckID)
+	case len(parts) == 3 && parts[1] == "use":
+		deck, err = h.wordService.UseDeck(userID, deckID)
+		if err == nil {
+			note = fmt.Sprintf("✅ Активная колода: «%s».", deck.Name)
+		}
+	case len(parts) == 4 && parts[1] == "edit":
+		deck, err = h.deckEditService.Start(userID, deckID, parts[2])
+		if err != nil {
+			answerCallback(ctx, b, callback.ID, deckErrorText(err))
+			return
+		}
+		answerCallback(ctx, b, callback.ID, "")
+		sendDeckPrompt(ctx, b, msg.Chat.ID, deck, parts[2])
+		return
+	case len(parts) == 4 && parts[1] == "new":
+		newPerDay, convErr := strconv.Atoi(parts[2])
+		if convErr != nil {
+			answerCallback(ctx, b, callback.ID, "")
+			return
+		}
+		deck, err = h.wordService.SetDeckNewPerDay(userID, deckID, newPerDay)
+	case len(parts) == 4 && parts[1] == "sched":
+		deck, err = h.setDeckScheduler(userID, deckID, parts[2])
+	case len(parts) == 3 && (parts[1] == "share" || parts[1] == "unshare"):
+		deck, note, err = h.shareDeckCallback(userID, parts[1], deckID)
+	case len(parts) == 3 && parts[1] == "del":
+		h.confirmDeckDeletion(ctx, b, callback, deckID)
+		return
+	case len(parts) == 3 && parts[1] == "delok":
+		h.deleteDeck(ctx, b, callback, deckID)
+		return
+	default:
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	if err != nil {
+		answerCallback(ctx, b, callback.ID, deckErrorText(err))
+		return
+	}
+	answerCallback(ctx, b, callback.ID, "")
+
+	_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        h.deckCardText(ctx, b, deck, note),
+		ReplyMarkup: deckKeyboard(deck),
+	})
+	if err != nil {
+		log.Printf("Failed to edit message: %v", err)
+	}
+}
+
+// handleDeckEditInput сохраняет введенное значение поля колоды и снова показывает колоду
+func (h *BotHandlers) handleDeckEditInput(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	deck, err := h.deckEditService.Input(update.Message.From.ID, update.Message.Text)
+	if err != nil {
+		sendText(ctx, b, chatID, deckErrorText(err))
+		return
+	}
+	h.sendDeckCard(ctx, b, chatID, deck, "✅ Сохранено.")
+}
+
+// setDeckScheduler меняет алгоритм колоды; choice — deckSchedulerDefault
+// или имя алгоритма с кнопки
+func (h *BotHandlers) setDeckScheduler(userID int64, deckID int, choice string) (*repository.Deck, error) {
+	scheduler := choice
+	if choice == deckSchedulerDefault {
+		scheduler = ""
+	}
+
+	userScheduler, err := h.userService.GetScheduler(userID)
+	if err != nil {
+		return nil, err
+	}
+	return h.wordService.SetDeckScheduler(userID, deckID, scheduler, userScheduler)
+}
+
+// confirmDeckDeletion спрашивает, точно ли удалить колоду вместе со словами
+func (h *BotHandlers) confirmDeckDeletion(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, deckID int) {
+	msg := callback.Message.Message
+	deck, err := h.wordService.GetDeck(callback.From.ID, deckID)
+	if err != nil {
+		answerCallback(ctx, b, callback.ID, deckErrorText(err))
+		return
+	}
+	answerCallback(ctx, b, callback.ID, "")
+
+	_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text: fmt.Sprintf("🗑 Удалить колоду «%s» вместе со словами (%d)? Прогресс по ним тоже удалится.",
+			deck.Name, deck.Words),
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{Text: "🗑 Да, удалить", CallbackData: fmt.Sprintf("deck_delok_%d", deck.ID)},
+				{Text: "❌ Отмена", CallbackData: fmt.Sprintf("deck_show_%d", deck.ID)},
+			},
+		}},
+	})
+	if err != nil {
+		log.Printf("Failed to edit message: %v", err)
+	}
+}
+
+// deleteDeck удаляет колоду после подтверждения и называет активную колоду
+func (h *BotHandlers) deleteDeck(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, deckID int) {
+	msg := callback.Message.Message
+	userID := callback.From.ID
+
+	active, err := h.wordService.DeleteDeck(userID, deckID)
+	if err == nil {
+		err = h.deckEditService.Cancel(userID)
+	}
+	if err != nil {
+		answerCallback(ctx, b, callback.ID, deckErrorText(err))
+		return
+	}
+	answerCallback(ctx, b, callback.ID, "Колода удалена")
+	editText(ctx, b, msg.Chat.ID, msg.ID,
+		fmt.Sprintf("🗑 Колода удалена. Активная колода: «%s». Все колоды: /decks", active.Name))
+}
+
+// sendDeckCard отправляет колоду с кнопками настроек
+func (h *BotHandlers) sendDeckCard(ctx context.Context, b *bot.Bot, chatID int64, deck *repository.Deck, note string) {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        h.deckCardText(ctx, b, deck, note),
+		ReplyMarkup: deckKeyboard(deck),
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// deckCardText показывает описание и настройки колоды; note — строка
+// о результате последнего действия, если она есть
+func (h *BotHandlers) deckCardText(ctx context.Context, b *bot.Bot, deck *repository.Deck, note string) string {
+	var sb strings.Builder
+	if note != "" {
+		sb.WriteString(note + "\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("🗂 Колода «%s»", deck.Name))
+	if deck.Active {
+		sb.WriteString(" — активная")
+	}
+	sb.WriteString("\n")
+	if deck.Description != "" {
+		sb.WriteString(deck.Description + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("\nЯзыки: %s → %s\nСлов: %d\nНовых слов в день: %d\n",
+		deck.SourceLang, deck.TargetLang, deck.Words, deck.NewPerDay))
+
+	if deck.Scheduler != "" {
+		sb.WriteString(fmt.Sprintf("Алгоритм повторения: %s\n", schedulerNames[deck.Scheduler]))
+	} else {
+		userScheduler, err := h.userService.GetScheduler(deck.UserID)
+		if err != nil {
+			log.Printf("Failed to get scheduler: %v", err)
+		}
+		sb.WriteString(fmt.Sprintf("Алгоритм повторения: как в /settings (%s)\n", schedulerNames[userScheduler]))
+	}
+	sb.WriteString(deckShareText(ctx, b, deck))
+
+	if deck.Active {
+		sb.WriteString("\nНовые слова из /add попадают в эту колоду, по ней идут /quiz, /review и /words.")
+	}
+	return sb.String()
+}
+
+// deckKeyboard возвращает кнопки настроек колоды
+func deckKeyboard(deck *repository.Deck) *models.InlineKeyboardMarkup {
+	var keyboard [][]models.InlineKeyboardButton
+	if !deck.Active {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
+			{Text: "✅ Сделать активной", CallbackData: fmt.Sprintf("deck_use_%d", deck.ID)},
+		})
+	}
+
+	var fields []models.InlineKeyboardButton
+	for _, field := range []string{service.DeckFieldName, service.DeckFieldDescription, service.DeckFieldLanguages} {
+		fields = append(fields, models.InlineKeyboardButton{
+			Text:         deckFieldNames[field],
+			CallbackData: fmt.Sprintf("deck_edit_%s_%d", field, deck.ID),
+		})
+	}
+	keyboard = append(keyboard, fields)
+
+	var limits []models.InlineKeyboardButton
+	for _, n := range service.NewPerDayOptions {
+		text := fmt.Sprintf("%d/день", n)
+		if n == deck.NewPerDay {
+			text = "✓ " + text
+		}
+		limits = append(limits, models.InlineKeyboardButton{
+			Text: text, CallbackData: fmt.Sprintf("deck_new_%d_%d", n, deck.ID),
+		})
+	}
+	keyboard = append(keyboard, limits)
+
+	var schedulers []models.InlineKeyboardButton
+	for _, choice := range []string{deckSchedulerDefault, repository.SchedulerSM2, repository.SchedulerFSRS} {
+		text, current := schedulerNames[choice], choice == deck.Scheduler
+		if choice == deckSchedulerDefault {
+			text, current = "Как в /settings", deck.Scheduler == ""
+		}
+		if current {
+			text = "✓ " + text
+		}
+		schedulers = append(schedulers, models.InlineKeyboardButton{
+			Text: text, CallbackData: fmt.Sprintf("deck_sched_%s_%d", choice, deck.ID),
+		})
+	}
+	keyboard = append(keyboard, schedulers)
+
+	share := models.InlineKeyboardButton{Text: "🔗 Поделиться", CallbackData: fmt.Sprintf("deck_share_%d", deck.ID)}
+	if deck.ShareCode != "" {
+		share = models.InlineKeyboardButton{Text: "🚫 Отозвать ссылку", CallbackData: fmt.Sprintf("deck_unshare_%d", deck.ID)}
+	}
+	keyboard = append(keyboard, []models.InlineKeyboardButton{
+		share,
+		{Text: "🗑 Удалить колоду", CallbackData: fmt.Sprintf("deck_del_%d", deck.ID)},
+	})
+	return &models.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// sendDeckPrompt просит ввести новое значение поля колоды field
+func sendDeckPrompt(ctx context.Context, b *bot.Bot, chatID int64, deck *repository.Deck, field string) {
+	var text string
+	switch field {
+	case service.DeckFieldName:
+		text = fmt.Sprintf("Введите новое название колоды «%s»:", deck.Name)
+	case service.DeckFieldDescription:
+		text = fmt.Sprintf("Введите описание колоды «%s» или «%s», чтобы удалить его:",
+			deck.Name, service.ClearContextInput)
+	default:
+		text = fmt.Sprintf("Введите языки колоды «%s» — язык слов и язык перевода, например: en-ru", deck.Name)
+	}
+
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   text,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "❌ Отмена", CallbackData: "deck_cancel"}},
+		}},
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// decksText перечисляет колоды с числом слов, отмечая активную
+func decksText(decks []*repository.Deck) string {
+	var sb strings.Builder
+	sb.WriteString("🗂 Ваши колоды:\n\n")
+	for _, deck := range decks {
+		mark := "•"
+		if deck.Active {
+			mark = "✅"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s — %d сл. (%s → %s)\n",
+			mark, deck.Name, deck.Words, deck.SourceLang, deck.TargetLang))
+	}
+	sb.WriteString("\nАктивная колода получает слова из /add, по ней идут /quiz, /review и /words. " +
+		"Выбрать колоду — кнопкой ниже, создать новую — /newdeck Название.")
+	return sb.String()
+}
+
+// decksKeyboard возвращает для каждой колоды кнопки выбора и настроек
+func decksKeyboard(decks []*repository.Deck) *models.InlineKeyboardMarkup {
+	keyboard := make([][]models.InlineKeyboardButton, 0, len(decks))
+	for _, deck := range decks {
+		text := truncateRunes(deck.Name, 30)
+		if deck.Active {
+			text = "✅ " + text
+		}
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
+			{Text: text, CallbackData: fmt.Sprintf("deck_use_%d", deck.ID)},
+			{Text: "⚙️", CallbackData: fmt.Sprintf("deck_show_%d", deck.ID)},
+		})
+	}
+	return &models.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// deckErrorText возвращает понятное пользователю описание ошибки работы с колодами
+func deckErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrDeckNotFound):
+		return "Колода не найдена. Список колод: /decks"
+	case errors.Is(err, service.ErrDeckExists):
+		return "Колода с таким названием уже есть. Список колод: /decks"
+	case errors.Is(err, service.ErrInvalidDeckName):
+		return fmt.Sprintf("Неверное название колоды: нужна одна строка длиной до %d символов.",
+			service.MaxDeckNameLength)
+	case errors.Is(err, service.ErrDeckDescription):
+		return fmt.Sprintf("Описание слишком длинное: до %d символов.", service.MaxDeckDescriptionLength)
+	case errors.Is(err, service.ErrInvalidLanguages):
+		return "Не удалось разобрать языки. Введите два кода языка, например: en-ru"
+	case errors.Is(err, service.ErrLastDeck):
+		return "Нельзя удалить единственную колоду."
+	case errors.Is(err, service.ErrNoDeckEdit):
+		return "Изменение колоды уже завершено. Настройки колоды: /deck"
+	default:
+		log.Printf("Failed to change deck: %v", err)
+		return "Ошибка при изменении колоды. Попробуйте позже."
+	}
+}
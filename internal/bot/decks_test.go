@@ -0,0 +1,149 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestNewDeckHandler_AddsToActiveDeck(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.DecksHandler(context.Background(), b, textUpdate("/decks"))
+	if text := api.LastText(t); !strings.Contains(text, "нет колод") {
+		t.Errorf("Expected no decks message, got %q", text)
+	}
+
+	addWords(t, wordService, "apple")
+	h.NewDeckHandler(context.Background(), b, textUpdate("/newdeck Travel"))
+	if text := api.LastText(t); !strings.Contains(text, "«Travel» создана") {
+		t.Fatalf("Expected the new deck card, got %q", text)
+	}
+	h.AddHandler(context.Background(), b, textUpdate("/add train - поезд"))
+
+	h.WordsHandler(context.Background(), b, textUpdate("/words"))
+	if text := api.LastText(t); !strings.Contains(text, "Колода «Travel»: 1–1 из 1") ||
+		!strings.Contains(text, "1. train") || strings.Contains(text, "apple") {
+		t.Fatalf("Expected only train in Travel, got %q", text)
+	}
+
+	h.DecksHandler(context.Background(), b, textUpdate("/decks"))
+	sends := api.Calls("sendMessage")
+	result := sends[len(sends)-1]
+	if !strings.Contains(result.Params["text"], "• Основная — 1 сл.") ||
+		!strings.Contains(result.Params["text"], "✅ Travel — 1 сл.") {
+		t.Errorf("Expected both decks with Travel active, got %q", result.Params["text"])
+	}
+	decks, _ := wordService.GetDecks(testUserID)
+	if use := fmt.Sprintf("deck_use_%d", decks[0].ID); !strings.Contains(result.Params["reply_markup"], use) {
+		t.Errorf("Expected switch button %q, got %s", use, result.Params["reply_markup"])
+	}
+
+	h.NewDeckHandler(context.Background(), b, textUpdate("/newdeck travel"))
+	if text := api.LastText(t); !strings.Contains(text, "уже есть") {
+		t.Errorf("Expected duplicate deck message, got %q", text)
+	}
+}
+
+func TestDeckHandler_SwitchAndMove(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear")
+	h.NewDeckHandler(context.Background(), b, textUpdate("/newdeck Fruit"))
+
+	h.DeckHandler(context.Background(), b, textUpdate("/deck основная"))
+	if text := api.LastText(t); !strings.Contains(text, "Активная колода: «Основная»") {
+		t.Fatalf("Expected the default deck to become active, got %q", text)
+	}
+
+	h.MoveHandler(context.Background(), b, textUpdate("/move 1,2 fruit"))
+	if text := api.LastText(t); !strings.Contains(text, "«Fruit»: 2") {
+		t.Fatalf("Expected two words to move, got %q", text)
+	}
+	if count, _ := wordService.CountWords(testUserID, 0); count != 0 {
+		t.Errorf("Expected the default deck to be empty, got %d", count)
+	}
+
+	h.MoveHandler(context.Background(), b, textUpdate("/move 1 Fruit"))
+	if text := api.LastText(t); !strings.Contains(text, "Неверный номер") {
+		t.Errorf("Expected wrong number message, got %q", text)
+	}
+	h.MoveHandler(context.Background(), b, textUpdate("/move 1 Food"))
+	if text := api.LastText(t); !strings.Contains(text, "Колода не найдена") {
+		t.Errorf("Expected deck not found message, got %q", text)
+	}
+	h.DeckHandler(context.Background(), b, textUpdate("/deck"))
+	if text := api.LastText(t); !strings.Contains(text, "🗂 Колода «Основная» — активная") {
+		t.Errorf("Expected the active deck card, got %q", text)
+	}
+}
+
+func TestDeckCallbackHandler_Settings(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+	deck, _ := wordService.ActiveDeck(testUserID)
+
+	h.DeckCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("deck_new_5_%d", deck.ID), "🗂"))
+	edits := api.Calls("editMessageText")
+	card := edits[len(edits)-1]
+	if !strings.Contains(card.Params["text"], "Новых слов в день: 5") ||
+		!strings.Contains(card.Params["reply_markup"], "✓ 5/день") {
+		t.Errorf("Expected the new limit on the card, got %q", card.Params["text"])
+	}
+
+	h.DeckCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("deck_sched_fsrs_%d", deck.ID), "🗂"))
+	if text := api.LastText(t); !strings.Contains(text, "Алгоритм повторения: FSRS") {
+		t.Errorf("Expected the deck scheduler on the card, got %q", text)
+	}
+	if deck, _ = wordService.GetDeck(testUserID, deck.ID); deck.Scheduler != repository.SchedulerFSRS {
+		t.Errorf("Expected the deck to use FSRS, got %q", deck.Scheduler)
+	}
+
+	h.DeckCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("deck_edit_name_%d", deck.ID), "🗂"))
+	if text := api.LastText(t); !strings.Contains(text, "новое название") {
+		t.Fatalf("Expected the name prompt, got %q", text)
+	}
+	h.DefaultHandler(context.Background(), b, textUpdate("Fruit"))
+	if text := api.LastText(t); !strings.Contains(text, "Сохранено") || !strings.Contains(text, "«Fruit»") {
+		t.Errorf("Expected the renamed deck card, got %q", text)
+	}
+}
+
+func TestDeckCallbackHandler_Delete(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+	mainDeck, _ := wordService.ActiveDeck(testUserID)
+
+	h.DeckCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("deck_delok_%d", mainDeck.ID), "🗂"))
+	answers := api.Calls("answerCallbackQuery")
+	if last := answers[len(answers)-1]; !strings.Contains(last.Params["text"], "единственную") {
+		t.Fatalf("Expected the last deck to stay, got %q", last.Params["text"])
+	}
+
+	h.NewDeckHandler(context.Background(), b, textUpdate("/newdeck Travel"))
+	addWords(t, wordService, "train")
+	travel, _ := wordService.ActiveDeck(testUserID)
+
+	h.DeckCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("deck_del_%d", travel.ID), "🗂"))
+	edits := api.Calls("editMessageText")
+	confirm := edits[len(edits)-1]
+	if !strings.Contains(confirm.Params["text"], "Удалить колоду «Travel»") ||
+		!strings.Contains(confirm.Params["reply_markup"], fmt.Sprintf("deck_delok_%d", travel.ID)) {
+		t.Fatalf("Expected a confirmation, got %q", confirm.Params["text"])
+	}
+
+	h.DeckCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("deck_delok_%d", travel.ID), "🗂"))
+	if text := api.LastText(t); !strings.Contains(text, "Активная колода: «Основная»") {
+		t.Errorf("Expected the default deck to become active, got %q", text)
+	}
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 1 || words[0].Word != "apple" {
+		t.Errorf("Expected train to be deleted with the deck, got %v", words)
+	}
+}
@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// DuplicatesHandler обрабатывает команду /duplicates: показывает слова, которые
+// различаются только регистром или пробелами, и предлагает их объединить
+func (h *BotHandlers) DuplicatesHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	groups, err := h.wordService.FindDuplicates(update.Message.From.ID)
+	if err != nil {
+		log.Printf("Failed to find duplicates: %v", err)
+		sendText(ctx, b, chatID, "Ошибка при поиске дубликатов.")
+		return
+	}
+	if len(groups) == 0 {
+		sendText(ctx, b, chatID, "👍 Дубликатов нет: каждое слово встречается в словаре один раз.")
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   duplicatesText(groups),
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "🔗 Объединить все", CallbackData: "dup_merge"}},
+		}},
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// DuplicatesCallbackHandler обрабатывает кнопку «Объединить все». Дубликаты ищутся
+// заново, поэтому повторное нажатие ничего не меняет.
+func (h *BotHandlers) DuplicatesCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	if callback.Data != "dup_merge" {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	groups, removed, err := h.wordService.MergeDuplicates(callback.From.ID)
+	if err != nil {
+		log.Printf("Failed to merge duplicates: %v", err)
+		answerCallback(ctx, b, callback.ID, "Ошибка при объединении. Попробуйте еще раз.")
+		return
+	}
+
+	answerCallback(ctx, b, callback.ID, "")
+	if msg := callback.Message.Message; msg != nil {
+		text := "👍 Дубликатов уже нет."
+		if groups > 0 {
+			text = fmt.Sprintf("🔗 Объединено слов: %d, удалено лишних карточек: %d.", groups, removed)
+		}
+		editText(ctx, b, msg.Chat.ID, msg.ID, text)
+	}
+}
+
+// duplicatesText перечисляет группы дубликатов: первой идет карточка,
+// которая останется, — с лучшей историей повторений
+func duplicatesText(groups []service.DuplicateGroup) string {
+	var sb strings.Builder
+	sb.WriteString("♻️ Найдены повторяющиеся слова:\n")
+	for _, group := range groups {
+		sb.WriteString(fmt.Sprintf("\n✅ %s — %s (повторений: %d)\n",
+			group.Keep.Word, group.Keep.Translation, group.Keep.Repetitions))
+		for _, duplicate := range group.Duplicates {
+			sb.WriteString(fmt.Sprintf("   ➖ %s — %s\n", duplicate.Word, duplicate.Translation))
+		}
+	}
+	sb.WriteString("\nПри объединении останется карточка с лучшей историей, " +
+		"переводы дубликатов добавятся к ней как варианты, а ответы в журнале перейдут к ней.")
+	return sb.String()
+}
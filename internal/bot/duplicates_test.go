@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestAddHandler_DuplicateOffersChoice(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+
+	h.AddHandler(context.Background(), b, textUpdate("/add Apple - яблоня"))
+
+	sends := api.Calls("sendMessage")
+	last := sends[len(sends)-1]
+	if !strings.Contains(last.Params["text"], "уже есть") {
+		t.Errorf("Expected duplicate warning, got %q", last.Params["text"])
+	}
+	for _, data := range []string{"add_dup_keep", "add_dup_alt", "add_dup_replace"} {
+		if !strings.Contains(last.Params["reply_markup"], data) {
+			t.Errorf("Expected button %q, got %s", data, last.Params["reply_markup"])
+		}
+	}
+
+	// Текст вместо выбора не теряет черновик
+	h.DefaultHandler(context.Background(), b, textUpdate("яблоня"))
+	if text := api.LastText(t); !strings.Contains(text, "Выберите вариант") {
+		t.Errorf("Expected a hint to use the buttons, got %q", text)
+	}
+
+	h.AddCallbackHandler(context.Background(), b, callbackUpdate("add_dup_alt", last.Params["text"]))
+	if text := api.LastText(t); !strings.Contains(text, "apple — apple-ru, яблоня") {
+		t.Errorf("Expected the alternative to be added, got %q", text)
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 1 {
+		t.Errorf("Expected a single card, got %d", len(words))
+	}
+}
+
+func TestDuplicatesHandler_NoDuplicates(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear")
+
+	h.DuplicatesHandler(context.Background(), b, textUpdate("/duplicates"))
+
+	if text := api.LastText(t); !strings.Contains(text, "Дубликатов нет") {
+		t.Errorf("Expected no duplicates message, got %q", text)
+	}
+}
+
+func TestDuplicatesHandler_MergesGroups(t *testing.T) {
+	h, wordService, db := newTestHandlersWithDB(t)
+	b, api := newTestBot(t)
+
+	// Дубликаты, сохраненные до появления проверки в AddWord
+	err := repository.NewMemoryWordRepository(db).SaveWords([]*repository.Word{
+		{UserID: testUserID, Word: "apple", Translation: "яблоко"},
+		{UserID: testUserID, Word: "Apple", Translation: "яблоня"},
+		{UserID: testUserID, Word: "pear", Translation: "груша"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save words: %v", err)
+	}
+
+	h.DuplicatesHandler(context.Background(), b, textUpdate("/duplicates"))
+	sends := api.Calls("sendMessage")
+	last := sends[len(sends)-1]
+	if !strings.Contains(last.Params["text"], "Apple — яблоня") || strings.Contains(last.Params["text"], "pear") {
+		t.Errorf("Expected only the apple group, got %q", last.Params["text"])
+	}
+	if !strings.Contains(last.Params["reply_markup"], "dup_merge") {
+		t.Errorf("Expected merge button, got %s", last.Params["reply_markup"])
+	}
+
+	h.DuplicatesCallbackHandler(context.Background(), b, callbackUpdate("dup_merge", last.Params["text"]))
+	if text := api.LastText(t); !strings.Contains(text, "удалено лишних карточек: 1") {
+		t.Errorf("Expected merge report, got %q", text)
+	}
+
+	// Повторное нажатие ничего не удаляет
+	h.DuplicatesCallbackHandler(context.Background(), b, callbackUpdate("dup_merge", last.Params["text"]))
+	if text := api.LastText(t); !strings.Contains(text, "Дубликатов уже нет") {
+		t.Errorf("Expected nothing left to merge, got %q", text)
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 2 {
+		t.Errorf("Expected apple and pear to remain, got %d words", len(words))
+	}
+}
@@ -0,0 +1,206 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// editFieldNames — названия полей слова на кнопках и в подсказках /edit
+var editFieldNames = map[string]string{
+	service.EditFieldWord:        "Слово",
+	service.EditFieldTranslation: "Перевод",
+	service.EditFieldContext:     "Контекст",
+}
+
+// EditHandler обрабатывает команду /edit N: показывает слово с кнопками полей
+func (h *BotHandlers) EditHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	text := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/edit"))
+
+	if text == "" {
+		sendText(ctx, b, chatID, "Используйте формат: /edit [номер]\nПример: /edit 1\n\n"+
+			"Для просмотра номеров слов используйте /words")
+		return
+	}
+
+	word, errText := h.wordByNumber(update.Message.From.ID, text)
+	if word == nil {
+		sendText(ctx, b, chatID, errText)
+		return
+	}
+
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        editCardText(word, ""),
+		ReplyMarkup: editKeyboard(word),
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// EditCallbackHandler обрабатывает кнопки /edit: edit_field_<поле>_<слово> ждет
+// новое значение поля, edit_reset_<слово> сбрасывает прогресс, edit_done закрывает
+// редактирование
+func (h *BotHandlers) EditCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	userID := callback.From.ID
+
+	msg := callback.Message.Message
+	if msg == nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	if callback.Data == "edit_done" {
+		if err := h.wordEditService.Cancel(userID); err != nil {
+			log.Printf("Failed to cancel word edit: %v", err)
+		}
+		answerCallback(ctx, b, callback.ID, "")
+		editText(ctx, b, msg.Chat.ID, msg.ID, "✅ Редактирование завершено.")
+		return
+	}
+
+	parts := strings.Split(callback.Data, "_")
+	wordID, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	switch {
+	case len(parts) == 4 && parts[1] == "field":
+		word, err := h.wordEditService.Start(userID, wordID, parts[2])
+		if err != nil {
+			answerCallback(ctx, b, callback.ID, editErrorText(err))
+			return
+		}
+		answerCallback(ctx, b, callback.ID, "")
+		sendEditPrompt(ctx, b, msg.Chat.ID, word, parts[2])
+	case len(parts) == 3 && parts[1] == "reset":
+		word, err := h.wordService.ResetProgress(userID, wordID)
+		if err != nil {
+			answerCallback(ctx, b, callback.ID, editErrorText(err))
+			return
+		}
+		answerCallback(ctx, b, callback.ID, "Прогресс сброшен")
+		_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        editCardText(word, "🔄 Прогресс сброшен: слово снова новое."),
+			ReplyMarkup: editKeyboard(word),
+		})
+		if err != nil {
+			log.Printf("Failed to edit message: %v", err)
+		}
+	default:
+		answerCallback(ctx, b, callback.ID, "")
+	}
+}
+
+// handleEditInput сохраняет введенное значение поля и снова показывает слово
+func (h *BotHandlers) handleEditInput(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	word, err := h.wordEditService.Input(update.Message.From.ID, update.Message.Text)
+	if err != nil {
+		sendText(ctx, b, chatID, editErrorText(err))
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        editCardText(word, "✅ Сохранено."),
+		ReplyMarkup: editKeyboard(word),
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// sendEditPrompt просит ввести новое значение поля field
+func sendEditPrompt(ctx context.Context, b *bot.Bot, chatID int64, word *repository.Word, field string) {
+	var text string
+	switch field {
+	case service.EditFieldWord:
+		text = fmt.Sprintf("Введите новое написание слова «%s»:", word.Word)
+	case service.EditFieldTranslation:
+		text = fmt.Sprintf("Введите новый перевод для «%s»:", word.Word)
+	default:
+		text = fmt.Sprintf("Введите новый контекст для «%s» или «%s», чтобы удалить его:",
+			word.Word, service.ClearContextInput)
+	}
+
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   text,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "❌ Отмена", CallbackData: "edit_done"}},
+		}},
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// editCardText показывает поля слова и его расписание; note — строка
+// о результате последнего действия, если она есть
+func editCardText(word *repository.Word, note string) string {
+	var sb strings.Builder
+	if note != "" {
+		sb.WriteString(note + "\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("✏️ %s — %s\n", word.Word, word.Translation))
+	if word.Context != "" {
+		sb.WriteString(fmt.Sprintf("Контекст: %s\n", word.Context))
+	}
+	sb.WriteString(fmt.Sprintf("Интервал: %d дн., следующее повторение: %s\n\n",
+		word.Interval, word.NextReview.Format("02.01.2006")))
+	sb.WriteString("Что изменить? Расписание повторений сохранится.")
+	return sb.String()
+}
+
+// editKeyboard возвращает кнопки полей слова, сброса прогресса и завершения
+func editKeyboard(word *repository.Word) *models.InlineKeyboardMarkup {
+	var fields []models.InlineKeyboardButton
+	for _, field := range []string{service.EditFieldWord, service.EditFieldTranslation, service.EditFieldContext} {
+		fields = append(fields, models.InlineKeyboardButton{
+			Text:         editFieldNames[field],
+			CallbackData: fmt.Sprintf("edit_field_%s_%d", field, word.ID),
+		})
+	}
+
+	return &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+		fields,
+		{{Text: "🔄 Сбросить прогресс", CallbackData: fmt.Sprintf("edit_reset_%d", word.ID)}},
+		{{Text: "✅ Готово", CallbackData: "edit_done"}},
+	}}
+}
+
+// editErrorText возвращает сообщение пользователю для ошибки редактирования
+func editErrorText(err error) string {
+	var duplicate *service.DuplicateWordError
+	switch {
+	case errors.As(err, &duplicate):
+		return fmt.Sprintf("Слово «%s» уже есть в словаре. Введите другое написание или нажмите «Отмена».",
+			duplicate.Existing.Word)
+	case errors.Is(err, service.ErrAddFieldRequired):
+		return "Это поле не может быть пустым. Введите текст или нажмите «Отмена»."
+	case errors.Is(err, service.ErrWordNotFound):
+		return "Слово не найдено. Посмотрите список командой /words"
+	case errors.Is(err, service.ErrNoWordEdit):
+		return "Редактирование уже завершено. Начните заново командой /edit"
+	default:
+		log.Printf("Failed to edit word: %v", err)
+		return "Ошибка при изменении слова. Попробуйте еще раз."
+	}
+}
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEditHandler_ChangesTranslation(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+	words, _ := wordService.GetUserWords(testUserID)
+	before := words[0]
+
+	h.EditHandler(context.Background(), b, textUpdate("/edit 1"))
+	sends := api.Calls("sendMessage")
+	card := sends[len(sends)-1]
+	field := fmt.Sprintf("edit_field_translation_%d", before.ID)
+	if !strings.Contains(card.Params["reply_markup"], field) ||
+		!strings.Contains(card.Params["reply_markup"], fmt.Sprintf("edit_reset_%d", before.ID)) {
+		t.Fatalf("Expected field and reset buttons, got %s", card.Params["reply_markup"])
+	}
+
+	h.EditCallbackHandler(context.Background(), b, callbackUpdate(field, card.Params["text"]))
+	if text := api.LastText(t); !strings.Contains(text, "новый перевод") {
+		t.Errorf("Expected translation prompt, got %q", text)
+	}
+
+	h.DefaultHandler(context.Background(), b, textUpdate("яблоко"))
+	if text := api.LastText(t); !strings.Contains(text, "Сохранено") || !strings.Contains(text, "apple — яблоко") {
+		t.Errorf("Expected the updated card, got %q", text)
+	}
+
+	after, _ := wordService.GetWord(testUserID, before.ID)
+	if after.Translation != "яблоко" || !after.NextReview.Equal(before.NextReview) || after.Interval != before.Interval {
+		t.Errorf("Expected translation to change and schedule to stay, got %+v", after)
+	}
+
+	// После сохранения текст снова не считается вводом
+	h.DefaultHandler(context.Background(), b, textUpdate("груша"))
+	if text := api.LastText(t); !strings.Contains(text, "не понимаю") {
+		t.Errorf("Expected unknown command reply, got %q", text)
+	}
+}
+
+func TestEditHandler_InvalidNumber(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+
+	h.EditHandler(context.Background(), b, textUpdate("/edit 5"))
+
+	if text := api.LastText(t); !strings.Contains(text, "Неверный номер") {
+		t.Errorf("Expected invalid number message, got %q", text)
+	}
+}
+
+func TestEditCallbackHandler_ResetProgress(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+	words, _ := wordService.GetUserWords(testUserID)
+	id := words[0].ID
+
+	h.EditCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("edit_reset_%d", id), "✏️ apple"))
+
+	if text := api.LastText(t); !strings.Contains(text, "Прогресс сброшен") {
+		t.Errorf("Expected reset confirmation, got %q", text)
+	}
+	if word, _ := wordService.GetWord(testUserID, id); !word.IsNew() {
+		t.Errorf("Expected the word to be new again, got %+v", word.ReviewState)
+	}
+}
@@ -0,0 +1,186 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-telegram/bot"
+)
+
+// apiCall описывает один запрос к Bot API
+type apiCall struct {
+	Method string
+	Params map[string]string
+}
+
+// fakeBotAPI — локальный сервер, имитирующий Telegram Bot API.
+// Запоминает все вызовы и отвечает успешными заглушками.
+type fakeBotAPI struct {
+	mu      sync.Mutex
+	calls   []apiCall
+	polls   []string          // ID отправленных опросов
+	files   map[string]string // Содержимое файлов по file_id
+	failing map[string]bool   // Методы, на которые API отвечает ошибкой
+	nextMsg int
+}
+
+// newTestBot создает бота, который ходит в fakeBotAPI вместо api.telegram.org
+func newTestBot(t *testing.T) (*bot.Bot, *fakeBotAPI) {
+	t.Helper()
+
+	api := &fakeBotAPI{nextMsg: 100, files: make(map[string]string), failing: make(map[string]bool)}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	b, err := bot.New("123456:test-token",
+		bot.WithServerURL(server.URL),
+		bot.WithSkipGetMe(),
+		bot.WithNotAsyncHandlers(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create test bot: %v", err)
+	}
+
+	return b, api
+}
+
+func (a *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Файлы скачиваются по ссылке /file/bot<токен>/<file_path>, где file_path — это file_id
+	if strings.HasPrefix(r.URL.Path, "/file/") {
+		a.mu.Lock()
+		content, ok := a.files[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+		a.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+		return
+	}
+
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	params := make(map[string]string)
+	if err := r.ParseMultipartForm(1 << 20); err == nil {
+		for key, values := range r.MultipartForm.Value {
+			params[key] = values[0]
+		}
+	}
+
+	a.mu.Lock()
+	a.calls = append(a.calls, apiCall{Method: method, Params: params})
+	failing := a.failing[method]
+	a.nextMsg++
+	messageID := a.nextMsg
+	if method == "sendPoll" && !failing {
+		a.polls = append(a.polls, fmt.Sprintf("poll-%d", messageID))
+	}
+	a.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if failing {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: test failure"}`)
+		return
+	}
+
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+
+	// Опрос получает ID по номеру сообщения, в котором его отправили
+	poll := map[string]any{
+		"id":       fmt.Sprintf("poll-%d", messageID),
+		"question": params["question"],
+		"type":     "quiz",
+	}
+
+	var result any = true
+	switch {
+	case method == "stopPoll":
+		result = poll
+	case method == "getFile":
+		result = map[string]any{"file_id": params["file_id"], "file_unique_id": params["file_id"],
+			"file_path": params["file_id"]}
+	case method == "getMe":
+		result = map[string]any{"id": 123456, "is_bot": true, "first_name": "Test", "username": "test_bot"}
+	case strings.HasPrefix(method, "send") || strings.HasPrefix(method, "edit"):
+		message := map[string]any{
+			"message_id": messageID,
+			"date":       0,
+			"chat":       map[string]any{"id": chatID, "type": "private"},
+			"text":       params["text"],
+		}
+		if method == "sendPoll" {
+			message["poll"] = poll
+		}
+		result = message
+	}
+
+	if err := json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result}); err != nil {
+		panic(fmt.Sprintf("fake bot api: %v", err))
+	}
+}
+
+// Fail заставляет API отвечать ошибкой на вызовы метода method
+func (a *fakeBotAPI) Fail(method string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.failing[method] = true
+}
+
+// AddFile сохраняет содержимое файла, который бот сможет скачать по fileID
+func (a *fakeBotAPI) AddFile(fileID, content string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.files[fileID] = content
+}
+
+// Calls возвращает вызовы указанного метода
+func (a *fakeBotAPI) Calls(method string) []apiCall {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var calls []apiCall
+	for _, call := range a.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// LastText возвращает текст последнего отправленного или отредактированного сообщения
+func (a *fakeBotAPI) LastText(t *testing.T) string {
+	t.Helper()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i := len(a.calls) - 1; i >= 0; i-- {
+		if text, ok := a.calls[i].Params["text"]; ok {
+			return text
+		}
+	}
+	t.Fatal("No messages were sent")
+	return ""
+}
+
+// LastPollID возвращает ID последнего отправленного опроса
+func (a *fakeBotAPI) LastPollID(t *testing.T) string {
+	t.Helper()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.polls) == 0 {
+		t.Fatal("No polls were sent")
+	}
+	return a.polls[len(a.polls)-1]
+}
@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// FindHandler обрабатывает команду /find <запрос>: ищет слова по слову,
+// переводу и контексту и показывает их с кнопками изменения и удаления
+func (h *BotHandlers) FindHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	query := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/find"))
+
+	if query == "" {
+		sendText(ctx, b, chatID, "Используйте формат: /find запрос\nПример: /find apple\n\n"+
+			"Ищется по слову, переводу и контексту, опечатки допускаются.")
+		return
+	}
+
+	words, err := h.wordService.FindWords(update.Message.From.ID, query)
+	if err != nil {
+		log.Printf("Failed to find words: %v", err)
+		sendText(ctx, b, chatID, "Ошибка при поиске слов.")
+		return
+	}
+	if len(words) == 0 {
+		sendText(ctx, b, chatID, fmt.Sprintf("🔍 По запросу «%s» ничего не найдено.", query))
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        findResultsText(query, words),
+		ReplyMarkup: findKeyboard(words),
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// FindCallbackHandler обрабатывает кнопку удаления в результатах поиска:
+// find_del_<слово> удаляет слово и убирает его кнопки из сообщения.
+// Изменение слова обрабатывает WordsCallbackHandler, как в списке /words.
+func (h *BotHandlers) FindCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+
+	id, ok := strings.CutPrefix(callback.Data, "find_del_")
+	wordID, err := strconv.Atoi(id)
+	if !ok || err != nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	if err := h.wordService.DeleteWord(wordID, callback.From.ID); err != nil {
+		log.Printf("Failed to delete word: %v", err)
+		answerCallback(ctx, b, callback.ID, "Слово уже удалено.")
+		return
+	}
+	answerCallback(ctx, b, callback.ID, "Слово удалено")
+
+	msg := callback.Message.Message
+	if msg == nil || msg.ReplyMarkup == nil {
+		return
+	}
+
+	// Оставляем кнопки остальных найденных слов
+	var keyboard [][]models.InlineKeyboardButton
+	for _, row := range msg.ReplyMarkup.InlineKeyboard {
+		if len(row) > 1 && row[1].CallbackData == callback.Data {
+			continue
+		}
+		keyboard = append(keyboard, row)
+	}
+	_, err = b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+	})
+	if err != nil {
+		log.Printf("Failed to edit message: %v", err)
+	}
+}
+
+// findResultsText перечисляет найденные слова, начиная с лучших совпадений
+func findResultsText(query string, words []*repository.Word) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔍 По запросу «%s» найдено: %d\n\n", query, len(words)))
+	for _, word := range words {
+		sb.WriteString(fmt.Sprintf("• %s - %s", word.Word, truncateRunes(word.Translation, wordsItemLimit)))
+		if word.Context != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", truncateRunes(word.Context, wordsItemLimit)))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// findKeyboard возвращает для каждого найденного слова кнопки изменения и удаления
+func findKeyboard(words []*repository.Word) *models.InlineKeyboardMarkup {
+	keyboard := make([][]models.InlineKeyboardButton, 0, len(words))
+	for _, word := range words {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
+			{Text: "✏️ " + truncateRunes(word.Word, 20), CallbackData: fmt.Sprintf("words_edit_%d", word.ID)},
+			{Text: "🗑", CallbackData: fmt.Sprintf("find_del_%d", word.ID)},
+		})
+	}
+	return &models.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFindHandler_ShowsMatches(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "pineapple")
+
+	h.FindHandler(context.Background(), b, textUpdate("/find aple"))
+
+	sends := api.Calls("sendMessage")
+	result := sends[len(sends)-1]
+	if !strings.Contains(result.Params["text"], "apple - apple-ru") || strings.Contains(result.Params["text"], "pear") {
+		t.Fatalf("Expected apple despite the typo, got %q", result.Params["text"])
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	for _, word := range words {
+		if word.Word == "apple" && !strings.Contains(result.Params["reply_markup"], fmt.Sprintf("find_del_%d", word.ID)) {
+			t.Errorf("Expected delete button for apple, got %s", result.Params["reply_markup"])
+		}
+	}
+}
+
+func TestFindHandler_EmptyQueryAndNoMatches(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+
+	h.FindHandler(context.Background(), b, textUpdate("/find"))
+	if text := api.LastText(t); !strings.Contains(text, "/find запрос") {
+		t.Errorf("Expected usage hint, got %q", text)
+	}
+
+	h.FindHandler(context.Background(), b, textUpdate("/find zebra"))
+	if text := api.LastText(t); !strings.Contains(text, "ничего не найдено") {
+		t.Errorf("Expected no matches message, got %q", text)
+	}
+}
+
+func TestFindCallbackHandler_DeletesWord(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+	words, _ := wordService.GetUserWords(testUserID)
+	id := words[0].ID
+
+	h.FindCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("find_del_%d", id), "🔍"))
+
+	if word, _ := wordService.GetWord(testUserID, id); word != nil {
+		t.Errorf("Expected the word to be deleted, got %+v", word)
+	}
+	if len(api.Calls("answerCallbackQuery")) != 1 {
+		t.Errorf("Expected the callback to be answered")
+	}
+}
@@ -17,22 +17,64 @@ import (
 type BotHandlers struct {
 	userService *service.UserService
 	wordService *service.WordService
+	quizService *service.QuizService
+
+	typedQuizService *service.TypedQuizService
+	addWizardService *service.AddWizardService
+	wordEditService  *service.WordEditService
+	deckEditService  *service.DeckEditService
 }
 
 // NewBotHandlers создает новый экземпляр BotHandlers с необходимыми сервисами
-func NewBotHandlers(userService *service.UserService, wordService *service.WordService) *BotHandlers {
+func NewBotHandlers(userService *service.UserService, wordService *service.WordService,
+	quizService *service.QuizService, typedQuizService *service.TypedQuizService,
+	addWizardService *service.AddWizardService, wordEditService *service.WordEditService,
+	deckEditService *service.DeckEditService) *BotHandlers {
 	return &BotHandlers{
-		userService: userService,
-		wordService: wordService,
+		userService:      userService,
+		wordService:      wordService,
+		quizService:      quizService,
+		typedQuizService: typedQuizService,
+		addWizardService: addWizardService,
+		wordEditService:  wordEditService,
+		deckEditService:  deckEditService,
+	}
+}
+
+// sendText отправляет простое текстовое сообщение и логирует ошибку отправки
+func sendText(ctx context.Context, b *bot.Bot, chatID int64, text string) {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   text,
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// editText заменяет текст сообщения и убирает его кнопки, логируя ошибку
+func editText(ctx context.Context, b *bot.Bot, chatID int64, messageID int, text string) {
+	_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+	})
+	if err != nil {
+		log.Printf("Failed to edit message: %v", err)
 	}
 }
 
-// DefaultHandler обрабатывает неизвестные команды
+// DefaultHandler обрабатывает неизвестные команды и текст без команды:
+// текст передается обработчику текущего состояния пользователя
 func (h *BotHandlers) DefaultHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
 	if update.Message == nil {
 		return
 	}
-	// Игнорируем сообщения, которые не являются командами
+	if update.Message.From != nil && !strings.HasPrefix(update.Message.Text, "/") &&
+		h.handleState(ctx, b, update) {
+		return
+	}
+
 	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: update.Message.Chat.ID,
 		Text:   "Извините, я не понимаю эту команду. Используйте /help для получения справки.",
@@ -42,7 +84,34 @@ func (h *BotHandlers) DefaultHandler(ctx context.Context, b *bot.Bot, update *mo
 	}
 }
 
-// StartHandler обрабатывает команду /start
+// handleState передает текст без команды обработчику состояния пользователя.
+// Возвращает false, если бот не ждал от пользователя ввода.
+func (h *BotHandlers) handleState(ctx context.Context, b *bot.Bot, update *models.Update) bool {
+	user, err := h.userService.GetUser(update.Message.From.ID)
+	if err != nil {
+		log.Printf("Failed to get user state: %v", err)
+		return false
+	}
+	if user == nil {
+		return false
+	}
+
+	switch {
+	case service.IsTypedState(user.State):
+		h.handleTypedAnswer(ctx, b, update)
+	case service.IsAddWizardState(user.State):
+		h.handleAddInput(ctx, b, update)
+	case service.IsEditState(user.State):
+		h.handleEditInput(ctx, b, update)
+	case service.IsDeckEditState(user.State):
+		h.handleDeckEditInput(ctx, b, update)
+	default:
+		return false
+	}
+	return true
+}
+
+// StartHandler обрабатывает команду /start; /start deck_<код> предлагает импортировать колоду по ссылке
 func (h *BotHandlers) StartHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
 	user := update.Message.From
 
@@ -60,6 +129,12 @@ func (h *BotHandlers) StartHandler(ctx context.Context, b *bot.Bot, update *mode
 		return
 	}
 
+	// Ссылка t.me/<бот>?start=deck_<код> приходит как /start deck_<код>
+	if code, ok := service.ParseDeckSharePayload(strings.TrimPrefix(update.Message.Text, "/start")); ok {
+		h.offerSharedDeck(ctx, b, update.Message.Chat.ID, user.ID, code)
+		return
+	}
+
 	welcomeText := fmt.Sprintf(`Привет, %s! 👋
 
 Я бот для изучения английского языка. Вот что я умею:
@@ -88,17 +163,54 @@ func (h *BotHandlers) HelpHandler(ctx context.Context, b *bot.Bot, update *model
 📝 /add - Добавить новое слово
    Формат: /add слово - перевод
    Пример: /add apple - яблоко
+   Без аргументов бот спросит слово, перевод и контекст по очереди
+   Несколько слов — по одному на строку после /add
+   Теги пишутся через #: /add train - поезд #travel
 
-📚 /words - Показать все ваши слова
+📚 /words [#тег] - Показать ваши слова по страницам
+   Кнопки под списком: изменить, удалить или сбросить прогресс слова,
+   листать страницы и сортировать: новые, А–Я, трудные, к повторению
 
-🧠 /quiz - Пройти тест на знание слов
+🧠 /quiz [n] [#тег] - Пройти тест из n вопросов (по умолчанию 5)
+   Вопросы приходят в одном сообщении, в конце — итог
 
-🔄 /review - Повторить слова, которые пора повторить
+✍️ /type - Написать перевод слова самому
+   Регистр, ё/е, артикли и знаки препинания не важны,
+   за небольшую опечатку ответ засчитывается как «почти»
+
+🔄 /review [#тег] - Повторить слова карточками
+   Вспомните перевод, откройте ответ и оцените себя:
+   чем легче вспомнилось, тем позже слово вернется
+
+🔍 /find [запрос] - Найти слова по слову, переводу или контексту
+   Опечатки в запросе допускаются
+
+✏️ /edit [номер] - Исправить слово, перевод или контекст
+   Расписание повторений сохраняется; можно и сбросить прогресс
 
 🗑️ /delete [номер] - Удалить слово по номеру из списка
 
+🏷 /tags - Показать ваши теги
+   /tag [номер] #тег - добавить теги слову, /untag [номер] #тег - убрать
+
+🗂 /decks - Показать колоды и выбрать активную
+   /newdeck Название - создать колоду, /deck - настройки активной колоды,
+   /move [номера] Колода - перенести слова в другую колоду
+   Кнопка «Поделиться» в /deck дает ссылку, по которой другой
+   пользователь импортирует копию колоды
+
+📄 Импорт из файла - Отправьте .csv или .tsv файл со столбцами
+   слово, перевод, контекст, теги: бот покажет первые строки
+   и по кнопке добавит слова в активную колоду
+
+♻️ /duplicates - Найти и объединить повторяющиеся слова
+   Слова, которые отличаются только регистром или пробелами,
+   считаются одним словом
+
 📊 /stats - Показать статистику изучения
 
+⚙️ /settings - Выбрать алгоритм повторения (SM-2 или FSRS), направление и вид теста
+
 🎨 /image [слово] - Сгенерировать изображение для слова
 
 ❓ /help - Показать эту справку
@@ -120,17 +232,21 @@ func (h *BotHandlers) AddHandler(ctx context.Context, b *bot.Bot, update *models
 	userID := update.Message.From.ID
 	text := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/add"))
 
+	// Без аргументов слово добавляется по шагам
 	if text == "" {
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "Используйте формат: /add слово - перевод\nПример: /add apple - яблоко",
-		})
+		h.startAddWizard(ctx, b, update)
 		return
 	}
 
-	// Парсим слово и перевод
-	parts := strings.Split(text, " - ")
-	if len(parts) < 2 {
+	// Каждая строка — отдельное слово
+	if strings.Contains(text, "\n") {
+		h.addWords(ctx, b, update, text)
+		return
+	}
+
+	text, tags := service.ExtractTags(text)
+	word, translation, context, err := service.ParseWordLine(text)
+	if err != nil {
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: update.Message.Chat.ID,
 			Text:   "Используйте формат: /add слово - перевод\nПример: /add apple - яблоко",
@@ -138,16 +254,8 @@ func (h *BotHandlers) AddHandler(ctx context.Context, b *bot.Bot, update *models
 		return
 	}
 
-	word := strings.TrimSpace(parts[0])
-	translation := strings.TrimSpace(parts[1])
-	context := ""
-
-	// Если есть контекст в скобках
-	if len(parts) > 2 {
-		context = strings.TrimSpace(strings.Join(parts[2:], " - "))
-	}
-
-	err := h.wordService.AddWord(userID, word, translation, context)
+	draft := service.AddDraft{Word: word, Translation: translation, Context: context, Tags: tags}
+	step, err := h.addWizardService.Add(userID, draft)
 	if err != nil {
 		log.Printf("Failed to add word: %v", err)
 		b.SendMessage(ctx, &bot.SendMessageParams{
@@ -157,253 +265,63 @@ func (h *BotHandlers) AddHandler(ctx context.Context, b *bot.Bot, update *models
 		return
 	}
 
-	b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: update.Message.Chat.ID,
-		Text:   fmt.Sprintf("✅ Слово '%s' добавлено!", word),
-	})
+	sendAddPrompt(ctx, b, update.Message.Chat.ID, step)
 }
 
-// WordsHandler обрабатывает команду /words
-func (h *BotHandlers) WordsHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+// DeleteHandler обрабатывает команду /delete
+func (h *BotHandlers) DeleteHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
 	userID := update.Message.From.ID
-	log.Printf("Received /words command from user %d", userID)
+	text := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/delete"))
 
-	words, err := h.wordService.GetUserWords(userID)
-	if err != nil {
-		log.Printf("Failed to get user words: %v", err)
+	if text == "" {
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: update.Message.Chat.ID,
-			Text:   "Ошибка при получении слов.",
+			Text:   "Используйте формат: /delete [номер]\nПример: /delete 1\n\nДля просмотра номеров слов используйте /words",
 		})
 		return
 	}
 
-	if len(words) == 0 {
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "У вас пока нет сохраненных слов. Добавьте их командой /add!",
-		})
+	wordToDelete, errText := h.wordByNumber(userID, text)
+	if wordToDelete == nil {
+		sendText(ctx, b, update.Message.Chat.ID, errText)
 		return
 	}
 
-	var response strings.Builder
-	response.WriteString("📚 Ваши слова:\n\n")
-	for i, word := range words {
-		response.WriteString(fmt.Sprintf("%d. %s - %s", i+1, word.Word, word.Translation))
-		if word.Context != "" {
-			response.WriteString(fmt.Sprintf(" (%s)", word.Context))
-		}
-		response.WriteString("\n")
-	}
-
-	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: update.Message.Chat.ID,
-		Text:   response.String(),
-		// ParseMode убран
-	})
-	if err != nil {
-		log.Printf("Failed to send message: %v", err)
-	}
-}
-
-// QuizHandler обрабатывает команду /quiz
-func (h *BotHandlers) QuizHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
-	userID := update.Message.From.ID
-	log.Printf("Received /quiz command from user %d", userID)
-
-	quiz, err := h.wordService.GenerateQuiz(userID)
+	err := h.wordService.DeleteWord(wordToDelete.ID, userID)
 	if err != nil {
-		log.Printf("Failed to generate quiz: %v", err)
-		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "Не удалось создать тест. Убедитесь, что у вас есть минимум 4 слова.",
-		})
-		if err != nil {
-			log.Printf("Failed to send error message: %v", err)
-		}
-		return
-	}
-
-	keyboard := &models.InlineKeyboardMarkup{
-		InlineKeyboard: make([][]models.InlineKeyboardButton, len(quiz.Options)),
-	}
-	for i, option := range quiz.Options {
-		if option == "" {
-			log.Printf("Empty option detected at index %d", i) // Добавлено для отладки
-		}
-		safeOption := strings.ReplaceAll(option, "_", "\\_")
-		keyboard.InlineKeyboard[i] = []models.InlineKeyboardButton{
-			{
-				Text:         fmt.Sprintf("%d. %s", i+1, safeOption),
-				CallbackData: fmt.Sprintf("quiz_%d_%d_%d", quiz.WordID, i, quiz.CorrectIdx),
-			},
-		}
-	}
-
-	safeQuestion := strings.ReplaceAll(quiz.Question, "_", "\\_")
-	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID:      update.Message.Chat.ID,
-		Text:        safeQuestion,
-		ReplyMarkup: keyboard,
-		// ParseMode: models.ParseModeMarkdown, // Убран
-	})
-	if err != nil {
-		log.Printf("Failed to send quiz message: %v", err)
-	}
-}
-
-// ReviewHandler обрабатывает команду /review
-func (h *BotHandlers) ReviewHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
-	userID := update.Message.From.ID
-
-	words, err := h.wordService.GetWordsForReview(userID)
-	if err != nil {
-		log.Printf("Failed to get words for review: %v", err)
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "Ошибка при получении слов для повторения.",
-		})
-		return
-	}
-
-	if len(words) == 0 {
+		log.Printf("Failed to delete word: %v", err)
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: update.Message.Chat.ID,
-			Text:   "🎉 Отлично! Сейчас нет слов для повторения. Проверьте позже или добавьте новые слова!",
+			Text:   "Ошибка при удалении слова.",
 		})
 		return
 	}
 
-	var response strings.Builder
-	response.WriteString("🔄 Слова для повторения:\n\n")
-
-	for i, word := range words {
-		response.WriteString(fmt.Sprintf("%d. *%s* - %s", i+1, word.Word, word.Translation))
-		if word.Context != "" {
-			response.WriteString(fmt.Sprintf(" (%s)", word.Context))
-		}
-		response.WriteString("\n")
-	}
-
-	response.WriteString("\n💡 Пройдите тест командой /quiz для закрепления!")
-
 	b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID:    update.Message.Chat.ID,
-		Text:      response.String(),
-		ParseMode: models.ParseModeMarkdown,
-	})
-}
-
-// CallbackHandler обрабатывает callback запросы (ответы на тесты)
-func (h *BotHandlers) CallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
-	callback := update.CallbackQuery
-	data := callback.Data
-
-	// Парсим данные callback'а
-	parts := strings.Split(data, "_")
-	if len(parts) != 4 || parts[0] != "quiz" {
-		return
-	}
-
-	wordID, _ := strconv.Atoi(parts[1])
-	selectedIdx, _ := strconv.Atoi(parts[2])
-	correctIdx, _ := strconv.Atoi(parts[3])
-
-	correct := selectedIdx == correctIdx
-
-	// Обновляем статистику слова
-	err := h.wordService.UpdateWordReview(wordID, correct)
-	if err != nil {
-		log.Printf("Failed to update word review: %v", err)
-	}
-
-	var responseText string
-	if correct {
-		responseText = "✅ Правильно! Отличная работа!"
-	} else {
-		responseText = "❌ Неправильно. Не расстраивайтесь, продолжайте изучать!"
-	}
-
-	// Отвечаем на callback
-	_, err = b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
-		CallbackQueryID: callback.ID,
-		Text:            responseText,
-		ShowAlert:       true,
+		ChatID: update.Message.Chat.ID,
+		Text:   fmt.Sprintf("✅ Слово '%s' удалено!", wordToDelete.Word),
 	})
-	if err != nil {
-		log.Printf("Failed to answer callback query: %v", err)
-	}
-
-	// Обновляем сообщение
-	if msg := callback.Message.Message; msg != nil {
-		_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
-			ChatID:    msg.Chat.ID,
-			MessageID: msg.ID,
-			Text:      fmt.Sprintf("%s\n\n%s", msg.Text, responseText),
-			ParseMode: models.ParseModeMarkdown,
-		})
-		if err != nil {
-			log.Printf("Failed to edit message: %v", err)
-		}
-	}
 }
 
-// DeleteHandler обрабатывает команду /delete
-func (h *BotHandlers) DeleteHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
-	userID := update.Message.From.ID
-	text := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/delete"))
-
-	if text == "" {
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "Используйте формат: /delete [номер]\nПример: /delete 1\n\nДля просмотра номеров слов используйте /words",
-		})
-		return
-	}
-
+// wordByNumber возвращает слово активной колоды по номеру из списка /words.
+// Если номер неверный, вместо слова возвращается текст для пользователя.
+func (h *BotHandlers) wordByNumber(userID int64, text string) (*repository.Word, string) {
 	wordNum, err := strconv.Atoi(text)
 	if err != nil {
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "Неверный номер слова. Используйте /words для просмотра списка.",
-		})
-		return
+		return nil, "Неверный номер слова. Используйте /words для просмотра списка."
 	}
 
-	// Получаем слова пользователя
-	words, err := h.wordService.GetUserWords(userID)
+	words, err := h.wordService.GetDeckWords(userID)
 	if err != nil {
 		log.Printf("Failed to get user words: %v", err)
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "Ошибка при получении слов.",
-		})
-		return
+		return nil, "Ошибка при получении слов."
 	}
 
 	if wordNum < 1 || wordNum > len(words) {
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   fmt.Sprintf("Неверный номер. У вас %d слов. Используйте /words для просмотра.", len(words)),
-		})
-		return
+		return nil, fmt.Sprintf("Неверный номер. У вас %d слов. Используйте /words для просмотра.", len(words))
 	}
 
-	wordToDelete := words[wordNum-1]
-	err = h.wordService.DeleteWord(wordToDelete.ID, userID)
-	if err != nil {
-		log.Printf("Failed to delete word: %v", err)
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "Ошибка при удалении слова.",
-		})
-		return
-	}
-
-	b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: update.Message.Chat.ID,
-		Text:   fmt.Sprintf("✅ Слово '%s' удалено!", wordToDelete.Word),
-	})
+	return words[wordNum-1], ""
 }
 
 // StatsHandler обрабатывает команду /stats
@@ -0,0 +1,321 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot/models"
+)
+
+const testUserID int64 = 42
+
+// newTestHandlers создает обработчики поверх хранилища в памяти
+func newTestHandlers(t *testing.T) (*BotHandlers, *service.WordService) {
+	t.Helper()
+
+	h, wordService, _ := newTestHandlersWithDB(t)
+	return h, wordService
+}
+
+// newTestHandlersWithDB создает обработчики и возвращает хранилище, чтобы тест мог
+// подготовить данные, недоступные через сервисы
+func newTestHandlersWithDB(t *testing.T) (*BotHandlers, *service.WordService, *repository.MemoryDatabase) {
+	t.Helper()
+
+	db := repository.NewMemoryDatabase()
+	userService := service.NewUserService(repository.NewMemoryUserRepository(db))
+	wordService := service.NewWordService(repository.NewMemoryWordRepository(db),
+		repository.NewMemoryReviewLogRepository(db))
+	quizService := service.NewQuizService(wordService, repository.NewMemoryQuizSessionRepository(db))
+
+	if err := userService.RegisterUser(testUserID, "tester", "Test", ""); err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	typedQuizService := service.NewTypedQuizService(wordService, userService)
+	addWizardService := service.NewAddWizardService(wordService, userService)
+	wordEditService := service.NewWordEditService(wordService, userService)
+	deckEditService := service.NewDeckEditService(wordService, userService)
+
+	return NewBotHandlers(userService, wordService, quizService, typedQuizService, addWizardService, wordEditService,
+		deckEditService), wordService, db
+}
+
+func textUpdate(text string) *models.Update {
+	return &models.Update{
+		Message: &models.Message{
+			ID:   1,
+			Text: text,
+			From: &models.User{ID: testUserID, FirstName: "Test"},
+			Chat: models.Chat{ID: testUserID},
+		},
+	}
+}
+
+func callbackUpdate(data, messageText string) *models.Update {
+	return &models.Update{
+		CallbackQuery: &models.CallbackQuery{
+			ID:   "cb1",
+			From: models.User{ID: testUserID},
+			Data: data,
+			Message: models.MaybeInaccessibleMessage{
+				Type: models.MaybeInaccessibleMessageTypeMessage,
+				Message: &models.Message{
+					ID:   7,
+					Text: messageText,
+					Chat: models.Chat{ID: testUserID},
+				},
+			},
+		},
+	}
+}
+
+func TestAddHandler_SavesWord(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.AddHandler(context.Background(), b, textUpdate("/add apple - яблоко - red apple"))
+
+	words, err := wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	if len(words) != 1 || words[0].Word != "apple" || words[0].Translation != "яблоко" || words[0].Context != "red apple" {
+		t.Fatalf("Unexpected words: %+v", words)
+	}
+	if text := api.LastText(t); !strings.Contains(text, "apple") {
+		t.Errorf("Expected confirmation mentioning the word, got %q", text)
+	}
+}
+
+func TestAddHandler_InvalidFormat(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.AddHandler(context.Background(), b, textUpdate("/add apple"))
+
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 0 {
+		t.Errorf("Expected no words to be saved, got %d", len(words))
+	}
+	if text := api.LastText(t); !strings.Contains(text, "Используйте формат") {
+		t.Errorf("Expected usage hint, got %q", text)
+	}
+}
+
+func TestWordsHandler_ListsWords(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear")
+
+	h.WordsHandler(context.Background(), b, textUpdate("/words"))
+
+	text := api.LastText(t)
+	if !strings.Contains(text, "1. pear - pear-ru") || !strings.Contains(text, "2. apple - apple-ru") {
+		t.Errorf("Expected numbered list, got %q", text)
+	}
+}
+
+func TestQuizHandler_NoWords(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz"))
+
+	if text := api.LastText(t); !strings.Contains(text, "/add") {
+		t.Errorf("Expected not enough words message, got %q", text)
+	}
+}
+
+func TestQuizHandler_SendsOptions(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz"))
+
+	calls := api.Calls("sendMessage")
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(calls))
+	}
+
+	var markup models.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(calls[0].Params["reply_markup"]), &markup); err != nil {
+		t.Fatalf("Failed to decode keyboard: %v", err)
+	}
+	// Четыре варианта ответа и кнопка остановки раунда
+	if len(markup.InlineKeyboard) != 5 {
+		t.Errorf("Expected 4 answer buttons and an abort button, got %d rows", len(markup.InlineKeyboard))
+	}
+	if text := calls[0].Params["text"]; !strings.HasPrefix(text, "Вопрос 1 из 4") {
+		t.Errorf("Expected round of 4 questions limited by the vocabulary, got %q", text)
+	}
+}
+
+func TestCallbackHandler_UpdatesReview(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz"))
+	question, data, _ := quizAnswers(t, api)
+
+	h.CallbackHandler(context.Background(), b, callbackUpdate(data, question))
+
+	history, err := wordService.GetAnswerHistory(testUserID, 10)
+	if err != nil {
+		t.Fatalf("Failed to get answer history: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 logged answer, got %d", len(history))
+	}
+	logged := history[0]
+	if !logged.Correct || logged.ChosenOption == "" || logged.IntervalBefore != 1 || logged.IntervalAfter != 1 {
+		t.Errorf("Unexpected logged answer: %+v", logged)
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	for _, w := range words {
+		if w.ID == logged.WordID && w.Repetitions != 1 {
+			t.Errorf("Expected 1 repetition after correct answer, got %d", w.Repetitions)
+		}
+	}
+	if len(api.Calls("answerCallbackQuery")) != 1 {
+		t.Error("Expected callback query to be answered")
+	}
+	if text := api.LastText(t); !strings.Contains(text, "Правильно") {
+		t.Errorf("Expected message to be edited with the result, got %q", text)
+	}
+}
+
+func TestCallbackHandler_DoubleTapIsIdempotent(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz"))
+	question, _, wrong := quizAnswers(t, api)
+
+	h.CallbackHandler(context.Background(), b, callbackUpdate(wrong, question))
+	h.CallbackHandler(context.Background(), b, callbackUpdate(wrong, question))
+
+	history, _ := wordService.GetAnswerHistory(testUserID, 10)
+	if len(history) != 1 {
+		t.Errorf("Expected the answer to be logged once, got %d", len(history))
+	}
+	if calls := api.Calls("answerCallbackQuery"); len(calls) != 2 || calls[0].Params["text"] != calls[1].Params["text"] {
+		t.Errorf("Expected both taps to get the same answer, got %+v", calls)
+	}
+	if len(api.Calls("editMessageText")) != 1 {
+		t.Error("Expected the message to be edited only once")
+	}
+}
+
+func TestCallbackHandler_RejectsForeignAndForgedAnswers(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz"))
+	question, data, _ := quizAnswers(t, api)
+
+	foreign := callbackUpdate(data, question)
+	foreign.CallbackQuery.From.ID = testUserID + 1
+	h.CallbackHandler(context.Background(), b, foreign)
+
+	// Старый формат с правильным ответом в данных больше не принимается
+	words, _ := wordService.GetUserWords(testUserID)
+	h.CallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("quiz_%d_2_2", words[0].ID), question))
+	h.CallbackHandler(context.Background(), b, callbackUpdate("quiz_0123456789abcdef_1", question))
+
+	history, _ := wordService.GetAnswerHistory(testUserID, 10)
+	if len(history) != 0 {
+		t.Errorf("Expected no answers to be logged, got %d", len(history))
+	}
+	if len(api.Calls("editMessageText")) != 0 {
+		t.Error("Expected the quiz message to stay unchanged")
+	}
+	calls := api.Calls("answerCallbackQuery")
+	// Данные старого формата не разбираются, но на callback все равно отвечаем
+	if len(calls) != 3 || !strings.Contains(calls[0].Params["text"], "другому пользователю") ||
+		calls[1].Params["text"] != "" || !strings.Contains(calls[2].Params["text"], "не найден") {
+		t.Errorf("Expected foreign and unknown sessions to be reported, got %+v", calls)
+	}
+}
+
+// quizAnswers возвращает текст последнего вопроса теста и данные кнопок правильного
+// и одного неправильного ответа. Вопрос ищется среди отправленных и отредактированных сообщений.
+func quizAnswers(t *testing.T, api *fakeBotAPI) (question, correct, wrong string) {
+	t.Helper()
+
+	var last *apiCall
+	api.mu.Lock()
+	for i := len(api.calls) - 1; i >= 0 && last == nil; i-- {
+		if api.calls[i].Params["reply_markup"] != "" {
+			last = &api.calls[i]
+		}
+	}
+	api.mu.Unlock()
+	if last == nil {
+		t.Fatal("No quiz was sent")
+	}
+
+	var markup models.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(last.Params["reply_markup"]), &markup); err != nil {
+		t.Fatalf("Failed to decode keyboard: %v", err)
+	}
+
+	question = last.Params["text"]
+	lines := strings.Split(question, "\n")
+	word := strings.TrimSuffix(strings.TrimPrefix(lines[len(lines)-1], "Как переводится слово: "), "?")
+	for _, row := range markup.InlineKeyboard {
+		data := row[0].CallbackData
+		switch {
+		case strings.HasPrefix(data, "quiz_abort_"):
+		case strings.HasSuffix(row[0].Text, " "+word+"-ru"):
+			correct = data
+		default:
+			wrong = data
+		}
+	}
+	if correct == "" || wrong == "" {
+		t.Fatalf("Failed to find answers for %q in %+v", word, markup)
+	}
+	return question, correct, wrong
+}
+
+// abortData возвращает данные кнопки остановки раунда из последнего вопроса
+func abortData(t *testing.T, api *fakeBotAPI) string {
+	t.Helper()
+
+	calls := append(api.Calls("sendMessage"), api.Calls("editMessageText")...)
+	for i := len(calls) - 1; i >= 0; i-- {
+		if !strings.Contains(calls[i].Params["reply_markup"], "quiz_abort_") {
+			continue
+		}
+		var markup models.InlineKeyboardMarkup
+		if err := json.Unmarshal([]byte(calls[i].Params["reply_markup"]), &markup); err != nil {
+			t.Fatalf("Failed to decode keyboard: %v", err)
+		}
+		rows := markup.InlineKeyboard
+		return rows[len(rows)-1][0].CallbackData
+	}
+	t.Fatal("No abort button was sent")
+	return ""
+}
+
+// addWords добавляет слова с переводами вида "<слово>-ru"
+func addWords(t *testing.T, wordService *service.WordService, words ...string) {
+	t.Helper()
+
+	for _, word := range words {
+		if err := wordService.AddWord(testUserID, word, word+"-ru", ""); err != nil {
+			t.Fatalf("Failed to add word %q: %v", word, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Ограничения Telegram на длину вопроса и вариантов опроса
+const (
+	maxPollQuestionLength = 300
+	maxPollOptionLength   = 100
+)
+
+// sendQuizPoll задает вопрос раунда опросом Telegram в режиме викторины.
+// Опрос не анонимный, иначе Telegram не пришлет боту обновление poll_answer.
+// Ошибка означает, что ответить на вопрос нельзя и раунд не продолжится.
+func (h *BotHandlers) sendQuizPoll(ctx context.Context, b *bot.Bot, chatID int64, round *repository.QuizRound,
+	session *repository.QuizSession) error {
+	options := make([]models.InputPollOption, 0, len(session.Options))
+	for _, option := range session.Options {
+		options = append(options, models.InputPollOption{Text: truncateRunes(option, maxPollOptionLength)})
+	}
+
+	params := &bot.SendPollParams{
+		ChatID:          chatID,
+		Question:        truncateRunes(quizQuestionText(round, session), maxPollQuestionLength),
+		Options:         options,
+		IsAnonymous:     bot.False(),
+		Type:            "quiz",
+		CorrectOptionID: session.CorrectIdx,
+	}
+	if abort := abortButton(round); abort != nil {
+		params.ReplyMarkup = &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{*abort}}}
+	}
+
+	msg, err := b.SendPoll(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to send quiz poll: %w", err)
+	}
+	if msg.Poll == nil {
+		return errors.New("failed to send quiz poll: no poll in response")
+	}
+
+	if err := h.quizService.AttachPoll(session.ID, msg.Poll.ID); err != nil {
+		return fmt.Errorf("failed to attach quiz poll: %w", err)
+	}
+	return nil
+}
+
+// abortUnsentRound прерывает раунд, очередной вопрос которого не удалось
+// отправить, и присылает итог по уже отвеченным вопросам
+func (h *BotHandlers) abortUnsentRound(ctx context.Context, b *bot.Bot, chatID int64, round *repository.QuizRound) {
+	text := "Не удалось отправить вопрос теста, тест остановлен. Начните новый: /quiz"
+	summary, err := h.quizService.AbortRound(round.UserID, round.ID)
+	if err != nil {
+		log.Printf("Failed to abort quiz round: %v", err)
+	} else if len(summary.Items) > 0 {
+		text += "\n\n" + roundSummaryText(summary)
+	}
+	sendText(ctx, b, chatID, text)
+}
+
+// PollAnswerHandler обрабатывает ответы на опросы теста (обновления poll_answer).
+// Правильный вариант Telegram показывает сам, поэтому бот присылает только
+// следующий вопрос раунда или его итог — в чат, где раунд начат.
+func (h *BotHandlers) PollAnswerHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	answer := update.PollAnswer
+	if answer == nil || answer.User == nil || len(answer.OptionIDs) == 0 {
+		return // Отозванный голос: в викторине его быть не может
+	}
+	userID := answer.User.ID
+
+	// В poll_answer нет чата, поэтому он берется из раунда
+	round, err := h.quizService.PollRound(answer.PollID)
+	if err != nil {
+		log.Printf("Failed to get quiz round: %v", err)
+		return
+	}
+	if round == nil || round.UserID != userID {
+		return // Опрос не из теста бота или чужой тест в групповом чате
+	}
+	chatID := round.ChatID
+	if chatID == 0 {
+		chatID = userID // Раунд начат до миграции 0015, а тогда тест шел только в личном чате
+	}
+
+	scheduler, err := h.userService.GetScheduler(userID)
+	if err != nil {
+		log.Printf("Failed to get user scheduler: %v", err)
+	}
+
+	result, err := h.quizService.AnswerPoll(scheduler, userID, answer.PollID, answer.OptionIDs[0])
+	if err != nil {
+		if errors.Is(err, service.ErrQuizSessionNotFound) {
+			return // Опрос не из теста бота
+		}
+		log.Printf("Failed to answer quiz poll: %v", err)
+		sendText(ctx, b, chatID, quizErrorText(err))
+		return
+	}
+	if result.AlreadyAnswered {
+		return
+	}
+
+	switch {
+	case result.Next != nil:
+		if err := h.sendQuizPoll(ctx, b, chatID, result.Round, result.Next); err != nil {
+			log.Printf("Failed to continue quiz round: %v", err)
+			h.abortUnsentRound(ctx, b, chatID, result.Round)
+		}
+	case result.Summary != nil:
+		sendText(ctx, b, chatID, roundSummaryText(result.Summary))
+	}
+}
+
+// stopQuizPoll закрывает опрос, чтобы на него больше нельзя было ответить
+func stopQuizPoll(ctx context.Context, b *bot.Bot, chatID int64, messageID int) {
+	_, err := b.StopPoll(ctx, &bot.StopPollParams{ChatID: chatID, MessageID: messageID})
+	if err != nil {
+		log.Printf("Failed to stop quiz poll: %v", err)
+	}
+}
+
+// truncateRunes обрезает строку до limit символов
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
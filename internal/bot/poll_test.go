@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/go-telegram/bot/models"
+)
+
+// pollAnswerUpdate имитирует обновление poll_answer от тестового пользователя
+func pollAnswerUpdate(pollID string, option int) *models.Update {
+	return &models.Update{
+		PollAnswer: &models.PollAnswer{
+			PollID:    pollID,
+			User:      &models.User{ID: testUserID},
+			OptionIDs: []int{option},
+		},
+	}
+}
+
+// lastPoll возвращает параметры последнего отправленного опроса
+func lastPoll(t *testing.T, api *fakeBotAPI) (options []models.InputPollOption, correct int, params map[string]string) {
+	t.Helper()
+
+	calls := api.Calls("sendPoll")
+	if len(calls) == 0 {
+		t.Fatal("No polls were sent")
+	}
+	params = calls[len(calls)-1].Params
+	if err := json.Unmarshal([]byte(params["options"]), &options); err != nil {
+		t.Fatalf("Failed to parse poll options: %v", err)
+	}
+	correct, err := strconv.Atoi(params["correct_option_id"])
+	if err != nil {
+		t.Fatalf("Failed to parse correct option: %v", err)
+	}
+	return options, correct, params
+}
+
+func TestQuizPoll_AnswersUpdateScheduleAndSummarize(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+	if err := h.userService.SetQuizPresentation(testUserID, repository.PresentationPoll); err != nil {
+		t.Fatalf("Failed to set presentation: %v", err)
+	}
+
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz 2"))
+
+	options, correct, params := lastPoll(t, api)
+	if params["type"] != "quiz" || params["is_anonymous"] != "false" || len(options) != 4 {
+		t.Fatalf("Expected a non-anonymous quiz poll with 4 options, got %+v", params)
+	}
+	if !strings.Contains(params["question"], "Вопрос 1 из 2") || !strings.Contains(params["reply_markup"], "quiz_abort_") {
+		t.Errorf("Expected numbered question with an abort button, got %+v", params)
+	}
+	if len(api.Calls("sendMessage")) != 0 {
+		t.Error("Expected no keyboard message in poll mode")
+	}
+
+	first := api.LastPollID(t)
+	h.PollAnswerHandler(context.Background(), b, pollAnswerUpdate(first, correct))
+	// Повторное обновление по тому же опросу ничего не меняет
+	h.PollAnswerHandler(context.Background(), b, pollAnswerUpdate(first, correct))
+
+	if polls := api.Calls("sendPoll"); len(polls) != 2 || !strings.Contains(polls[1].Params["question"], "Вопрос 2 из 2") {
+		t.Fatalf("Expected the second question as a new poll, got %d polls", len(polls))
+	}
+
+	_, correct, _ = lastPoll(t, api)
+	h.PollAnswerHandler(context.Background(), b, pollAnswerUpdate(api.LastPollID(t), (correct+1)%4))
+
+	if text := api.LastText(t); !strings.Contains(text, "Результат: 1 из 2 (50%)") {
+		t.Errorf("Expected round summary, got %q", text)
+	}
+
+	history, err := wordService.GetAnswerHistory(testUserID, 10)
+	if err != nil {
+		t.Fatalf("Failed to get answer history: %v", err)
+	}
+	if len(history) != 2 {
+		t.Errorf("Expected both poll answers in the review log, got %d", len(history))
+	}
+}
+
+func TestQuizPoll_IgnoresUnknownPoll(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.PollAnswerHandler(context.Background(), b, pollAnswerUpdate("unknown", 0))
+
+	if len(api.Calls("sendMessage")) != 0 || len(api.Calls("sendPoll")) != 0 {
+		t.Error("Expected no reply to a poll the bot did not send")
+	}
+}
+
+func TestQuizPoll_Abort(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+	if err := h.userService.SetQuizPresentation(testUserID, repository.PresentationPoll); err != nil {
+		t.Fatalf("Failed to set presentation: %v", err)
+	}
+
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz 2"))
+	_, _, params := lastPoll(t, api)
+
+	var markup models.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(params["reply_markup"]), &markup); err != nil {
+		t.Fatalf("Failed to parse reply markup: %v", err)
+	}
+	update := callbackUpdate(markup.InlineKeyboard[0][0].CallbackData, "")
+	update.CallbackQuery.Message.Message.Poll = &models.Poll{ID: api.LastPollID(t)}
+	h.CallbackHandler(context.Background(), b, update)
+
+	if len(api.Calls("stopPoll")) != 1 {
+		t.Error("Expected the poll to be closed")
+	}
+	if len(api.Calls("editMessageText")) != 0 {
+		t.Error("Expected no attempt to edit the poll text")
+	}
+	if text := api.LastText(t); !strings.Contains(text, "Тест прерван") {
+		t.Errorf("Expected abort summary, got %q", text)
+	}
+}
+
+func TestQuizPoll_ContinuesInTheRoundChat(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+	if err := h.userService.SetQuizPresentation(testUserID, repository.PresentationPoll); err != nil {
+		t.Fatalf("Failed to set presentation: %v", err)
+	}
+
+	const groupChatID = -100123
+	start := textUpdate("/quiz 2")
+	start.Message.Chat.ID = groupChatID
+	h.QuizHandler(context.Background(), b, start)
+
+	_, correct, _ := lastPoll(t, api)
+	// Голос другого участника группы не засчитывается в чужой тест
+	other := pollAnswerUpdate(api.LastPollID(t), correct)
+	other.PollAnswer.User.ID = testUserID + 1
+	h.PollAnswerHandler(context.Background(), b, other)
+	if polls := api.Calls("sendPoll"); len(polls) != 1 {
+		t.Fatalf("Expected no reply to another user's vote, got %d polls", len(polls))
+	}
+
+	h.PollAnswerHandler(context.Background(), b, pollAnswerUpdate(api.LastPollID(t), correct))
+
+	polls := api.Calls("sendPoll")
+	if len(polls) != 2 || polls[1].Params["chat_id"] != strconv.Itoa(groupChatID) {
+		t.Fatalf("Expected the second question in the group chat, got %+v", polls)
+	}
+}
+
+func TestQuizPoll_AbortsWhenNextPollFails(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+	if err := h.userService.SetQuizPresentation(testUserID, repository.PresentationPoll); err != nil {
+		t.Fatalf("Failed to set presentation: %v", err)
+	}
+
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz 3"))
+	_, correct, _ := lastPoll(t, api)
+	first := api.LastPollID(t)
+
+	api.Fail("sendPoll")
+	h.PollAnswerHandler(context.Background(), b, pollAnswerUpdate(first, correct))
+
+	text := api.LastText(t)
+	if !strings.Contains(text, "тест остановлен") || !strings.Contains(text, "Тест прерван") ||
+		!strings.Contains(text, "1 из 1") {
+		t.Errorf("Expected the failure notice with the summary, got %q", text)
+	}
+	round, err := h.quizService.PollRound(first)
+	if err != nil || round == nil || !round.Finished() || !round.Aborted {
+		t.Errorf("Expected the round to be aborted, got %+v, %v", round, err)
+	}
+}
@@ -0,0 +1,306 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// quizUsage подсказывает формат команды /quiz
+var quizUsage = fmt.Sprintf("Используйте формат: /quiz [количество вопросов] [#тег]\nПример: /quiz 10 #travel\n\n"+
+	"Без числа тест состоит из %d вопросов, максимум — %d. С тегом вопросы только по словам с этим тегом.",
+	service.DefaultQuizRoundSize, service.MaxQuizRoundSize)
+
+// QuizHandler обрабатывает команду /quiz [n] [#тег]
+func (h *BotHandlers) QuizHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+	log.Printf("Received /quiz command from user %d", userID)
+
+	arg, tag, errText := h.tagFilter(userID, strings.TrimPrefix(update.Message.Text, "/quiz"))
+	if errText != "" {
+		sendText(ctx, b, update.Message.Chat.ID, errText)
+		return
+	}
+
+	size := service.DefaultQuizRoundSize
+	if arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > service.MaxQuizRoundSize {
+			sendText(ctx, b, update.Message.Chat.ID, quizUsage)
+			return
+		}
+		size = n
+	}
+
+	h.startQuiz(ctx, b, update.Message.Chat.ID, userID, size, tag)
+}
+
+// startQuiz начинает раунд из size вопросов по словам с тегом tag (nil — по всем словам)
+// и отправляет первый вопрос
+func (h *BotHandlers) startQuiz(ctx context.Context, b *bot.Bot, chatID, userID int64, size int, tag *repository.Tag) {
+	mode, err := h.userService.GetQuizMode(userID)
+	if err != nil {
+		log.Printf("Failed to get user quiz mode: %v", err)
+		mode = repository.DirectionForward
+	}
+
+	tagID := 0
+	if tag != nil {
+		tagID = tag.ID
+	}
+	round, session, err := h.quizService.StartRound(userID, chatID, size, mode, tagID)
+	if err != nil {
+		log.Printf("Failed to generate quiz: %v", err)
+		sendText(ctx, b, chatID, "Не удалось создать тест. Сначала добавьте слова командой /add.")
+		return
+	}
+
+	presentation, err := h.userService.GetQuizPresentation(userID)
+	if err != nil {
+		log.Printf("Failed to get user quiz presentation: %v", err)
+	}
+	if presentation == repository.PresentationPoll {
+		if err := h.sendQuizPoll(ctx, b, chatID, round, session); err != nil {
+			log.Printf("Failed to start quiz round: %v", err)
+			h.abortUnsentRound(ctx, b, chatID, round)
+		}
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        quizQuestionText(round, session),
+		ReplyMarkup: quizKeyboard(round, session),
+	})
+	if err != nil {
+		log.Printf("Failed to send quiz message: %v", err)
+	}
+}
+
+// CallbackHandler обрабатывает callback запросы (ответы на тесты)
+func (h *BotHandlers) CallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	data := callback.Data
+
+	// Парсим данные callback'а: quiz_<сессия>_<вариант> или quiz_abort_<раунд>
+	parts := strings.Split(data, "_")
+	if len(parts) != 3 || parts[0] != "quiz" {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+	if parts[1] == "abort" {
+		h.abortQuizRound(ctx, b, callback, parts[2])
+		return
+	}
+
+	sessionID := parts[1]
+	selectedIdx, err := strconv.Atoi(parts[2])
+	if err != nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	scheduler, err := h.userService.GetScheduler(callback.From.ID)
+	if err != nil {
+		log.Printf("Failed to get user scheduler: %v", err)
+	}
+
+	// Проверяем ответ по сессии на сервере и обновляем статистику слова
+	result, err := h.quizService.AnswerQuiz(scheduler, callback.From.ID, sessionID, selectedIdx)
+	if err != nil {
+		log.Printf("Failed to answer quiz: %v", err)
+		answerCallback(ctx, b, callback.ID, quizErrorText(err))
+		return
+	}
+
+	var responseText string
+	if result.Correct {
+		responseText = "✅ Правильно! Отличная работа!"
+	} else {
+		responseText = fmt.Sprintf("❌ Неправильно. Правильный ответ: %s", result.CorrectOption)
+	}
+
+	// Отвечаем на callback
+	answerCallback(ctx, b, callback.ID, responseText)
+
+	// При повторном нажатии сообщение уже показывает следующий вопрос или итог
+	if result.AlreadyAnswered {
+		return
+	}
+
+	msg := callback.Message.Message
+	if msg == nil {
+		return
+	}
+
+	// Следующий вопрос или итог показываем в том же сообщении
+	params := &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text:      fmt.Sprintf("%s\n\n%s", msg.Text, responseText),
+	}
+	switch {
+	case result.Next != nil:
+		params.Text = responseText + "\n\n" + quizQuestionText(result.Round, result.Next)
+		params.ReplyMarkup = quizKeyboard(result.Round, result.Next)
+	case result.Summary != nil:
+		params.Text = responseText + "\n\n" + roundSummaryText(result.Summary)
+	}
+
+	if _, err := b.EditMessageText(ctx, params); err != nil {
+		log.Printf("Failed to edit message: %v", err)
+	}
+}
+
+// abortQuizRound досрочно завершает раунд и показывает итог
+func (h *BotHandlers) abortQuizRound(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, roundID string) {
+	summary, err := h.quizService.AbortRound(callback.From.ID, roundID)
+	if err != nil {
+		log.Printf("Failed to abort quiz round: %v", err)
+		answerCallback(ctx, b, callback.ID, quizErrorText(err))
+		return
+	}
+
+	answerCallback(ctx, b, callback.ID, "Тест прерван")
+
+	msg := callback.Message.Message
+	if msg != nil && msg.Poll != nil {
+		// Текст опроса не редактируется: закрываем опрос и присылаем итог отдельно
+		stopQuizPoll(ctx, b, msg.Chat.ID, msg.ID)
+		sendText(ctx, b, msg.Chat.ID, roundSummaryText(summary))
+		return
+	}
+	if msg != nil {
+		_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:    msg.Chat.ID,
+			MessageID: msg.ID,
+			Text:      roundSummaryText(summary),
+		})
+		if err != nil {
+			log.Printf("Failed to edit message: %v", err)
+		}
+	}
+}
+
+// quizQuestionText формирует текст вопроса с номером в раунде
+func quizQuestionText(round *repository.QuizRound, session *repository.QuizSession) string {
+	if round == nil || round.Size <= 1 {
+		return session.Question
+	}
+	return fmt.Sprintf("Вопрос %d из %d\n\n%s", session.Position+1, round.Size, session.Question)
+}
+
+// quizKeyboard формирует кнопки вариантов ответа и кнопку остановки раунда
+func quizKeyboard(round *repository.QuizRound, session *repository.QuizSession) *models.InlineKeyboardMarkup {
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: make([][]models.InlineKeyboardButton, 0, len(session.Options)+1),
+	}
+	for i, option := range session.Options {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []models.InlineKeyboardButton{{
+			Text:         fmt.Sprintf("%d. %s", i+1, option),
+			CallbackData: fmt.Sprintf("quiz_%s_%d", session.ID, i),
+		}})
+	}
+	if abort := abortButton(round); abort != nil {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []models.InlineKeyboardButton{*abort})
+	}
+	return keyboard
+}
+
+// abortButton возвращает кнопку остановки раунда; у теста из одного вопроса ее нет
+func abortButton(round *repository.QuizRound) *models.InlineKeyboardButton {
+	if round == nil || round.Size <= 1 {
+		return nil
+	}
+	return &models.InlineKeyboardButton{
+		Text:         "⏹ Прервать тест",
+		CallbackData: "quiz_abort_" + round.ID,
+	}
+}
+
+// roundSummaryText формирует итог раунда: счет, ошибки и сдвиг дат повторения
+func roundSummaryText(summary *service.RoundSummary) string {
+	var text strings.Builder
+
+	if summary.Round.Aborted {
+		text.WriteString("⏹ Тест прерван\n\n")
+	} else {
+		text.WriteString("🏁 Тест завершен!\n\n")
+	}
+
+	if summary.Answered == 0 {
+		text.WriteString("Вы не ответили ни на один вопрос.")
+		return text.String()
+	}
+
+	text.WriteString(fmt.Sprintf("Результат: %d из %d (%d%%)\n", summary.Correct, summary.Answered,
+		summary.Correct*100/summary.Answered))
+
+	if missed := summary.Missed(); len(missed) > 0 {
+		text.WriteString("\n❌ Ошибки:\n")
+		for _, item := range missed {
+			text.WriteString(fmt.Sprintf("• %s — %s\n", item.Word, item.Translation))
+		}
+	}
+
+	text.WriteString("\n📅 Следующее повторение:\n")
+	for _, item := range summary.Items {
+		mark := "✅"
+		if !item.Correct {
+			mark = "❌"
+		}
+		text.WriteString(fmt.Sprintf("%s %s: %s\n", mark, item.Word, reviewDateChange(item)))
+	}
+
+	return strings.TrimRight(text.String(), "\n")
+}
+
+// reviewDateChange описывает, как сдвинулась дата следующего повторения
+func reviewDateChange(item service.RoundItem) string {
+	const layout = "02.01"
+	if item.NextReviewBefore.IsZero() || item.NextReviewAfter.IsZero() {
+		return "—"
+	}
+
+	before := item.NextReviewBefore.Format(layout)
+	after := item.NextReviewAfter.Format(layout)
+	if before == after {
+		return after + " (без изменений)"
+	}
+	return before + " → " + after
+}
+
+// quizErrorText возвращает понятное пользователю описание ошибки ответа на тест
+func quizErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrQuizSessionExpired):
+		return "⌛ Время на ответ истекло. Начните новый тест командой /quiz"
+	case errors.Is(err, service.ErrQuizRoundFinished):
+		return "Этот тест уже завершен. Начните новый командой /quiz"
+	case errors.Is(err, service.ErrQuizSessionForbidden):
+		return "Этот тест предназначен другому пользователю."
+	case errors.Is(err, service.ErrQuizSessionNotFound):
+		return "Тест не найден. Начните новый командой /quiz"
+	default:
+		return "Не удалось проверить ответ. Попробуйте позже."
+	}
+}
+
+// answerCallback отвечает на callback запрос коротким уведомлением
+func answerCallback(ctx context.Context, b *bot.Bot, callbackID, text string) {
+	_, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackID,
+		Text:            text,
+	})
+	if err != nil {
+		log.Printf("Failed to answer callback query: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestQuizHandler_InvalidRoundSize(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+
+	for _, text := range []string{"/quiz abc", "/quiz 0", "/quiz 21"} {
+		h.QuizHandler(context.Background(), b, textUpdate(text))
+
+		if got := api.LastText(t); !strings.Contains(got, "Используйте формат: /quiz") {
+			t.Errorf("Expected usage hint for %q, got %q", text, got)
+		}
+	}
+}
+
+func TestQuizRound_EditsSameMessageAndSummarizes(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz 2"))
+	question, correct, _ := quizAnswers(t, api)
+	h.CallbackHandler(context.Background(), b, callbackUpdate(correct, question))
+
+	edits := api.Calls("editMessageText")
+	if len(edits) != 1 || !strings.Contains(edits[0].Params["text"], "Вопрос 2 из 2") {
+		t.Fatalf("Expected the message to show question 2, got %+v", edits)
+	}
+	if edits[0].Params["message_id"] != "7" {
+		t.Errorf("Expected the quiz message to be edited, got message %s", edits[0].Params["message_id"])
+	}
+
+	question, _, wrong := quizAnswers(t, api)
+	missed := strings.TrimSuffix(strings.TrimPrefix(question[strings.LastIndex(question, "\n")+1:],
+		"Как переводится слово: "), "?")
+	h.CallbackHandler(context.Background(), b, callbackUpdate(wrong, question))
+
+	if len(api.Calls("sendMessage")) != 1 {
+		t.Error("Expected the whole round to use a single message")
+	}
+	summary := api.LastText(t)
+	for _, want := range []string{"Тест завершен", "Результат: 1 из 2 (50%)", "Ошибки",
+		"• " + missed + " — " + missed + "-ru", "Следующее повторение"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected summary to contain %q, got %q", want, summary)
+		}
+	}
+	if strings.Contains(api.Calls("editMessageText")[1].Params["reply_markup"], "quiz_") {
+		t.Error("Expected the summary to have no quiz buttons")
+	}
+}
+
+func TestQuizRound_Abort(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz 3"))
+	question, correct, _ := quizAnswers(t, api)
+	h.CallbackHandler(context.Background(), b, callbackUpdate(correct, question))
+
+	question, correct, _ = quizAnswers(t, api)
+	h.CallbackHandler(context.Background(), b, callbackUpdate(abortData(t, api), question))
+
+	summary := api.LastText(t)
+	if !strings.Contains(summary, "Тест прерван") || !strings.Contains(summary, "Результат: 1 из 1") {
+		t.Errorf("Expected summary of the aborted round, got %q", summary)
+	}
+
+	// Вопрос прерванного раунда больше не принимает ответы
+	h.CallbackHandler(context.Background(), b, callbackUpdate(correct, question))
+	calls := api.Calls("answerCallbackQuery")
+	if text := calls[len(calls)-1].Params["text"]; !strings.Contains(text, "уже завершен") {
+		t.Errorf("Expected answer to a finished round to be rejected, got %q", text)
+	}
+	history, _ := wordService.GetAnswerHistory(testUserID, 10)
+	if len(history) != 1 {
+		t.Errorf("Expected only the first answer to be logged, got %d", len(history))
+	}
+}
@@ -0,0 +1,260 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// reviewDoneText показывается, когда все карточки на сегодня повторены
+const reviewDoneText = "🎉 Отлично! Сейчас нет слов для повторения. Проверьте позже или добавьте новые слова!"
+
+// ratingButtons — кнопки самооценки в порядке показа
+var ratingButtons = []struct {
+	rating int
+	text   string
+}{
+	{service.RatingAgain, "🔁 Снова"},
+	{service.RatingHard, "😓 Трудно"},
+	{service.RatingGood, "🙂 Хорошо"},
+	{service.RatingEasy, "😎 Легко"},
+}
+
+// ReviewHandler обрабатывает команду /review [#тег]: показывает первую карточку для повторения
+func (h *BotHandlers) ReviewHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+
+	_, tag, errText := h.tagFilter(userID, strings.TrimPrefix(update.Message.Text, "/review"))
+	if errText != "" {
+		sendText(ctx, b, update.Message.Chat.ID, errText)
+		return
+	}
+
+	h.startReview(ctx, b, update.Message.Chat.ID, userID, tag)
+}
+
+// startReview отправляет первую карточку для повторения из слов с тегом tag (nil — из всех слов)
+func (h *BotHandlers) startReview(ctx context.Context, b *bot.Bot, chatID, userID int64, tag *repository.Tag) {
+	tagID := 0
+	if tag != nil {
+		tagID = tag.ID
+	}
+
+	card, err := h.wordService.NextCard(userID, tagID)
+	if err != nil {
+		log.Printf("Failed to get words for review: %v", err)
+		sendText(ctx, b, chatID, "Ошибка при получении слов для повторения.")
+		return
+	}
+	if card == nil {
+		if tag != nil {
+			sendText(ctx, b, chatID, fmt.Sprintf("🎉 Сейчас нет слов с тегом #%s для повторения. Проверьте позже!", tag.Name))
+			return
+		}
+		sendText(ctx, b, chatID, reviewDoneText)
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        cardFrontText(card),
+		ReplyMarkup: showAnswerKeyboard(card, tagID),
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// ReviewCallbackHandler обрабатывает кнопки карточки:
+// review_show_<слово>_<тег>_<показана> открывает ответ, а
+// review_grade_<слово>_<оценка>_<тег>_<задержка> оценивает карточку. Тег 0 —
+// повторяются все слова, <показана> — время показа карточки в миллисекундах
+// Unix, <задержка> — за сколько миллисекунд пользователь вспомнил перевод.
+// Кнопки прежних версий без задержки и тега тоже принимаются.
+func (h *BotHandlers) ReviewCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	parts := strings.Split(callback.Data, "_")
+	if len(parts) < 3 || parts[0] != "review" {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+	wordID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	switch {
+	case parts[1] == "show" && len(parts) >= 3 && len(parts) <= 5:
+		h.showCardAnswer(ctx, b, callback, wordID, optionalTagID(parts, 3), recallLatency(parts, 4))
+	case parts[1] == "grade" && len(parts) >= 4 && len(parts) <= 6:
+		rating, err := strconv.Atoi(parts[3])
+		if err != nil {
+			answerCallback(ctx, b, callback.ID, "")
+			return
+		}
+		latency := time.Duration(optionalMillis(parts, 5)) * time.Millisecond
+		h.gradeCard(ctx, b, callback, wordID, rating, optionalTagID(parts, 4), latency)
+	default:
+		answerCallback(ctx, b, callback.ID, "")
+	}
+}
+
+// optionalTagID возвращает ID тега из parts[i] или 0, если его нет
+func optionalTagID(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	tagID, _ := strconv.Atoi(parts[i])
+	return tagID
+}
+
+// optionalMillis возвращает число миллисекунд из parts[i] или 0, если его нет
+func optionalMillis(parts []string, i int) int64 {
+	if i >= len(parts) {
+		return 0
+	}
+	ms, _ := strconv.ParseInt(parts[i], 10, 64)
+	return ms
+}
+
+// recallLatency возвращает, сколько прошло с показа карточки, время которого
+// передано в parts[i]; 0, если время неизвестно
+func recallLatency(parts []string, i int) time.Duration {
+	shownAt := optionalMillis(parts, i)
+	if shownAt <= 0 {
+		return 0
+	}
+	return max(time.Since(time.UnixMilli(shownAt)), 0)
+}
+
+// showCardAnswer показывает оборот карточки с кнопками самооценки; latency —
+// за сколько пользователь вспомнил перевод, она передается в кнопки оценки
+func (h *BotHandlers) showCardAnswer(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery,
+	wordID, tagID int, latency time.Duration) {
+	card, err := h.wordService.GetWord(callback.From.ID, wordID)
+	if err != nil {
+		log.Printf("Failed to get word: %v", err)
+	}
+	if card == nil {
+		answerCallback(ctx, b, callback.ID, "Слово не найдено. Начните заново командой /review")
+		return
+	}
+
+	answerCallback(ctx, b, callback.ID, "")
+	if msg := callback.Message.Message; msg != nil {
+		_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        cardBackText(card),
+			ReplyMarkup: gradeKeyboard(card, tagID, latency),
+		})
+		if err != nil {
+			log.Printf("Failed to edit message: %v", err)
+		}
+	}
+}
+
+// gradeCard сохраняет самооценку и показывает следующую карточку в том же сообщении
+func (h *BotHandlers) gradeCard(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery,
+	wordID, rating, tagID int, latency time.Duration) {
+	userID := callback.From.ID
+
+	scheduler, err := h.userService.GetScheduler(userID)
+	if err != nil {
+		log.Printf("Failed to get user scheduler: %v", err)
+	}
+
+	change, err := h.wordService.GradeCard(scheduler, userID, wordID, rating, latency)
+	if err != nil {
+		if !errors.Is(err, service.ErrCardNotDue) && !errors.Is(err, service.ErrCardNotFound) {
+			log.Printf("Failed to grade card: %v", err)
+		}
+		answerCallback(ctx, b, callback.ID, reviewErrorText(err))
+		return
+	}
+
+	answerCallback(ctx, b, callback.ID, fmt.Sprintf("Следующее повторение: %s",
+		change.After.NextReview.Format("02.01")))
+
+	msg := callback.Message.Message
+	if msg == nil {
+		return
+	}
+
+	next, err := h.wordService.NextCard(userID, tagID)
+	if err != nil {
+		log.Printf("Failed to get words for review: %v", err)
+	}
+
+	params := &bot.EditMessageTextParams{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Text:      reviewDoneText,
+	}
+	if next != nil {
+		params.Text = cardFrontText(next)
+		params.ReplyMarkup = showAnswerKeyboard(next, tagID)
+	}
+	if _, err := b.EditMessageText(ctx, params); err != nil {
+		log.Printf("Failed to edit message: %v", err)
+	}
+}
+
+// cardFrontText формирует лицевую сторону карточки
+func cardFrontText(card *repository.Word) string {
+	return fmt.Sprintf("🃏 %s\n\nВспомните перевод и откройте ответ.", card.Word)
+}
+
+// cardBackText формирует оборот карточки: перевод и контекст
+func cardBackText(card *repository.Word) string {
+	text := fmt.Sprintf("🃏 %s — %s", card.Word, card.Translation)
+	if card.Context != "" {
+		text += fmt.Sprintf("\n\n📝 %s", card.Context)
+	}
+	return text + "\n\nНасколько легко вы вспомнили?"
+}
+
+// showAnswerKeyboard формирует кнопку, открывающую оборот карточки; в нее
+// записывается время показа, чтобы измерить, как быстро вспомнили перевод
+func showAnswerKeyboard(card *repository.Word, tagID int) *models.InlineKeyboardMarkup {
+	data := fmt.Sprintf("review_show_%d_%d_%d", card.ID, tagID, time.Now().UnixMilli())
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: "👀 Показать ответ", CallbackData: data}},
+		},
+	}
+}
+
+// gradeKeyboard формирует кнопки самооценки
+func gradeKeyboard(card *repository.Word, tagID int, latency time.Duration) *models.InlineKeyboardMarkup {
+	row := make([]models.InlineKeyboardButton, 0, len(ratingButtons))
+	for _, button := range ratingButtons {
+		row = append(row, models.InlineKeyboardButton{
+			Text:         button.text,
+			CallbackData: fmt.Sprintf("review_grade_%d_%d_%d_%d", card.ID, button.rating, tagID, latency.Milliseconds()),
+		})
+	}
+	return &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{row}}
+}
+
+// reviewErrorText возвращает понятное пользователю описание ошибки оценки карточки
+func reviewErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrCardNotDue):
+		return "Эта карточка уже оценена."
+	case errors.Is(err, service.ErrCardNotFound):
+		return "Слово не найдено. Начните заново командой /review"
+	default:
+		return "Не удалось сохранить оценку. Попробуйте позже."
+	}
+}
@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+)
+
+// makeDue делает все слова пользователя готовыми к повторению в порядке добавления
+func makeDue(t *testing.T, db *repository.MemoryDatabase, wordService *service.WordService) {
+	t.Helper()
+
+	words, err := wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	sort.Slice(words, func(i, j int) bool { return words[i].ID < words[j].ID })
+
+	repo := repository.NewMemoryWordRepository(db)
+	for i, word := range words {
+		state := word.ReviewState
+		// Разные даты задают порядок показа карточек
+		state.NextReview = time.Now().Add(-time.Duration(len(words)-i) * time.Hour)
+		if err := repo.SaveReviewState(word.ID, state); err != nil {
+			t.Fatalf("Failed to save review state: %v", err)
+		}
+	}
+}
+
+func TestReviewHandler_NothingDue(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+
+	h.ReviewHandler(context.Background(), b, textUpdate("/review"))
+
+	if text := api.LastText(t); !strings.Contains(text, "нет слов для повторения") {
+		t.Errorf("Expected nothing-due message, got %q", text)
+	}
+}
+
+func TestReviewFlashcards_GoThroughAllDueCards(t *testing.T) {
+	h, wordService, db := newTestHandlersWithDB(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear")
+	if err := wordService.AddWord(testUserID, "plum", "слива", "a ripe plum"); err != nil {
+		t.Fatalf("Failed to add word: %v", err)
+	}
+	makeDue(t, db, wordService)
+
+	h.ReviewHandler(context.Background(), b, textUpdate("/review"))
+
+	front := api.LastText(t)
+	if !strings.Contains(front, "🃏 apple") || strings.Contains(front, "apple-ru") {
+		t.Fatalf("Expected the front of the first card, got %q", front)
+	}
+	words, _ := wordService.GetUserWords(testUserID)
+	ids := wordsByWord(words)
+
+	h.ReviewCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("review_show_%d", ids["apple"]), front))
+	back := api.LastText(t)
+	if !strings.Contains(back, "apple — apple-ru") {
+		t.Errorf("Expected the translation on the back, got %q", back)
+	}
+	markup := api.Calls("editMessageText")[0].Params["reply_markup"]
+	for _, grade := range []string{"Снова", "Трудно", "Хорошо", "Легко"} {
+		if !strings.Contains(markup, grade) {
+			t.Errorf("Expected %q button, got %s", grade, markup)
+		}
+	}
+
+	grade := func(word string, rating int) {
+		data := fmt.Sprintf("review_grade_%d_%d", ids[word], rating)
+		h.ReviewCallbackHandler(context.Background(), b, callbackUpdate(data, ""))
+	}
+
+	grade("apple", service.RatingGood)
+	if text := api.LastText(t); !strings.Contains(text, "🃏 pear") {
+		t.Fatalf("Expected the next card, got %q", text)
+	}
+	// Повторное нажатие на оцененную карточку не меняет расписание
+	grade("apple", service.RatingEasy)
+	answers := api.Calls("answerCallbackQuery")
+	if last := answers[len(answers)-1].Params["text"]; !strings.Contains(last, "уже оценена") {
+		t.Errorf("Expected already graded notice, got %q", last)
+	}
+
+	grade("pear", service.RatingAgain)
+	h.ReviewCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("review_show_%d", ids["plum"]), ""))
+	if text := api.LastText(t); !strings.Contains(text, "a ripe plum") {
+		t.Errorf("Expected context on the back, got %q", text)
+	}
+	grade("plum", service.RatingEasy)
+
+	if text := api.LastText(t); !strings.Contains(text, "нет слов для повторения") {
+		t.Errorf("Expected the session to end after all due cards, got %q", text)
+	}
+	if len(api.Calls("sendMessage")) != 1 {
+		t.Error("Expected the whole session to use a single message")
+	}
+
+	history, err := wordService.GetAnswerHistory(testUserID, 10)
+	if err != nil {
+		t.Fatalf("Failed to get answer history: %v", err)
+	}
+	if len(history) != 3 {
+		t.Errorf("Expected three graded cards in the review log, got %d", len(history))
+	}
+}
+
+// wordsByWord возвращает ID слов по их написанию
+func wordsByWord(words []*repository.Word) map[string]int {
+	ids := make(map[string]int, len(words))
+	for _, word := range words {
+		ids[word.Word] = word.ID
+	}
+	return ids
+}
+
+func TestReviewCallbackHandler_AnswersMalformedData(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	for i, data := range []string{"review", "review_show_x", "review_grade_1_x", "review_skip_1"} {
+		h.ReviewCallbackHandler(context.Background(), b, callbackUpdate(data, "🃏"))
+		// Без ответа на callback клиент Telegram показывает загрузку до таймаута
+		if answers := api.Calls("answerCallbackQuery"); len(answers) != i+1 {
+			t.Errorf("%s: expected the callback to be answered, got %d answers", data, len(answers))
+		}
+	}
+}
+
+func TestReviewCallbackHandler_RecordsRecallLatency(t *testing.T) {
+	h, wordService, db := newTestHandlersWithDB(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+	makeDue(t, db, wordService)
+	words, _ := wordService.GetUserWords(testUserID)
+
+	// Карточку показали полторы секунды назад
+	shownAt := time.Now().Add(-1500 * time.Millisecond).UnixMilli()
+	show := fmt.Sprintf("review_show_%d_0_%d", words[0].ID, shownAt)
+	h.ReviewCallbackHandler(context.Background(), b, callbackUpdate(show, "🃏 apple"))
+
+	markup := api.Calls("editMessageText")[0].Params["reply_markup"]
+	grade := regexp.MustCompile(`review_grade_\d+_3_0_\d+`).FindString(markup)
+	if grade == "" {
+		t.Fatalf("Expected grade buttons with latency, got %s", markup)
+	}
+	h.ReviewCallbackHandler(context.Background(), b, callbackUpdate(grade, ""))
+
+	history, _ := wordService.GetAnswerHistory(testUserID, 10)
+	if len(history) != 1 || history[0].LatencyMs < 1500 || history[0].LatencyMs > 60000 {
+		t.Errorf("Expected the recall latency in the log, got %+v", history)
+	}
+}
@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// schedulerNames содержит названия алгоритмов повторения для интерфейса
+var schedulerNames = map[string]string{
+	repository.SchedulerSM2:  "SM-2",
+	repository.SchedulerFSRS: "FSRS",
+}
+
+// quizModeNames содержит названия режимов /quiz для интерфейса
+var quizModeNames = map[string]string{
+	repository.DirectionForward: "EN → RU",
+	repository.DirectionReverse: "RU → EN",
+	repository.QuizModeMixed:    "Смешанный",
+}
+
+// presentationNames содержит названия способов показа вопросов /quiz для интерфейса
+var presentationNames = map[string]string{
+	repository.PresentationButtons: "Кнопки",
+	repository.PresentationPoll:    "Опрос Telegram",
+}
+
+// SettingsHandler обрабатывает команду /settings
+func (h *BotHandlers) SettingsHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+
+	scheduler, err := h.userService.GetScheduler(userID)
+	if err != nil {
+		log.Printf("Failed to get user scheduler: %v", err)
+		sendText(ctx, b, update.Message.Chat.ID, "Ошибка при получении настроек.")
+		return
+	}
+	mode, err := h.userService.GetQuizMode(userID)
+	if err != nil {
+		log.Printf("Failed to get user quiz mode: %v", err)
+		sendText(ctx, b, update.Message.Chat.ID, "Ошибка при получении настроек.")
+		return
+	}
+	presentation, err := h.userService.GetQuizPresentation(userID)
+	if err != nil {
+		log.Printf("Failed to get user quiz presentation: %v", err)
+		sendText(ctx, b, update.Message.Chat.ID, "Ошибка при получении настроек.")
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        settingsText(scheduler, mode, presentation),
+		ReplyMarkup: settingsKeyboard(scheduler, mode, presentation),
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// SettingsCallbackHandler обрабатывает нажатия кнопок в /settings
+func (h *BotHandlers) SettingsCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	userID := callback.From.ID
+
+	var responseText string
+	switch {
+	case strings.HasPrefix(callback.Data, "settings_scheduler_"):
+		scheduler := strings.TrimPrefix(callback.Data, "settings_scheduler_")
+		if _, ok := schedulerNames[scheduler]; !ok {
+			return
+		}
+		responseText = h.switchScheduler(userID, scheduler)
+	case strings.HasPrefix(callback.Data, "settings_quizmode_"):
+		mode := strings.TrimPrefix(callback.Data, "settings_quizmode_")
+		if _, ok := quizModeNames[mode]; !ok {
+			return
+		}
+		responseText = h.switchQuizMode(userID, mode)
+	case strings.HasPrefix(callback.Data, "settings_quizview_"):
+		presentation := strings.TrimPrefix(callback.Data, "settings_quizview_")
+		if _, ok := presentationNames[presentation]; !ok {
+			return
+		}
+		responseText = h.switchQuizPresentation(userID, presentation)
+	default:
+		return
+	}
+
+	_, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callback.ID,
+		Text:            responseText,
+	})
+	if err != nil {
+		log.Printf("Failed to answer callback query: %v", err)
+	}
+
+	scheduler, err := h.userService.GetScheduler(userID)
+	if err != nil {
+		log.Printf("Failed to get user scheduler: %v", err)
+	}
+	mode, err := h.userService.GetQuizMode(userID)
+	if err != nil {
+		log.Printf("Failed to get user quiz mode: %v", err)
+	}
+	presentation, err := h.userService.GetQuizPresentation(userID)
+	if err != nil {
+		log.Printf("Failed to get user quiz presentation: %v", err)
+	}
+
+	if msg := callback.Message.Message; msg != nil {
+		_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.ID,
+			Text:        settingsText(scheduler, mode, presentation),
+			ReplyMarkup: settingsKeyboard(scheduler, mode, presentation),
+		})
+		if err != nil {
+			log.Printf("Failed to edit message: %v", err)
+		}
+	}
+}
+
+// switchScheduler переключает алгоритм повторения и возвращает ответ на нажатие
+func (h *BotHandlers) switchScheduler(userID int64, scheduler string) string {
+	current, err := h.userService.GetScheduler(userID)
+	if err != nil {
+		log.Printf("Failed to get user scheduler: %v", err)
+	}
+	if current == scheduler {
+		return fmt.Sprintf("Алгоритм %s уже выбран", schedulerNames[scheduler])
+	}
+
+	// Сначала переносим состояние слов, затем сохраняем выбор
+	err = h.wordService.ConvertWordsToScheduler(userID, scheduler)
+	if err == nil {
+		err = h.userService.SetScheduler(userID, scheduler)
+	}
+	if err != nil {
+		log.Printf("Failed to switch scheduler: %v", err)
+		return "Не удалось сменить алгоритм. Попробуйте позже."
+	}
+
+	return fmt.Sprintf("✅ Теперь используется %s", schedulerNames[scheduler])
+}
+
+// switchQuizMode сохраняет режим /quiz и возвращает ответ на нажатие
+func (h *BotHandlers) switchQuizMode(userID int64, mode string) string {
+	if err := h.userService.SetQuizMode(userID, mode); err != nil {
+		log.Printf("Failed to switch quiz mode: %v", err)
+		return "Не удалось сменить режим теста. Попробуйте позже."
+	}
+
+	return fmt.Sprintf("✅ Режим теста: %s", quizModeNames[mode])
+}
+
+// switchQuizPresentation сохраняет способ показа вопросов /quiz и возвращает ответ на нажатие
+func (h *BotHandlers) switchQuizPresentation(userID int64, presentation string) string {
+	if err := h.userService.SetQuizPresentation(userID, presentation); err != nil {
+		log.Printf("Failed to switch quiz presentation: %v", err)
+		return "Не удалось сменить вид теста. Попробуйте позже."
+	}
+
+	return fmt.Sprintf("✅ Вид теста: %s", presentationNames[presentation])
+}
+
+// settingsText формирует описание текущих настроек
+func settingsText(scheduler, mode, presentation string) string {
+	return fmt.Sprintf(`⚙️ Настройки
+
+🔄 Алгоритм повторения: %s
+
+SM-2 — классический алгоритм: интервал растет с каждым правильным ответом.
+FSRS — современный алгоритм, который моделирует вероятность вспомнить слово.
+
+Интервалы и даты повторения сохраняются при переключении.
+
+🧠 Режим теста: %s
+
+EN → RU — выбрать перевод английского слова.
+RU → EN — выбрать английское слово по переводу.
+Смешанный — направления чередуются.
+
+У каждого направления свое расписание повторений.
+
+📊 Вид теста: %s
+
+Кнопки — варианты ответа кнопками под сообщением.
+Опрос Telegram — вопрос приходит встроенной викториной Telegram.`, schedulerNames[scheduler], quizModeNames[mode],
+		presentationNames[presentation])
+}
+
+// settingsKeyboard формирует кнопки выбора алгоритма, режима и вида теста
+func settingsKeyboard(currentScheduler, currentMode, currentPresentation string) *models.InlineKeyboardMarkup {
+	schedulers := make([]models.InlineKeyboardButton, 0, 2)
+	for _, scheduler := range []string{repository.SchedulerSM2, repository.SchedulerFSRS} {
+		text := schedulerNames[scheduler]
+		if scheduler == currentScheduler {
+			text = "✅ " + text
+		}
+		schedulers = append(schedulers, models.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: "settings_scheduler_" + scheduler,
+		})
+	}
+
+	modes := make([]models.InlineKeyboardButton, 0, len(service.QuizModes))
+	for _, mode := range service.QuizModes {
+		text := quizModeNames[mode]
+		if mode == currentMode {
+			text = "✅ " + text
+		}
+		modes = append(modes, models.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: "settings_quizmode_" + mode,
+		})
+	}
+
+	presentations := make([]models.InlineKeyboardButton, 0, len(service.QuizPresentations))
+	for _, presentation := range service.QuizPresentations {
+		text := presentationNames[presentation]
+		if presentation == currentPresentation {
+			text = "✅ " + text
+		}
+		presentations = append(presentations, models.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: "settings_quizview_" + presentation,
+		})
+	}
+
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{schedulers, modes, presentations},
+	}
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestSettingsCallbackHandler_SwitchesScheduler(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.SettingsCallbackHandler(context.Background(), b, callbackUpdate("settings_scheduler_fsrs", "⚙️ Настройки"))
+
+	scheduler, err := h.userService.GetScheduler(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get scheduler: %v", err)
+	}
+	if scheduler != repository.SchedulerFSRS {
+		t.Errorf("Expected scheduler %q, got %q", repository.SchedulerFSRS, scheduler)
+	}
+	if text := api.LastText(t); !strings.Contains(text, "Алгоритм повторения: FSRS") {
+		t.Errorf("Expected settings message to show FSRS, got %q", text)
+	}
+}
+
+func TestSettingsCallbackHandler_IgnoresUnknownScheduler(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.SettingsCallbackHandler(context.Background(), b, callbackUpdate("settings_scheduler_leitner", "⚙️ Настройки"))
+
+	scheduler, _ := h.userService.GetScheduler(testUserID)
+	if scheduler != repository.SchedulerSM2 {
+		t.Errorf("Expected scheduler to stay %q, got %q", repository.SchedulerSM2, scheduler)
+	}
+	if len(api.Calls("editMessageText")) != 0 {
+		t.Error("Expected no message edits for unknown scheduler")
+	}
+}
+
+func TestSettingsCallbackHandler_SwitchesQuizMode(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "lemon")
+
+	h.SettingsCallbackHandler(context.Background(), b, callbackUpdate("settings_quizmode_reverse", "⚙️ Настройки"))
+
+	if text := api.LastText(t); !strings.Contains(text, "Режим теста: RU → EN") {
+		t.Errorf("Expected settings message to show reverse mode, got %q", text)
+	}
+
+	// Тест теперь спрашивает слово по переводу
+	h.QuizHandler(context.Background(), b, textUpdate("/quiz 1"))
+	if text := api.LastText(t); !strings.HasPrefix(text, "Как по-английски: ") {
+		t.Errorf("Expected reverse question, got %q", text)
+	}
+}
+
+func TestSettingsCallbackHandler_SwitchesQuizPresentation(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.SettingsCallbackHandler(context.Background(), b, callbackUpdate("settings_quizview_poll", "⚙️ Настройки"))
+
+	presentation, err := h.userService.GetQuizPresentation(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get presentation: %v", err)
+	}
+	if presentation != repository.PresentationPoll {
+		t.Errorf("Expected presentation %q, got %q", repository.PresentationPoll, presentation)
+	}
+	if text := api.LastText(t); !strings.Contains(text, "Вид теста: Опрос Telegram") {
+		t.Errorf("Expected settings message to show poll presentation, got %q", text)
+	}
+}
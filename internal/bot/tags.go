@@ -0,0 +1,210 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// tagsUsage подсказывает формат команд /tag и /untag
+const tagsUsage = "Используйте формат: /tag [номер] #тег или /untag [номер] #тег\nПример: /tag 1 #travel #verbs\n\n" +
+	"Номера слов — в списке /words. Тег начинается с буквы, в нем можно использовать буквы, цифры, _ и -."
+
+// TagsHandler обрабатывает команду /tags: показывает теги пользователя
+// с числом слов и кнопками списка, теста и повторения по тегу
+func (h *BotHandlers) TagsHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+	log.Printf("Received /tags command from user %d", userID)
+
+	tags, err := h.wordService.GetTags(userID)
+	if err != nil {
+		log.Printf("Failed to get user tags: %v", err)
+		sendText(ctx, b, update.Message.Chat.ID, "Ошибка при получении тегов.")
+		return
+	}
+	if len(tags) == 0 {
+		sendText(ctx, b, update.Message.Chat.ID, "У вас пока нет тегов.\n\n"+
+			"Добавьте тег вместе со словом: /add train - поезд #travel\nили к уже сохраненному слову: /tag 1 #travel")
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        tagsText(tags),
+		ReplyMarkup: tagsKeyboard(tags),
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// TagHandler обрабатывает команду /tag [номер] #тег…: добавляет теги слову
+func (h *BotHandlers) TagHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	h.changeTags(ctx, b, update, "/tag", h.wordService.AddTags)
+}
+
+// UntagHandler обрабатывает команду /untag [номер] #тег…: убирает теги у слова
+func (h *BotHandlers) UntagHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	h.changeTags(ctx, b, update, "/untag", h.wordService.RemoveTags)
+}
+
+// changeTags разбирает номер слова и теги из команды и меняет теги слова функцией change
+func (h *BotHandlers) changeTags(ctx context.Context, b *bot.Bot, update *models.Update, command string,
+	change func(userID int64, wordID int, tags []string) ([]string, error)) {
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+
+	number, tags := service.ExtractTags(strings.TrimPrefix(update.Message.Text, command))
+	if number == "" || len(tags) == 0 {
+		sendText(ctx, b, chatID, tagsUsage)
+		return
+	}
+
+	word, errText := h.wordByNumber(userID, number)
+	if word == nil {
+		sendText(ctx, b, chatID, errText)
+		return
+	}
+
+	tags, err := change(userID, word.ID, tags)
+	if err != nil {
+		if !errors.Is(err, service.ErrWordNotFound) {
+			log.Printf("Failed to change word tags: %v", err)
+		}
+		sendText(ctx, b, chatID, tagErrorText(err))
+		return
+	}
+
+	if len(tags) == 0 {
+		sendText(ctx, b, chatID, fmt.Sprintf("🏷 У слова '%s' больше нет тегов.", word.Word))
+		return
+	}
+	sendText(ctx, b, chatID, fmt.Sprintf("🏷 Теги слова '%s': %s", word.Word, formatTags(tags)))
+}
+
+// TagsCallbackHandler обрабатывает кнопки списка /tags: tags_quiz_<тег>
+// начинает тест, tags_review_<тег> — повторение слов с тегом. Список слов
+// с тегом открывает WordsCallbackHandler.
+func (h *BotHandlers) TagsCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	userID := callback.From.ID
+
+	parts := strings.Split(callback.Data, "_")
+	msg := callback.Message.Message
+	if len(parts) != 3 || msg == nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+	tagID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	tag, err := h.wordService.GetTag(userID, tagID)
+	if err != nil {
+		if !errors.Is(err, service.ErrTagNotFound) {
+			log.Printf("Failed to get tag: %v", err)
+		}
+		answerCallback(ctx, b, callback.ID, tagErrorText(err))
+		return
+	}
+
+	switch parts[1] {
+	case "quiz":
+		answerCallback(ctx, b, callback.ID, "")
+		h.startQuiz(ctx, b, msg.Chat.ID, userID, service.DefaultQuizRoundSize, tag)
+	case "review":
+		answerCallback(ctx, b, callback.ID, "")
+		h.startReview(ctx, b, msg.Chat.ID, userID, tag)
+	default:
+		answerCallback(ctx, b, callback.ID, "")
+	}
+}
+
+// tagFilter выделяет из аргументов команды тег-фильтр. Возвращает остальной
+// текст и найденный тег (nil, если тега нет) или текст ошибки для пользователя.
+func (h *BotHandlers) tagFilter(userID int64, args string) (string, *repository.Tag, string) {
+	rest, tags := service.ExtractTags(args)
+	switch {
+	case len(tags) == 0:
+		return rest, nil, ""
+	case len(tags) > 1:
+		return rest, nil, "Укажите только один тег, например: #travel"
+	}
+
+	tag, err := h.wordService.FindTag(userID, tags[0])
+	if errors.Is(err, service.ErrTagNotFound) {
+		return rest, nil, fmt.Sprintf("У вас нет слов с тегом #%s. Список тегов: /tags", tags[0])
+	}
+	if err != nil {
+		log.Printf("Failed to find tag: %v", err)
+		return rest, nil, tagErrorText(err)
+	}
+	return rest, tag, ""
+}
+
+// tagsText перечисляет теги с числом слов
+func tagsText(tags []*repository.Tag) string {
+	var sb strings.Builder
+	sb.WriteString("🏷 Ваши теги:\n\n")
+	for _, tag := range tags {
+		sb.WriteString(fmt.Sprintf("#%s — %d сл.\n", tag.Name, tag.Words))
+	}
+	sb.WriteString("\nСписок слов, тест или повторение только по тегу — кнопками ниже.")
+	return sb.String()
+}
+
+// tagsKeyboard возвращает для каждого тега кнопки списка слов, теста и повторения
+func tagsKeyboard(tags []*repository.Tag) *models.InlineKeyboardMarkup {
+	keyboard := make([][]models.InlineKeyboardButton, 0, len(tags))
+	for _, tag := range tags {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
+			{
+				Text:         "📚 #" + truncateRunes(tag.Name, 20),
+				CallbackData: fmt.Sprintf("words_page_%s_0", repository.WordSortRecent) + tagSuffix(tag.ID),
+			},
+			{Text: "🧠 Тест", CallbackData: fmt.Sprintf("tags_quiz_%d", tag.ID)},
+			{Text: "🔄 Повторить", CallbackData: fmt.Sprintf("tags_review_%d", tag.ID)},
+		})
+	}
+	return &models.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// tagSuffix возвращает окончание callback данных с ID тега или пустую строку без тега
+func tagSuffix(tagID int) string {
+	if tagID == 0 {
+		return ""
+	}
+	return fmt.Sprintf("_%d", tagID)
+}
+
+// formatTags записывает теги через пробел с #
+func formatTags(tags []string) string {
+	return "#" + strings.Join(tags, " #")
+}
+
+// tagErrorText возвращает понятное пользователю описание ошибки работы с тегами
+func tagErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrInvalidTag):
+		return fmt.Sprintf("Неверный тег. Тег начинается с буквы, в нем можно использовать буквы, цифры, _ и -, "+
+			"длина — до %d символов.", service.MaxTagLength)
+	case errors.Is(err, service.ErrNoTags):
+		return tagsUsage
+	case errors.Is(err, service.ErrWordNotFound):
+		return "Слово не найдено. Используйте /words для просмотра списка."
+	case errors.Is(err, service.ErrTagNotFound):
+		return "Тег не найден. Список тегов: /tags"
+	default:
+		return "Не удалось изменить теги. Попробуйте позже."
+	}
+}
@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTagHandlers_TagAndUntag(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+
+	h.TagHandler(context.Background(), b, textUpdate("/tag 1 #Fruit #red"))
+	if text := api.LastText(t); !strings.Contains(text, "'apple': #fruit #red") {
+		t.Fatalf("Expected apple to get both tags, got %q", text)
+	}
+
+	h.UntagHandler(context.Background(), b, textUpdate("/untag 1 #red"))
+	if text := api.LastText(t); !strings.Contains(text, "'apple': #fruit") || strings.Contains(text, "#red") {
+		t.Errorf("Expected only fruit to remain, got %q", text)
+	}
+
+	h.TagHandler(context.Background(), b, textUpdate("/tag 1"))
+	if text := api.LastText(t); !strings.Contains(text, "/tag [номер] #тег") {
+		t.Errorf("Expected usage hint, got %q", text)
+	}
+	h.TagHandler(context.Background(), b, textUpdate("/tag 5 #fruit"))
+	if text := api.LastText(t); !strings.Contains(text, "Неверный номер") {
+		t.Errorf("Expected wrong number message, got %q", text)
+	}
+}
+
+func TestTagsHandler_ListsTagsWithButtons(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.TagsHandler(context.Background(), b, textUpdate("/tags"))
+	if text := api.LastText(t); !strings.Contains(text, "нет тегов") {
+		t.Errorf("Expected no tags message, got %q", text)
+	}
+
+	h.AddHandler(context.Background(), b, textUpdate("/add train - поезд #travel"))
+	travel, err := wordService.FindTag(testUserID, "travel")
+	if err != nil {
+		t.Fatalf("Expected the travel tag after /add, got %v", err)
+	}
+
+	h.TagsHandler(context.Background(), b, textUpdate("/tags"))
+	sends := api.Calls("sendMessage")
+	result := sends[len(sends)-1]
+	if !strings.Contains(result.Params["text"], "#travel — 1") {
+		t.Errorf("Expected travel with one word, got %q", result.Params["text"])
+	}
+	for _, data := range []string{
+		fmt.Sprintf("words_page_recent_0_%d", travel.ID),
+		fmt.Sprintf("tags_quiz_%d", travel.ID),
+		fmt.Sprintf("tags_review_%d", travel.ID),
+	} {
+		if !strings.Contains(result.Params["reply_markup"], data) {
+			t.Errorf("Expected button %q, got %s", data, result.Params["reply_markup"])
+		}
+	}
+}
+
+func TestWordsHandler_FiltersByTag(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "train")
+	h.TagHandler(context.Background(), b, textUpdate("/tag 1 #travel"))
+	travel, _ := wordService.FindTag(testUserID, "travel")
+
+	h.WordsHandler(context.Background(), b, textUpdate("/words #travel"))
+	sends := api.Calls("sendMessage")
+	result := sends[len(sends)-1]
+	if !strings.Contains(result.Params["text"], "#travel: 1–1 из 1") ||
+		!strings.Contains(result.Params["text"], "train - train-ru #travel") ||
+		strings.Contains(result.Params["text"], "apple") {
+		t.Fatalf("Expected only train in the travel list, got %q", result.Params["text"])
+	}
+	if !strings.Contains(result.Params["reply_markup"], fmt.Sprintf("words_page_alpha_0_%d", travel.ID)) {
+		t.Errorf("Expected sort buttons to keep the tag, got %s", result.Params["reply_markup"])
+	}
+
+	h.WordsCallbackHandler(context.Background(), b,
+		callbackUpdate(fmt.Sprintf("words_page_alpha_0_%d", travel.ID), result.Params["text"]))
+	if text := api.LastText(t); !strings.Contains(text, "train") || strings.Contains(text, "apple") {
+		t.Errorf("Expected the sorted page to stay filtered, got %q", text)
+	}
+
+	h.WordsHandler(context.Background(), b, textUpdate("/words #food"))
+	if text := api.LastText(t); !strings.Contains(text, "нет слов с тегом #food") {
+		t.Errorf("Expected unknown tag message, got %q", text)
+	}
+}
+
+func TestReviewHandler_ByTag(t *testing.T) {
+	h, wordService, db := newTestHandlersWithDB(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear")
+	makeDue(t, db, wordService)
+	h.TagHandler(context.Background(), b, textUpdate("/tag 1 #fruit"))
+
+	words, _ := wordService.GetUserWords(testUserID)
+	pear := words[0]
+	fruit, _ := wordService.FindTag(testUserID, "fruit")
+
+	h.ReviewHandler(context.Background(), b, textUpdate("/review #fruit"))
+	sends := api.Calls("sendMessage")
+	front := sends[len(sends)-1]
+	if !strings.Contains(front.Params["text"], "🃏 pear") {
+		t.Fatalf("Expected the tagged card, got %q", front.Params["text"])
+	}
+	show := fmt.Sprintf("review_show_%d_%d", pear.ID, fruit.ID)
+	if !strings.Contains(front.Params["reply_markup"], show) {
+		t.Fatalf("Expected show button %q, got %s", show, front.Params["reply_markup"])
+	}
+
+	grade := fmt.Sprintf("review_grade_%d_2_%d", pear.ID, fruit.ID)
+	h.ReviewCallbackHandler(context.Background(), b, callbackUpdate(grade, "🃏 pear"))
+	if text := api.LastText(t); !strings.Contains(text, "нет слов для повторения") {
+		t.Errorf("Expected the tagged review to end without apple, got %q", text)
+	}
+}
+
+func TestTagsCallbackHandler_StartsQuizByTag(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple", "pear", "plum", "train")
+	h.TagHandler(context.Background(), b, textUpdate("/tag 1 #travel"))
+	travel, _ := wordService.FindTag(testUserID, "travel")
+
+	h.TagsCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("tags_quiz_%d", travel.ID), "🏷"))
+
+	if text := api.LastText(t); !strings.Contains(text, "train") {
+		t.Errorf("Expected a question about train, got %q", text)
+	}
+
+	h.TagsCallbackHandler(context.Background(), b, callbackUpdate("tags_quiz_999", "🏷"))
+	answers := api.Calls("answerCallbackQuery")
+	if last := answers[len(answers)-1]; !strings.Contains(last.Params["text"], "Тег не найден") {
+		t.Errorf("Expected tag not found notice, got %q", last.Params["text"])
+	}
+}
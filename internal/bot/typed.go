@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// TypeHandler обрабатывает команду /type: показывает слово и ждет ответ текстом
+func (h *BotHandlers) TypeHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+
+	mode, err := h.userService.GetQuizMode(userID)
+	if err != nil {
+		log.Printf("Failed to get user quiz mode: %v", err)
+		mode = repository.DirectionForward
+	}
+
+	question, err := h.typedQuizService.Start(userID, mode)
+	if err != nil {
+		log.Printf("Failed to start typed quiz: %v", err)
+		sendText(ctx, b, update.Message.Chat.ID, "Не удалось задать вопрос. Сначала добавьте слова командой /add.")
+		return
+	}
+
+	text := fmt.Sprintf("✍️ Напишите перевод слова: %s", question.Prompt)
+	if question.Direction == repository.DirectionReverse {
+		text = fmt.Sprintf("✍️ Напишите по-английски: %s", question.Prompt)
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: update.Message.Chat.ID,
+		Text:   text,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "🤷 Не знаю", CallbackData: "type_giveup"}},
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// TypeCallbackHandler обрабатывает кнопку «Не знаю» под вопросом /type
+func (h *BotHandlers) TypeCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	if callback.Data != "type_giveup" {
+		return
+	}
+
+	scheduler, err := h.userService.GetScheduler(callback.From.ID)
+	if err != nil {
+		log.Printf("Failed to get user scheduler: %v", err)
+	}
+
+	result, err := h.typedQuizService.GiveUp(scheduler, callback.From.ID)
+	if err != nil {
+		if !errors.Is(err, service.ErrNoTypedQuestion) {
+			log.Printf("Failed to give up typed quiz: %v", err)
+		}
+		answerCallback(ctx, b, callback.ID, typedErrorText(err))
+		return
+	}
+
+	answerCallback(ctx, b, callback.ID, "")
+	if msg := callback.Message.Message; msg != nil {
+		sendText(ctx, b, msg.Chat.ID, typedResultText(result))
+	}
+}
+
+// handleTypedAnswer проверяет сообщение как ответ на вопрос /type
+func (h *BotHandlers) handleTypedAnswer(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+
+	scheduler, err := h.userService.GetScheduler(userID)
+	if err != nil {
+		log.Printf("Failed to get user scheduler: %v", err)
+	}
+
+	result, err := h.typedQuizService.Answer(scheduler, userID, update.Message.Text)
+	if err != nil {
+		log.Printf("Failed to check typed answer: %v", err)
+		sendText(ctx, b, update.Message.Chat.ID, typedErrorText(err))
+		return
+	}
+
+	sendText(ctx, b, update.Message.Chat.ID, typedResultText(result))
+}
+
+// typedResultText формирует ответ на введенный перевод
+func typedResultText(result *service.TypedResult) string {
+	var text strings.Builder
+	switch result.Grade {
+	case service.GradeCorrect:
+		text.WriteString("✅ Правильно!")
+		if alternatives := service.AnswerAlternatives(result.Expected); len(alternatives) > 1 {
+			text.WriteString(fmt.Sprintf("\nВсе варианты: %s", strings.Join(alternatives, ", ")))
+		}
+	case service.GradeAlmost:
+		text.WriteString(fmt.Sprintf("🟡 Почти! Правильно пишется: %s\n", result.Closest))
+		text.WriteString("Ответ засчитан, но слово повторится раньше.")
+	default:
+		text.WriteString(fmt.Sprintf("❌ Неправильно. Правильный ответ: %s", result.Expected))
+	}
+
+	text.WriteString(fmt.Sprintf("\n\n%s — %s", result.Word.Word, result.Word.Translation))
+	text.WriteString("\nСледующее слово: /type")
+	return text.String()
+}
+
+// typedErrorText возвращает сообщение пользователю для ошибки проверки ответа
+func typedErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrNoTypedQuestion):
+		return "Вопрос уже закрыт. Новое слово: /type"
+	case errors.Is(err, service.ErrQuizSessionExpired):
+		return "Время на ответ истекло. Новое слово: /type"
+	case errors.Is(err, service.ErrQuizSessionNotFound):
+		return "Слово было удалено. Новое слово: /type"
+	default:
+		return "Ошибка при проверке ответа."
+	}
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTypeHandler_GradesTypedAnswer(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+
+	h.TypeHandler(context.Background(), b, textUpdate("/type"))
+	if text := api.LastText(t); text != "✍️ Напишите перевод слова: apple" {
+		t.Fatalf("Expected question about apple, got %q", text)
+	}
+
+	// Сообщение без команды проверяется как ответ
+	h.DefaultHandler(context.Background(), b, textUpdate("Apple-RU!"))
+	if text := api.LastText(t); !strings.HasPrefix(text, "✅ Правильно!") {
+		t.Errorf("Expected correct answer, got %q", text)
+	}
+
+	// Следующий текст уже не ответ
+	h.DefaultHandler(context.Background(), b, textUpdate("apple-ru"))
+	if text := api.LastText(t); !strings.Contains(text, "не понимаю") {
+		t.Errorf("Expected unknown command message, got %q", text)
+	}
+}
+
+func TestTypeHandler_AlmostAndGiveUp(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+
+	h.TypeHandler(context.Background(), b, textUpdate("/type"))
+	h.DefaultHandler(context.Background(), b, textUpdate("aple-ru"))
+	if text := api.LastText(t); !strings.Contains(text, "Почти! Правильно пишется: apple-ru") {
+		t.Errorf("Expected almost correct feedback, got %q", text)
+	}
+
+	h.TypeHandler(context.Background(), b, textUpdate("/type"))
+	h.TypeCallbackHandler(context.Background(), b, callbackUpdate("type_giveup", "✍️ Напишите перевод слова: apple"))
+	if text := api.LastText(t); !strings.Contains(text, "Правильный ответ: apple-ru") {
+		t.Errorf("Expected the answer to be revealed, got %q", text)
+	}
+
+	// Повторное нажатие не засчитывает ответ еще раз
+	h.TypeCallbackHandler(context.Background(), b, callbackUpdate("type_giveup", "✍️ Напишите перевод слова: apple"))
+	calls := api.Calls("answerCallbackQuery")
+	if text := calls[len(calls)-1].Params["text"]; !strings.Contains(text, "Вопрос уже закрыт") {
+		t.Errorf("Expected closed question notice, got %q", text)
+	}
+	history, _ := wordService.GetAnswerHistory(testUserID, 10)
+	if len(history) != 2 {
+		t.Errorf("Expected 2 answers in the log, got %d", len(history))
+	}
+}
+
+func TestTypeHandler_NoWords(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	h.TypeHandler(context.Background(), b, textUpdate("/type"))
+
+	if text := api.LastText(t); !strings.Contains(text, "/add") {
+		t.Errorf("Expected hint to add words, got %q", text)
+	}
+}
@@ -0,0 +1,276 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// importReportLines — сколько строк с повторами и ошибками перечислять в отчете об импорте
+const importReportLines = 20
+
+// importExtensions — расширения файлов, которые бот принимает для импорта слов
+var importExtensions = map[string]bool{".csv": true, ".tsv": true, ".txt": true}
+
+// errImportFileSize возвращается, если скачанный файл больше service.MaxImportFileSize
+var errImportFileSize = errors.New("import file is too large")
+
+// importColumnNames — названия полей слова в предпросмотре импорта
+var importColumnNames = map[string]string{
+	service.ColumnWord:        "слово",
+	service.ColumnTranslation: "перевод",
+	service.ColumnContext:     "контекст",
+	service.ColumnTags:        "теги",
+}
+
+// DocumentHandler принимает CSV/TSV файл со словами и показывает предпросмотр импорта
+func (h *BotHandlers) DocumentHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID, userID := update.Message.Chat.ID, update.Message.From.ID
+	document := update.Message.Document
+
+	if !importExtensions[strings.ToLower(filepath.Ext(document.FileName))] {
+		sendText(ctx, b, chatID, "Я умею импортировать слова только из файлов .csv и .tsv. Подробнее: /help")
+		return
+	}
+	if document.FileSize > service.MaxImportFileSize {
+		sendText(ctx, b, chatID, importErrorText(errImportFileSize))
+		return
+	}
+
+	table, err := downloadWordTable(ctx, b, document.FileID)
+	if err != nil {
+		log.Printf("Failed to read import file: %v", err)
+		sendText(ctx, b, chatID, importErrorText(err))
+		return
+	}
+
+	deck, err := h.wordService.ActiveDeck(userID)
+	if err != nil {
+		log.Printf("Failed to get active deck: %v", err)
+		sendText(ctx, b, chatID, "Ошибка при чтении файла. Попробуйте позже.")
+		return
+	}
+	// Без колод слова попадут в основную колоду: она создается при первом добавлении
+	deckName := service.DefaultDeckName
+	if deck != nil {
+		deckName = deck.Name
+	}
+	if err := h.userService.UpdateUserStateData(userID, service.ImportState, document.FileID); err != nil {
+		log.Printf("Failed to save import state: %v", err)
+		sendText(ctx, b, chatID, "Ошибка при чтении файла. Попробуйте позже.")
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   importPreviewText(document.FileName, table, userID, deckName),
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{Text: "✅ Импортировать", CallbackData: "import_ok"},
+				{Text: "❌ Отмена", CallbackData: "import_cancel"},
+			},
+		}},
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// ImportCallbackHandler обрабатывает кнопки предпросмотра импорта: import_ok и import_cancel
+func (h *BotHandlers) ImportCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	if callback.Message.Message == nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+	msg := callback.Message.Message
+
+	user, err := h.userService.GetUser(callback.From.ID)
+	if err != nil {
+		log.Printf("Failed to get user state: %v", err)
+		answerCallback(ctx, b, callback.ID, "Не удалось импортировать слова. Попробуйте позже.")
+		return
+	}
+	// Состояние сбрасывается после импорта и при переходе к другой команде
+	if user == nil || user.State != service.ImportState || user.StateData == "" {
+		answerCallback(ctx, b, callback.ID, "Этот импорт уже завершен или отменен")
+		editText(ctx, b, msg.Chat.ID, msg.ID,
+			"Импорт уже завершен или отменен. Чтобы импортировать слова, отправьте файл еще раз.")
+		return
+	}
+
+	if callback.Data == "import_cancel" {
+		if err := h.userService.UpdateUserState(user.ID, service.StateIdle); err != nil {
+			log.Printf("Failed to reset import state: %v", err)
+		}
+		answerCallback(ctx, b, callback.ID, "Импорт отменен")
+		editText(ctx, b, msg.Chat.ID, msg.ID, "❌ Импорт отменен.")
+		return
+	}
+
+	// Файл скачивается заново: в состоянии хранится только его file_id
+	table, err := downloadWordTable(ctx, b, user.StateData)
+	if err != nil {
+		log.Printf("Failed to read import file: %v", err)
+		answerCallback(ctx, b, callback.ID, importErrorText(err))
+		return
+	}
+	results, err := h.wordService.ImportWordTable(user.ID, table)
+	if err != nil {
+		log.Printf("Failed to import words: %v", err)
+		answerCallback(ctx, b, callback.ID, "Ошибка при импорте. Ни одно слово не добавлено, попробуйте еще раз.")
+		return
+	}
+	if err := h.userService.UpdateUserState(user.ID, service.StateIdle); err != nil {
+		log.Printf("Failed to reset import state: %v", err)
+	}
+
+	answerCallback(ctx, b, callback.ID, "Импорт завершен")
+	editText(ctx, b, msg.Chat.ID, msg.ID, importReport(results))
+}
+
+// downloadWordTable скачивает файл из Telegram и разбирает его как таблицу слов
+func downloadWordTable(ctx context.Context, b *bot.Bot, fileID string) (*service.WordTable, error) {
+	file, err := b.GetFile(ctx, &bot.GetFileParams{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.FileDownloadLink(file), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, service.MaxImportFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if len(data) > service.MaxImportFileSize {
+		return nil, errImportFileSize
+	}
+
+	return service.ParseWordTable(data)
+}
+
+// importPreviewText описывает разобранный файл: разделитель, столбцы и первые строки
+func importPreviewText(fileName string, table *service.WordTable, userID int64, deckName string) string {
+	delimiter := fmt.Sprintf("«%c»", table.Delimiter)
+	if table.Delimiter == '\t' {
+		delimiter = "табуляция"
+	}
+	header := "нет, столбцы по порядку"
+	if table.Header {
+		header = "есть"
+	}
+
+	var columns []string
+	for i, column := range table.Columns {
+		name, ok := importColumnNames[column]
+		if !ok {
+			name = "пропускается"
+		}
+		columns = append(columns, fmt.Sprintf("%d — %s", i+1, name))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📄 %s\n\nСтрок со словами: %d\nРазделитель: %s\nЗаголовок: %s\nСтолбцы: %s\n\n",
+		fileName, len(table.Rows), delimiter, header, strings.Join(columns, ", ")))
+
+	sb.WriteString("Первые строки:\n")
+	for i, row := range table.Rows {
+		if i == service.ImportPreviewRows {
+			break
+		}
+		result := table.RowResult(userID, row)
+		if result.Status == service.LineInvalid {
+			sb.WriteString(fmt.Sprintf("⚠️ %d. %s\n", row.Line, importRowErrorText(result.Err)))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s — %s", row.Line, result.Word.Word, result.Word.Translation))
+		if result.Word.Context != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", result.Word.Context))
+		}
+		if len(result.Word.Tags) > 0 {
+			sb.WriteString(" " + formatTags(result.Word.Tags))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\nСлова попадут в колоду «%s», а слова, которые уже есть в словаре, "+
+		"не повторятся. Импортировать?", deckName))
+	return sb.String()
+}
+
+// importReport формирует отчет об импорте: сколько слов добавлено и какие строки пропущены
+func importReport(results []service.LineResult) string {
+	counts := make(map[string]int)
+	var lines []string
+	for _, result := range results {
+		counts[result.Status]++
+		switch result.Status {
+		case service.LineDuplicate:
+			lines = append(lines, fmt.Sprintf("♻️ %d. %s — уже есть в словаре", result.Line, result.Word.Word))
+		case service.LineInvalid:
+			lines = append(lines, fmt.Sprintf("⚠️ %d. %s", result.Line, importRowErrorText(result.Err)))
+		}
+	}
+
+	text := fmt.Sprintf("📥 Импорт завершен. Добавлено: %d, уже было: %d, ошибок: %d", counts[service.LineAdded],
+		counts[service.LineDuplicate], counts[service.LineInvalid])
+	if len(lines) > importReportLines {
+		more := len(lines) - importReportLines
+		lines = append(lines[:importReportLines], fmt.Sprintf("…и еще %d", more))
+	}
+	if len(lines) > 0 {
+		text += "\n\n" + strings.Join(lines, "\n")
+	}
+	return text + "\n\nСловарь: /words"
+}
+
+// importRowErrorText объясняет, почему строка файла не импортирована
+func importRowErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrAddFieldRequired):
+		return "Нет слова или перевода"
+	case errors.Is(err, service.ErrInvalidTag):
+		return "Неверный тег: тег начинается с буквы, в нем можно использовать буквы, цифры, _ и -"
+	default:
+		return "Строка не разобрана"
+	}
+}
+
+// importErrorText возвращает понятное пользователю описание ошибки чтения файла
+func importErrorText(err error) string {
+	switch {
+	case errors.Is(err, errImportFileSize):
+		return fmt.Sprintf("Файл слишком большой: можно импортировать файл до %d КБ.", service.MaxImportFileSize/1024)
+	case errors.Is(err, service.ErrTooManyRows):
+		return fmt.Sprintf("В файле слишком много строк: за раз можно импортировать до %d слов.", service.MaxImportRows)
+	case errors.Is(err, service.ErrEmptyTable):
+		return "В файле нет строк со словами."
+	case errors.Is(err, service.ErrTableColumns):
+		return "Не нашел столбцы со словом и переводом. Назовите их в заголовке word и translation " +
+			"или поставьте слово и перевод первыми столбцами."
+	case errors.Is(err, service.ErrTableCharset):
+		return "Файл не в кодировке UTF-8. Сохраните его как «CSV UTF-8» и отправьте еще раз."
+	default:
+		return "Не удалось прочитать файл. Попробуйте еще раз."
+	}
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func documentUpdate(fileID, fileName string, size int64) *models.Update {
+	update := textUpdate("")
+	update.Message.Document = &models.Document{FileID: fileID, FileName: fileName, FileSize: size}
+	return update
+}
+
+func TestDocumentHandler_ImportsTable(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "apple")
+
+	api.AddFile("file-1", "word;translation;context;tags\n"+
+		"Apple;яблоко;;\n"+
+		"pear;груша;a ripe pear;food\n"+
+		"plum;;;\n"+
+		"train;поезд;;\n")
+	h.DocumentHandler(context.Background(), b, documentUpdate("file-1", "words.csv", 80))
+
+	preview := api.Calls("sendMessage")[0]
+	for _, want := range []string{"📄 words.csv", "Строк со словами: 4", "Разделитель: «;»", "Заголовок: есть",
+		"1 — слово, 2 — перевод, 3 — контекст, 4 — теги", "3. pear — груша (a ripe pear) #food",
+		"⚠️ 4. Нет слова или перевода", "колоду «Основная»"} {
+		if !strings.Contains(preview.Params["text"], want) {
+			t.Errorf("Expected the preview to contain %q, got %q", want, preview.Params["text"])
+		}
+	}
+	if !strings.Contains(preview.Params["reply_markup"], "import_ok") {
+		t.Fatalf("Expected an import button, got %q", preview.Params["reply_markup"])
+	}
+	if words, _ := wordService.GetUserWords(testUserID); len(words) != 1 {
+		t.Fatalf("Expected nothing to be imported before confirmation, got %v", words)
+	}
+
+	h.ImportCallbackHandler(context.Background(), b, callbackUpdate("import_ok", preview.Params["text"]))
+	text := api.LastText(t)
+	for _, want := range []string{"Добавлено: 2, уже было: 1, ошибок: 1", "♻️ 2. Apple — уже есть в словаре",
+		"⚠️ 4. Нет слова или перевода"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected the report to contain %q, got %q", want, text)
+		}
+	}
+	if words, _ := wordService.GetDeckWords(testUserID); len(words) != 3 {
+		t.Errorf("Expected pear and train to be added, got %v", words)
+	}
+
+	// Повторное нажатие не импортирует файл еще раз
+	h.ImportCallbackHandler(context.Background(), b, callbackUpdate("import_ok", text))
+	if text := api.LastText(t); !strings.Contains(text, "уже завершен или отменен") {
+		t.Errorf("Expected the finished import notice, got %q", text)
+	}
+}
+
+func TestDocumentHandler_CancelsImport(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+
+	api.AddFile("file-1", "apple\tяблоко\n")
+	h.DocumentHandler(context.Background(), b, documentUpdate("file-1", "words.tsv", 14))
+	if text := api.LastText(t); !strings.Contains(text, "Разделитель: табуляция") ||
+		!strings.Contains(text, "Заголовок: нет") {
+		t.Fatalf("Expected a tab separated preview, got %q", text)
+	}
+
+	h.ImportCallbackHandler(context.Background(), b, callbackUpdate("import_cancel", "📄"))
+	if text := api.LastText(t); !strings.Contains(text, "Импорт отменен") {
+		t.Errorf("Expected the cancel notice, got %q", text)
+	}
+	if user, _ := h.userService.GetUser(testUserID); user.State != "idle" {
+		t.Errorf("Expected the idle state, got %q", user.State)
+	}
+	if words, _ := wordService.GetUserWords(testUserID); len(words) != 0 {
+		t.Errorf("Expected no words after cancel, got %v", words)
+	}
+}
+
+func TestDocumentHandler_RejectsFiles(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	b, api := newTestBot(t)
+	api.AddFile("file-1", "context\nan apple a day\n")
+
+	tests := []struct {
+		update *models.Update
+		want   string
+	}{
+		{documentUpdate("file-1", "words.xlsx", 10), "только из файлов .csv и .tsv"},
+		{documentUpdate("file-1", "words.csv", 5<<20), "Файл слишком большой"},
+		{documentUpdate("file-1", "words.csv", 22), "Не нашел столбцы со словом и переводом"},
+		{documentUpdate("missing", "words.csv", 22), "Не удалось прочитать файл"},
+	}
+
+	for _, tt := range tests {
+		h.DocumentHandler(context.Background(), b, tt.update)
+		if text := api.LastText(t); !strings.Contains(text, tt.want) {
+			t.Errorf("%s: expected %q, got %q", tt.update.Message.Document.FileName, tt.want, text)
+		}
+	}
+	if len(api.Calls("getFile")) != 2 {
+		t.Errorf("Expected only valid files to be downloaded, got %d getFile calls", len(api.Calls("getFile")))
+	}
+}
@@ -0,0 +1,270 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// wordSortNames — названия порядков списка /words на кнопках
+var wordSortNames = map[string]string{
+	repository.WordSortRecent:  "Новые",
+	repository.WordSortAlpha:   "А–Я",
+	repository.WordSortHardest: "Трудные",
+	repository.WordSortDue:     "К повторению",
+}
+
+// Ограничения длины полей слова в списке. Строка слова занимает не больше
+// ~370 символов, поэтому страница из service.WordsPageSize слов вместе с
+// заголовком помещается в одно сообщение Telegram (до 4096 символов).
+const (
+	wordsItemLimit = 100 // Слово, перевод и контекст
+	wordsTagsLimit = 60  // Все теги слова вместе
+)
+
+// WordsHandler обрабатывает команду /words [#тег]: показывает первую страницу списка
+func (h *BotHandlers) WordsHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+	log.Printf("Received /words command from user %d", userID)
+
+	_, tag, errText := h.tagFilter(userID, strings.TrimPrefix(update.Message.Text, "/words"))
+	if errText != "" {
+		sendText(ctx, b, update.Message.Chat.ID, errText)
+		return
+	}
+	tagID := 0
+	if tag != nil {
+		tagID = tag.ID
+	}
+
+	page, err := h.wordService.ListWords(userID, repository.WordSortRecent, 0, service.WordsPageSize, tagID)
+	if err != nil {
+		log.Printf("Failed to get user words: %v", err)
+		sendText(ctx, b, update.Message.Chat.ID, "Ошибка при получении слов.")
+		return
+	}
+
+	if page.Total == 0 {
+		sendText(ctx, b, update.Message.Chat.ID, "У вас пока нет сохраненных слов. Добавьте их командой /add!")
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      update.Message.Chat.ID,
+		Text:        wordsPageText(page),
+		ReplyMarkup: wordsKeyboard(page),
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// WordsCallbackHandler обрабатывает кнопки списка слов. Страница задается
+// порядком и первым словом: words_page_<порядок>_<слово> показывает страницу,
+// words_del_… и words_reset_… с ID слова после первого слова страницы удаляют
+// слово или сбрасывают его прогресс и показывают ту же страницу,
+// words_edit_<слово> открывает /edit. Если список отфильтрован по тегу,
+// его ID передается последним: _<тег>.
+func (h *BotHandlers) WordsCallbackHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	callback := update.CallbackQuery
+	userID := callback.From.ID
+
+	msg := callback.Message.Message
+	if msg == nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	parts := strings.Split(callback.Data, "_")
+	if len(parts) == 3 && parts[1] == "edit" {
+		h.showEditCard(ctx, b, callback, msg.Chat.ID, parts[2])
+		return
+	}
+	if len(parts) < 4 {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	sort := parts[2]
+	fromID, err := strconv.Atoi(parts[3])
+	if err != nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	notice := ""
+	tagID := 0
+	switch {
+	case parts[1] == "page" && (len(parts) == 4 || len(parts) == 5):
+		tagID = optionalTagID(parts, 4)
+	case parts[1] == "del" && (len(parts) == 5 || len(parts) == 6):
+		tagID = optionalTagID(parts, 5)
+		wordID, _ := strconv.Atoi(parts[4])
+		if err := h.wordService.DeleteWord(wordID, userID); err != nil {
+			log.Printf("Failed to delete word: %v", err)
+			answerCallback(ctx, b, callback.ID, "Слово уже удалено.")
+			return
+		}
+		notice = "Слово удалено"
+	case parts[1] == "reset" && (len(parts) == 5 || len(parts) == 6):
+		tagID = optionalTagID(parts, 5)
+		wordID, _ := strconv.Atoi(parts[4])
+		if _, err := h.wordService.ResetProgress(userID, wordID); err != nil {
+			answerCallback(ctx, b, callback.ID, editErrorText(err))
+			return
+		}
+		notice = "Прогресс сброшен"
+	default:
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	page, err := h.wordService.ListWords(userID, sort, fromID, service.WordsPageSize, tagID)
+	if err != nil {
+		log.Printf("Failed to list words: %v", err)
+		answerCallback(ctx, b, callback.ID, "Ошибка при получении слов.")
+		return
+	}
+	answerCallback(ctx, b, callback.ID, notice)
+
+	if page.Total == 0 {
+		text := "В словаре больше нет слов. Добавьте их командой /add!"
+		if page.Tag != nil {
+			text = fmt.Sprintf("Слов с тегом #%s больше нет. Все слова: /words", page.Tag.Name)
+		}
+		editText(ctx, b, msg.Chat.ID, msg.ID, text)
+		return
+	}
+	_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        wordsPageText(page),
+		ReplyMarkup: wordsKeyboard(page),
+	})
+	if err != nil {
+		log.Printf("Failed to edit message: %v", err)
+	}
+}
+
+// showEditCard отправляет карточку /edit для слова из списка
+func (h *BotHandlers) showEditCard(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery,
+	chatID int64, id string) {
+	wordID, err := strconv.Atoi(id)
+	if err != nil {
+		answerCallback(ctx, b, callback.ID, "")
+		return
+	}
+
+	word, err := h.wordService.GetWord(callback.From.ID, wordID)
+	if err != nil {
+		log.Printf("Failed to get word: %v", err)
+	}
+	if word == nil {
+		answerCallback(ctx, b, callback.ID, editErrorText(service.ErrWordNotFound))
+		return
+	}
+
+	answerCallback(ctx, b, callback.ID, "")
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        editCardText(word, ""),
+		ReplyMarkup: editKeyboard(word),
+	})
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+	}
+}
+
+// wordsPageText формирует текст страницы списка. Номера показываются только
+// для недавних слов без фильтра: они совпадают с номерами для /edit и /delete.
+func wordsPageText(page *service.WordPage) string {
+	var sb strings.Builder
+	title := "Ваши слова"
+	switch {
+	case page.Tag != nil:
+		title = "Слова с тегом #" + page.Tag.Name
+	case page.Deck != nil:
+		title = fmt.Sprintf("Колода «%s»", page.Deck.Name)
+	}
+	sb.WriteString(fmt.Sprintf("📚 %s: %d–%d из %d (%s)\n\n",
+		title, page.Offset+1, page.Offset+len(page.Words), page.Total, wordSortNames[page.Sort]))
+
+	for i, word := range page.Words {
+		if page.Sort == repository.WordSortRecent && page.Tag == nil {
+			sb.WriteString(fmt.Sprintf("%d. ", page.Offset+i+1))
+		} else {
+			sb.WriteString("• ")
+		}
+		sb.WriteString(fmt.Sprintf("%s - %s", truncateRunes(word.Word, wordsItemLimit),
+			truncateRunes(word.Translation, wordsItemLimit)))
+		if word.Context != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", truncateRunes(word.Context, wordsItemLimit)))
+		}
+		if len(word.Tags) > 0 {
+			sb.WriteString(" " + truncateRunes(formatTags(word.Tags), wordsTagsLimit))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// wordsKeyboard возвращает кнопки слов страницы, переход между страницами и выбор порядка
+func wordsKeyboard(page *service.WordPage) *models.InlineKeyboardMarkup {
+	var keyboard [][]models.InlineKeyboardButton
+
+	tag := ""
+	if page.Tag != nil {
+		tag = tagSuffix(page.Tag.ID)
+	}
+
+	for _, word := range page.Words {
+		suffix := fmt.Sprintf("%s_%d_%d", page.Sort, page.FromID, word.ID) + tag
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
+			{Text: "✏️ " + truncateRunes(word.Word, 20), CallbackData: fmt.Sprintf("words_edit_%d", word.ID)},
+			{Text: "🗑", CallbackData: "words_del_" + suffix},
+			{Text: "🔄", CallbackData: "words_reset_" + suffix},
+		})
+	}
+
+	var nav []models.InlineKeyboardButton
+	if page.HasPrev {
+		nav = append(nav, models.InlineKeyboardButton{
+			Text: "⬅️ Назад", CallbackData: fmt.Sprintf("words_page_%s_%d", page.Sort, page.PrevID) + tag,
+		})
+	}
+	if page.HasNext {
+		nav = append(nav, models.InlineKeyboardButton{
+			Text: "Вперед ➡️", CallbackData: fmt.Sprintf("words_page_%s_%d", page.Sort, page.NextID) + tag,
+		})
+	}
+	if len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+
+	var sorts []models.InlineKeyboardButton
+	for _, sort := range service.WordSorts {
+		text := wordSortNames[sort]
+		if sort == page.Sort {
+			text = "✓ " + text
+		}
+		sorts = append(sorts, models.InlineKeyboardButton{
+			Text: text, CallbackData: fmt.Sprintf("words_page_%s_0", sort) + tag,
+		})
+	}
+	keyboard = append(keyboard, sorts)
+
+	if page.Tag != nil {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{
+			{Text: "✖️ Все слова", CallbackData: fmt.Sprintf("words_page_%s_0", page.Sort)},
+		})
+	}
+
+	return &models.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
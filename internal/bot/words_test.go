@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/service"
+)
+
+func TestWordsHandler_Paginates(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	for i := 1; i <= 12; i++ {
+		addWords(t, wordService, fmt.Sprintf("word%02d", i))
+	}
+
+	h.WordsHandler(context.Background(), b, textUpdate("/words"))
+	sends := api.Calls("sendMessage")
+	first := sends[len(sends)-1]
+	if !strings.Contains(first.Params["text"], "1–10 из 12") || !strings.Contains(first.Params["text"], "1. word12") {
+		t.Fatalf("Expected the first page, got %q", first.Params["text"])
+	}
+	if strings.Contains(first.Params["text"], "word02") {
+		t.Errorf("Expected older words on the next page, got %q", first.Params["text"])
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	next := fmt.Sprintf("words_page_recent_%d", words[10].ID)
+	if !strings.Contains(first.Params["reply_markup"], next) {
+		t.Fatalf("Expected next button %q, got %s", next, first.Params["reply_markup"])
+	}
+
+	h.WordsCallbackHandler(context.Background(), b, callbackUpdate(next, first.Params["text"]))
+	text := api.LastText(t)
+	if !strings.Contains(text, "11–12 из 12") || !strings.Contains(text, "11. word02") ||
+		!strings.Contains(text, "12. word01") {
+		t.Errorf("Expected the second page in the same message, got %q", text)
+	}
+	if len(api.Calls("editMessageText")) != 1 {
+		t.Errorf("Expected the list message to be edited")
+	}
+}
+
+func TestWordsCallbackHandler_SortAndDelete(t *testing.T) {
+	h, wordService := newTestHandlers(t)
+	b, api := newTestBot(t)
+	addWords(t, wordService, "pear", "apple", "plum")
+
+	h.WordsCallbackHandler(context.Background(), b, callbackUpdate("words_page_alpha_0", "📚"))
+	text := api.LastText(t)
+	if !strings.Contains(text, "А–Я") || strings.Index(text, "apple") > strings.Index(text, "pear") {
+		t.Fatalf("Expected alphabetical order, got %q", text)
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	pear := words[2]
+	h.WordsCallbackHandler(context.Background(), b, callbackUpdate(fmt.Sprintf("words_del_alpha_0_%d", pear.ID), text))
+	if text := api.LastText(t); strings.Contains(text, "pear") || !strings.Contains(text, "из 2") {
+		t.Errorf("Expected pear to disappear from the page, got %q", text)
+	}
+	if word, _ := wordService.GetWord(testUserID, pear.ID); word != nil {
+		t.Errorf("Expected pear to be deleted, got %+v", word)
+	}
+}
+
+func TestWordsPageText_FitsTelegramLimit(t *testing.T) {
+	var tags []string
+	for i := 0; i < 10; i++ {
+		tags = append(tags, string(rune('a'+i))+strings.Repeat("т", service.MaxTagLength-1))
+	}
+
+	page := &service.WordPage{
+		Sort:   repository.WordSortRecent,
+		Deck:   &repository.Deck{Name: strings.Repeat("К", service.MaxDeckNameLength)},
+		Offset: 9990,
+		Total:  10000,
+	}
+	for i := 0; i < service.WordsPageSize; i++ {
+		page.Words = append(page.Words, &repository.Word{
+			ID:          i + 1,
+			Word:        strings.Repeat("w", 255),
+			Translation: strings.Repeat("п", 255),
+			Context:     strings.Repeat("к", 1000),
+			Tags:        tags,
+		})
+	}
+
+	text := wordsPageText(page)
+	// Telegram считает длину сообщения в кодовых единицах UTF-16
+	if n := len(utf16.Encode([]rune(text))); n > 4096 {
+		t.Errorf("Expected a page of maximum-length words to fit into 4096 characters, got %d", n)
+	}
+	if !strings.Contains(text, "…") {
+		t.Error("Expected long fields to be truncated")
+	}
+}
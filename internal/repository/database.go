@@ -4,79 +4,188 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"regexp"
+	"time"
 
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
+// Поддерживаемые драйверы базы данных
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// placeholderRe находит плейсхолдеры PostgreSQL вида $1
+var placeholderRe = regexp.MustCompile(`\$(\d+)`)
+
 // Database представляет собой структуру для работы с базой данных
 type Database struct {
-	db *sql.DB
+	db     *sql.DB
+	driver string
+}
+
+// DatabaseOption настраивает открытие базы данных
+type DatabaseOption func(*databaseOptions)
+
+// databaseOptions — параметры открытия базы данных
+type databaseOptions struct {
+	skipMigrate bool
+}
+
+// WithoutMigrate открывает базу без применения миграций: например, чтобы
+// откатить миграции, не накатывая перед этим еще не примененные
+func WithoutMigrate() DatabaseOption {
+	return func(o *databaseOptions) {
+		o.skipMigrate = true
+	}
 }
 
 // NewDatabase создает новое подключение к базе данных
-func NewDatabase(host, port, user, password, dbname string) (*Database, error) {
+func NewDatabase(host, port, user, password, dbname string, opts ...DatabaseOption) (*Database, error) {
 	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		host, port, user, password, dbname)
 
-	db, err := sql.Open("postgres", psqlInfo)
+	return openDatabase(DriverPostgres, psqlInfo, opts)
+}
+
+// NewSQLiteDatabase открывает встроенную базу SQLite в указанном файле
+func NewSQLiteDatabase(path string, opts ...DatabaseOption) (*Database, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite", path)
+
+	return openDatabase(DriverSQLite, dsn, opts)
+}
+
+// openDatabase подключается к базе и, если не передан WithoutMigrate,
+// приводит схему к актуальной версии
+func openDatabase(driver, dsn string, opts []DatabaseOption) (*Database, error) {
+	var options databaseOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	db, err := sql.Open(driver, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	if driver == DriverSQLite {
+		// SQLite допускает только одного писателя одновременно
+		db.SetMaxOpenConns(1)
+	}
+
 	if err = db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	database := &Database{db: db}
+	database := &Database{db: db, driver: driver}
 
-	// Создаем таблицы при инициализации
-	if err := database.createTables(); err != nil {
-		return nil, fmt.Errorf("failed to create tables: %w", err)
+	// Приводим схему к актуальной версии
+	if !options.skipMigrate {
+		if err := database.Migrate(); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		if err := database.fillWordNorm(); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
 	}
 
-	log.Println("Successfully connected to database")
+	log.Printf("Successfully connected to %s database", driver)
 	return database, nil
 }
 
-// createTables создает необходимые таблицы
-func (d *Database) createTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS users (
-			id BIGINT PRIMARY KEY,
-			username VARCHAR(255),
-			first_name VARCHAR(255),
-			last_name VARCHAR(255),
-			state VARCHAR(50) DEFAULT 'idle',
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS words (
-			id SERIAL PRIMARY KEY,
-			user_id BIGINT REFERENCES users(id),
-			word VARCHAR(255) NOT NULL,
-			translation VARCHAR(255) NOT NULL,
-			context TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			last_review TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			next_review TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			interval INTEGER DEFAULT 1,
-			difficulty INTEGER DEFAULT 0
-		)`,
-		`CREATE TABLE IF NOT EXISTS quizzes (
-			id SERIAL PRIMARY KEY,
-			user_id BIGINT REFERENCES users(id),
-			word_id INTEGER REFERENCES words(id),
-			correct BOOLEAN NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
+// Driver возвращает имя используемого драйвера
+func (d *Database) Driver() string {
+	return d.driver
+}
+
+// Exec выполняет запрос, написанный в диалекте PostgreSQL
+func (d *Database) Exec(query string, args ...any) (sql.Result, error) {
+	return d.db.Exec(d.rebind(query), d.convertArgs(args)...)
+}
+
+// Query выполняет запрос, возвращающий строки
+func (d *Database) Query(query string, args ...any) (*sql.Rows, error) {
+	return d.db.Query(d.rebind(query), d.convertArgs(args)...)
+}
+
+// QueryRow выполняет запрос, возвращающий не более одной строки
+func (d *Database) QueryRow(query string, args ...any) *sql.Row {
+	return d.db.QueryRow(d.rebind(query), d.convertArgs(args)...)
+}
+
+// querier — общие методы Database и Tx, чтобы вспомогательные функции
+// репозиториев работали как вне транзакции, так и внутри нее
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Tx — транзакция, переводящая запросы в диалект базы так же, как Database
+type Tx struct {
+	tx *sql.Tx
+	d  *Database
+}
+
+// Begin начинает транзакцию
+func (d *Database) Begin() (*Tx, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, err
 	}
+	return &Tx{tx: tx, d: d}, nil
+}
 
-	for _, query := range queries {
-		if _, err := d.db.Exec(query); err != nil {
-			return fmt.Errorf("failed to execute query: %s, error: %w", query, err)
-		}
+// Exec выполняет запрос внутри транзакции
+func (t *Tx) Exec(query string, args ...any) (sql.Result, error) {
+	return t.tx.Exec(t.d.rebind(query), t.d.convertArgs(args)...)
+}
+
+// Query выполняет запрос, возвращающий строки, внутри транзакции
+func (t *Tx) Query(query string, args ...any) (*sql.Rows, error) {
+	return t.tx.Query(t.d.rebind(query), t.d.convertArgs(args)...)
+}
+
+// QueryRow выполняет запрос, возвращающий не более одной строки, внутри транзакции
+func (t *Tx) QueryRow(query string, args ...any) *sql.Row {
+	return t.tx.QueryRow(t.d.rebind(query), t.d.convertArgs(args)...)
+}
+
+// Commit фиксирует транзакцию
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback откатывает транзакцию
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// rebind переводит плейсхолдеры $n в нумерованные ?n для SQLite
+func (d *Database) rebind(query string) string {
+	if d.driver != DriverSQLite {
+		return query
+	}
+	return placeholderRe.ReplaceAllString(query, "?$1")
+}
+
+// convertArgs приводит время к UTC для SQLite: даты там хранятся строками,
+// и сравнение next_review <= $n корректно только в одном часовом поясе
+func (d *Database) convertArgs(args []any) []any {
+	if d.driver != DriverSQLite {
+		return args
 	}
 
-	return nil
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			arg = t.UTC()
+		}
+		converted[i] = arg
+	}
+	return converted
 }
 
 // Close закрывает соединение с базой данных
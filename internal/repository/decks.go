@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// deckColumns перечисляет столбцы, которые читает scanDecks; последним идет число слов колоды
+const deckColumns = `d.id, d.user_id, d.name, d.description, d.source_lang, d.target_lang, d.new_per_day,
+	d.scheduler, d.active, d.share_code, d.imports, d.created_at, (SELECT COUNT(*) FROM words w WHERE w.deck_id = d.id)`
+
+// CreateDeck сохраняет новую колоду и заполняет ее ID и дату создания
+func (r *WordRepository) CreateDeck(deck *Deck) error {
+	return insertDeck(r.db, deck)
+}
+
+// insertDeck добавляет колоду и заполняет ее ID и дату создания
+func insertDeck(q querier, deck *Deck) error {
+	err := q.QueryRow(`
+		INSERT INTO decks (user_id, name, description, source_lang, target_lang, new_per_day, scheduler, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, deck.UserID, deck.Name, deck.Description, deck.SourceLang, deck.TargetLang, deck.NewPerDay,
+		deck.Scheduler, deck.Active).Scan(&deck.ID, &deck.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create deck: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeck получает колоду по ID или nil, если ее нет
+func (r *WordRepository) GetDeck(deckID int) (*Deck, error) {
+	rows, err := r.db.Query(`SELECT `+deckColumns+` FROM decks d WHERE d.id = $1`, deckID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deck: %w", err)
+	}
+	defer rows.Close()
+
+	decks, err := scanDecks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(decks) == 0 {
+		return nil, nil // Колода не найдена
+	}
+
+	return decks[0], nil
+}
+
+// GetUserDecks возвращает колоды пользователя в порядке создания
+func (r *WordRepository) GetUserDecks(userID int64) ([]*Deck, error) {
+	rows, err := r.db.Query(`SELECT `+deckColumns+` FROM decks d WHERE d.user_id = $1 ORDER BY d.created_at, d.id`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user decks: %w", err)
+	}
+	defer rows.Close()
+
+	return scanDecks(rows)
+}
+
+// UpdateDeck сохраняет название, описание, языки и настройки колоды пользователя
+func (r *WordRepository) UpdateDeck(deck *Deck) error {
+	return updateDeck(r.db, deck)
+}
+
+// UpdateDeckScheduler в одной транзакции сохраняет колоду и состояния ее слов,
+// переведенные в параметры нового алгоритма: states — в прямом направлении,
+// reverse — в обратном
+func (r *WordRepository) UpdateDeckScheduler(deck *Deck, states, reverse map[int]ReviewState) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateDeck(tx, deck); err != nil {
+		return err
+	}
+	for wordID, state := range states {
+		if err := saveReviewState(tx, wordID, state); err != nil {
+			return err
+		}
+	}
+	for wordID, state := range reverse {
+		if err := saveReverseReviewState(tx, wordID, state); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit deck scheduler: %w", err)
+	}
+
+	return nil
+}
+
+// updateDeck обновляет строку колоды
+func updateDeck(q querier, deck *Deck) error {
+	result, err := q.Exec(`
+		UPDATE decks SET name = $1, description = $2, source_lang = $3, target_lang = $4,
+			new_per_day = $5, scheduler = $6
+		WHERE id = $7 AND user_id = $8
+	`, deck.Name, deck.Description, deck.SourceLang, deck.TargetLang, deck.NewPerDay, deck.Scheduler,
+		deck.ID, deck.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update deck: %w", err)
+	}
+
+	return checkDeckAffected(result)
+}
+
+// SetActiveDeck делает колоду пользователя активной, а остальные его колоды — неактивными
+func (r *WordRepository) SetActiveDeck(userID int64, deckID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Сначала снимаем отметку, иначе уникальный индекс decks_active_idx не даст поставить новую
+	if _, err := tx.Exec(`UPDATE decks SET active = FALSE WHERE user_id = $1 AND id <> $2`, userID, deckID); err != nil {
+		return fmt.Errorf("failed to deactivate decks: %w", err)
+	}
+	result, err := tx.Exec(`UPDATE decks SET active = TRUE WHERE id = $1 AND user_id = $2`, deckID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to activate deck: %w", err)
+	}
+	if err := checkDeckAffected(result); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit active deck: %w", err)
+	}
+
+	return nil
+}
+
+// MoveWords в одной транзакции переносит слова пользователя в его колоду deckID
+func (r *WordRepository) MoveWords(userID int64, wordIDs []int, deckID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var owner int64
+	err = tx.QueryRow(`SELECT user_id FROM decks WHERE id = $1`, deckID).Scan(&owner)
+	if err == sql.ErrNoRows || (err == nil && owner != userID) {
+		return fmt.Errorf("deck not found or not owned by user")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get deck: %w", err)
+	}
+
+	for _, wordID := range wordIDs {
+		result, err := tx.Exec(`UPDATE words SET deck_id = $1 WHERE id = $2 AND user_id = $3`, deckID, wordID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to move word: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("word %d not found or not owned by user", wordID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit moved words: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDeck в одной транзакции удаляет колоду пользователя вместе с ее словами
+func (r *WordRepository) DeleteDeck(userID int64, deckID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM words WHERE deck_id = $1 AND user_id = $2`, deckID, userID); err != nil {
+		return fmt.Errorf("failed to delete deck words: %w", err)
+	}
+	result, err := tx.Exec(`DELETE FROM decks WHERE id = $1 AND user_id = $2`, deckID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete deck: %w", err)
+	}
+	if err := checkDeckAffected(result); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit deck deletion: %w", err)
+	}
+
+	return nil
+}
+
+// CountNewWordsReviewed возвращает, сколько слов колоды впервые попали в журнал
+// ответов начиная с since, то есть сколько новых слов пользователь уже начал учить
+func (r *WordRepository) CountNewWordsReviewed(userID int64, deckID int, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM words w
+		WHERE w.user_id = $1 AND w.deck_id = $2
+			AND (SELECT MIN(q.created_at) FROM quizzes q WHERE q.word_id = w.id) >= $3
+	`, userID, deckID, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count new words: %w", err)
+	}
+
+	return count, nil
+}
+
+// SetDeckShareCode публикует колоду пользователя под кодом code;
+// пустой code отзывает ссылку на колоду
+func (r *WordRepository) SetDeckShareCode(userID int64, deckID int, code string) error {
+	result, err := r.db.Exec(`UPDATE decks SET share_code = $1 WHERE id = $2 AND user_id = $3`, code, deckID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set deck share code: %w", err)
+	}
+
+	return checkDeckAffected(result)
+}
+
+// GetDeckByShareCode получает опубликованную колоду по коду ссылки или nil, если ее нет
+func (r *WordRepository) GetDeckByShareCode(code string) (*Deck, error) {
+	if code == "" {
+		return nil, nil // Пустой код означает, что колода не опубликована
+	}
+
+	rows, err := r.db.Query(`SELECT `+deckColumns+` FROM decks d WHERE d.share_code = $1`, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deck by share code: %w", err)
+	}
+	defer rows.Close()
+
+	decks, err := scanDecks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(decks) == 0 {
+		return nil, nil // Колода не найдена
+	}
+
+	return decks[0], nil
+}
+
+// ImportDeck в одной транзакции создает колоду deck со словами words и
+// увеличивает счетчик импортов колоды sourceID, из которой они скопированы
+func (r *WordRepository) ImportDeck(deck *Deck, words []*Word, sourceID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertDeck(tx, deck); err != nil {
+		return err
+	}
+	for _, word := range words {
+		word.UserID, word.DeckID = deck.UserID, deck.ID
+		if err := saveWord(tx, word); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE decks SET imports = imports + 1 WHERE id = $1`, sourceID); err != nil {
+		return fmt.Errorf("failed to count deck import: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit deck import: %w", err)
+	}
+
+	deck.Words = len(words)
+	return nil
+}
+
+// checkDeckAffected возвращает ошибку, если запрос не изменил ни одной колоды
+func checkDeckAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("deck not found or not owned by user")
+	}
+	return nil
+}
+
+// scanDecks читает строки, выбранные со столбцами deckColumns
+func scanDecks(rows *sql.Rows) ([]*Deck, error) {
+	var decks []*Deck
+	for rows.Next() {
+		deck := &Deck{}
+		err := rows.Scan(&deck.ID, &deck.UserID, &deck.Name, &deck.Description, &deck.SourceLang, &deck.TargetLang,
+			&deck.NewPerDay, &deck.Scheduler, &deck.Active, &deck.ShareCode, &deck.Imports, &deck.CreatedAt, &deck.Words)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deck: %w", err)
+		}
+		decks = append(decks, deck)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read decks: %w", err)
+	}
+
+	return decks, nil
+}
@@ -0,0 +1,1254 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/textutil"
+)
+
+// MemoryDatabase хранит данные в памяти процесса. Используется в тестах
+// вместо PostgreSQL и повторяет поведение SQL-репозиториев.
+type MemoryDatabase struct {
+	mu         sync.Mutex
+	users      map[int64]*User
+	words      map[int]*Word
+	nextWordID int
+	reverse    map[int]ReviewState // Состояния слов в обратном направлении
+	tags       map[int]*Tag
+	nextTagID  int
+	wordTags   map[int]map[int]bool // ID тегов каждого слова
+	decks      map[int]*Deck
+	nextDeckID int
+	quizzes    []*Quiz
+	nextQuizID int
+	sessions   map[string]*QuizSession
+	rounds     map[string]*QuizRound
+}
+
+// NewMemoryDatabase создает пустое хранилище в памяти
+func NewMemoryDatabase() *MemoryDatabase {
+	return &MemoryDatabase{
+		users:      make(map[int64]*User),
+		words:      make(map[int]*Word),
+		nextWordID: 1,
+		reverse:    make(map[int]ReviewState),
+		tags:       make(map[int]*Tag),
+		nextTagID:  1,
+		wordTags:   make(map[int]map[int]bool),
+		decks:      make(map[int]*Deck),
+		nextDeckID: 1,
+		nextQuizID: 1,
+		sessions:   make(map[string]*QuizSession),
+		rounds:     make(map[string]*QuizRound),
+	}
+}
+
+// MemoryUserRepository реализует UserStore поверх MemoryDatabase
+type MemoryUserRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryUserRepository создает репозиторий пользователей в памяти
+func NewMemoryUserRepository(database *MemoryDatabase) *MemoryUserRepository {
+	return &MemoryUserRepository{db: database}
+}
+
+// CreateOrUpdateUser создает или обновляет пользователя
+func (r *MemoryUserRepository) CreateOrUpdateUser(user *User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if existing, ok := r.db.users[user.ID]; ok {
+		// Как и ON CONFLICT в SQL-версии, состояние не перезаписываем
+		existing.Username = user.Username
+		existing.FirstName = user.FirstName
+		existing.LastName = user.LastName
+		return nil
+	}
+
+	stored := *user
+	stored.Scheduler = SchedulerSM2
+	stored.QuizMode = DirectionForward
+	stored.QuizPresentation = PresentationButtons
+	stored.CreatedAt = time.Now()
+	r.db.users[user.ID] = &stored
+
+	return nil
+}
+
+// GetUser получает пользователя по ID
+func (r *MemoryUserRepository) GetUser(userID int64) (*User, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	user, ok := r.db.users[userID]
+	if !ok {
+		return nil, nil // Пользователь не найден
+	}
+
+	result := *user
+	return &result, nil
+}
+
+// UpdateUserState обновляет состояние пользователя и сбрасывает данные прежнего состояния
+func (r *MemoryUserRepository) UpdateUserState(userID int64, state string) error {
+	return r.UpdateUserStateData(userID, state, "")
+}
+
+// UpdateUserStateData обновляет состояние пользователя вместе с его данными
+func (r *MemoryUserRepository) UpdateUserStateData(userID int64, state, data string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if user, ok := r.db.users[userID]; ok {
+		user.State = state
+		user.StateData = data
+	}
+
+	return nil
+}
+
+// UpdateUserScheduler сохраняет выбранный пользователем алгоритм повторения
+func (r *MemoryUserRepository) UpdateUserScheduler(userID int64, scheduler string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if user, ok := r.db.users[userID]; ok {
+		user.Scheduler = scheduler
+	}
+
+	return nil
+}
+
+// UpdateUserQuizMode сохраняет выбранный пользователем режим /quiz
+func (r *MemoryUserRepository) UpdateUserQuizMode(userID int64, mode string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if user, ok := r.db.users[userID]; ok {
+		user.QuizMode = mode
+	}
+
+	return nil
+}
+
+// UpdateUserQuizPresentation сохраняет выбранный пользователем способ показа вопросов /quiz
+func (r *MemoryUserRepository) UpdateUserQuizPresentation(userID int64, presentation string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if user, ok := r.db.users[userID]; ok {
+		user.QuizPresentation = presentation
+	}
+
+	return nil
+}
+
+// MemoryWordRepository реализует WordStore поверх MemoryDatabase
+type MemoryWordRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryWordRepository создает репозиторий слов в памяти
+func NewMemoryWordRepository(database *MemoryDatabase) *MemoryWordRepository {
+	return &MemoryWordRepository{db: database}
+}
+
+// SaveWord сохраняет новое слово вместе с его тегами
+func (r *MemoryWordRepository) SaveWord(word *Word) error {
+	return r.SaveWords([]*Word{word})
+}
+
+// SaveWords сохраняет несколько слов: либо все, либо ни одного
+func (r *MemoryWordRepository) SaveWords(words []*Word) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, word := range words {
+		if _, ok := r.db.users[word.UserID]; !ok {
+			return fmt.Errorf("failed to save word: user %d does not exist", word.UserID)
+		}
+	}
+
+	now := time.Now()
+	for _, word := range words {
+		r.db.insertWord(word, now)
+	}
+
+	return nil
+}
+
+// insertWord добавляет слово с тегами и начальным расписанием и заполняет его ID
+// и дату создания. Вызывающий должен удерживать мьютекс.
+func (d *MemoryDatabase) insertWord(word *Word, now time.Time) {
+	word.ID = d.nextWordID
+	word.CreatedAt = now
+	d.nextWordID++
+
+	stored := *word
+	stored.LastReview = now
+	stored.NextReview = now.AddDate(0, 0, 1)
+	stored.Interval = 1
+	stored.Difficulty = 0
+	stored.EaseFactor = 2.5
+	stored.Repetitions = 0
+	stored.Lapses = 0
+	stored.Tags = nil
+	d.words[stored.ID] = &stored
+	d.attachTags(word.UserID, word.ID, word.Tags)
+}
+
+// GetUserWords получает все слова пользователя
+func (r *MemoryWordRepository) GetUserWords(userID int64) ([]*Word, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	words := r.db.userWords(userID, func(*Word) bool { return true })
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].CreatedAt.Equal(words[j].CreatedAt) {
+			return words[i].ID > words[j].ID
+		}
+		return words[i].CreatedAt.After(words[j].CreatedAt)
+	})
+
+	return words, nil
+}
+
+// ListWords возвращает страницу слов пользователя в порядке показа
+func (r *MemoryWordRepository) ListWords(q WordListQuery) ([]*Word, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	words, err := r.db.wordList(q)
+	if err != nil {
+		return nil, err
+	}
+	if q.Limit > 0 && len(words) > q.Limit {
+		if q.Backward {
+			words = words[len(words)-q.Limit:]
+		} else {
+			words = words[:q.Limit]
+		}
+	}
+	return words, nil
+}
+
+// CountWords возвращает, сколько слов выбрал бы запрос без ограничения Limit
+func (r *MemoryWordRepository) CountWords(q WordListQuery) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	words, err := r.db.wordList(q)
+	if err != nil {
+		return 0, err
+	}
+	return len(words), nil
+}
+
+// SearchWords ищет слова пользователя по слову, переводу и контексту с учетом опечаток
+func (r *MemoryWordRepository) SearchWords(userID int64, query string, limit int) ([]*Word, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	words := r.db.userWords(userID, func(*Word) bool { return true })
+	return rankSearch(words, query, limit), nil
+}
+
+// GetWordsForReview получает слова для повторения
+func (r *MemoryWordRepository) GetWordsForReview(userID int64) ([]*Word, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	words := r.db.userWords(userID, func(w *Word) bool { return !w.NextReview.After(now) })
+	sort.Slice(words, func(i, j int) bool {
+		return words[i].NextReview.Before(words[j].NextReview)
+	})
+
+	if len(words) > 10 {
+		words = words[:10]
+	}
+
+	return words, nil
+}
+
+// GetWord получает слово по ID
+func (r *MemoryWordRepository) GetWord(wordID int) (*Word, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	word, ok := r.db.words[wordID]
+	if !ok {
+		return nil, nil // Слово не найдено
+	}
+
+	result := *word
+	return &result, nil
+}
+
+// GetWordByNorm получает слово пользователя по нормализованному написанию norm
+// (см. textutil.NormalizeWord). Если дубликаты еще не объединены, возвращается
+// самое раннее слово.
+func (r *MemoryWordRepository) GetWordByNorm(userID int64, norm string) (*Word, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var found *Word
+	for _, word := range r.db.words {
+		if word.UserID == userID && textutil.NormalizeWord(word.Word) == norm && (found == nil || word.ID < found.ID) {
+			found = word
+		}
+	}
+	if found == nil {
+		return nil, nil // Слово не найдено
+	}
+
+	result := *found
+	return &result, nil
+}
+
+// SaveReviewState сохраняет параметры повторения слова
+func (r *MemoryWordRepository) SaveReviewState(wordID int, state ReviewState) error {
+	return r.SaveReviewStates(map[int]ReviewState{wordID: state})
+}
+
+// SaveReviewStates сохраняет параметры повторения нескольких слов атомарно
+func (r *MemoryWordRepository) SaveReviewStates(states map[int]ReviewState) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for wordID := range states {
+		if _, ok := r.db.words[wordID]; !ok {
+			return fmt.Errorf("failed to update word review: word %d not found", wordID)
+		}
+	}
+	for wordID, state := range states {
+		r.db.words[wordID].ReviewState = state
+	}
+
+	return nil
+}
+
+// SaveDueReviewState сохраняет параметры повторения слова, только если его пора
+// повторять (next_review не позже now), и сообщает, сохранены ли они
+func (r *MemoryWordRepository) SaveDueReviewState(wordID int, state ReviewState, now time.Time) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	word, ok := r.db.words[wordID]
+	if !ok || word.NextReview.After(now) {
+		return false, nil
+	}
+	word.ReviewState = state
+
+	return true, nil
+}
+
+// ResetReviewState записывает слову состояние state в прямом направлении
+// и удаляет его историю в обратном
+func (r *MemoryWordRepository) ResetReviewState(wordID int, state ReviewState) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	word, ok := r.db.words[wordID]
+	if !ok {
+		return fmt.Errorf("failed to update word review: word %d not found", wordID)
+	}
+	word.ReviewState = state
+	delete(r.db.reverse, wordID)
+
+	return nil
+}
+
+// GetReverseReviewState возвращает состояние слова в обратном направлении
+// или nil, если в этом направлении слово еще не повторялось
+func (r *MemoryWordRepository) GetReverseReviewState(wordID int) (*ReviewState, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	state, ok := r.db.reverse[wordID]
+	if !ok {
+		return nil, nil // Слово еще не повторялось в обратном направлении
+	}
+
+	return &state, nil
+}
+
+// GetReverseReviewStates возвращает состояния слов пользователя в обратном направлении
+func (r *MemoryWordRepository) GetReverseReviewStates(userID int64) (map[int]ReviewState, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	states := make(map[int]ReviewState)
+	for wordID, state := range r.db.reverse {
+		if r.db.words[wordID].UserID == userID {
+			states[wordID] = state
+		}
+	}
+
+	return states, nil
+}
+
+// SaveReverseReviewState сохраняет состояние слова в обратном направлении
+func (r *MemoryWordRepository) SaveReverseReviewState(wordID int, state ReviewState) error {
+	return r.SaveReverseReviewStates(map[int]ReviewState{wordID: state})
+}
+
+// SaveReverseReviewStates сохраняет состояния нескольких слов в обратном направлении атомарно
+func (r *MemoryWordRepository) SaveReverseReviewStates(states map[int]ReviewState) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for wordID := range states {
+		if _, ok := r.db.words[wordID]; !ok {
+			return fmt.Errorf("failed to save reverse review state: word %d not found", wordID)
+		}
+	}
+	for wordID, state := range states {
+		r.db.reverse[wordID] = state
+	}
+
+	return nil
+}
+
+// DeleteWord удаляет слово
+func (r *MemoryWordRepository) DeleteWord(wordID int, userID int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	word, ok := r.db.words[wordID]
+	if !ok || word.UserID != userID {
+		return fmt.Errorf("word not found or not owned by user")
+	}
+
+	r.db.deleteWord(wordID)
+	return nil
+}
+
+// UpdateWord сохраняет написание, перевод и контекст слова пользователя.
+// Параметры повторения не меняются.
+func (r *MemoryWordRepository) UpdateWord(word *Word) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.words[word.ID]
+	if !ok || stored.UserID != word.UserID {
+		return fmt.Errorf("word not found or not owned by user")
+	}
+
+	stored.Word, stored.Translation, stored.Context = word.Word, word.Translation, word.Context
+	return nil
+}
+
+// MergeWords сохраняет текстовые поля keep, переносит на него журнал ответов
+// и теги слов duplicateIDs и удаляет эти слова: либо все изменения, либо ни одного
+func (r *MemoryWordRepository) MergeWords(keep *Word, duplicateIDs []int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.words[keep.ID]
+	if !ok || stored.UserID != keep.UserID {
+		return fmt.Errorf("word not found or not owned by user")
+	}
+	for _, wordID := range duplicateIDs {
+		if wordID == keep.ID {
+			return fmt.Errorf("cannot merge word %d into itself", wordID)
+		}
+		if word, ok := r.db.words[wordID]; !ok || word.UserID != keep.UserID {
+			return fmt.Errorf("word %d not found or not owned by user", wordID)
+		}
+	}
+
+	stored.Word, stored.Translation, stored.Context = keep.Word, keep.Translation, keep.Context
+	for _, wordID := range duplicateIDs {
+		for _, quiz := range r.db.quizzes {
+			if quiz.WordID == wordID {
+				quiz.WordID = keep.ID
+			}
+		}
+		for tagID := range r.db.wordTags[wordID] {
+			r.db.tagWord(keep.ID, tagID)
+		}
+		r.db.deleteWord(wordID)
+	}
+
+	return nil
+}
+
+// deleteWord удаляет слово вызывающего, уже захватившего блокировку. Как ON DELETE
+// CASCADE в SQL-версии, журнал и сессии тестов удаляются вместе со словом.
+func (d *MemoryDatabase) deleteWord(wordID int) {
+	delete(d.words, wordID)
+	delete(d.reverse, wordID)
+	delete(d.wordTags, wordID)
+
+	quizzes := d.quizzes[:0]
+	for _, quiz := range d.quizzes {
+		if quiz.WordID != wordID {
+			quizzes = append(quizzes, quiz)
+		}
+	}
+	d.quizzes = quizzes
+	for id, session := range d.sessions {
+		if session.WordID == wordID {
+			delete(d.sessions, id)
+		}
+	}
+}
+
+// AddWordTags добавляет слову пользователя теги tags, создавая новые теги
+func (r *MemoryWordRepository) AddWordTags(userID int64, wordID int, tags []string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if word, ok := r.db.words[wordID]; !ok || word.UserID != userID {
+		return fmt.Errorf("word not found or not owned by user")
+	}
+
+	r.db.attachTags(userID, wordID, tags)
+	return nil
+}
+
+// RemoveWordTags снимает со слова пользователя теги tags
+func (r *MemoryWordRepository) RemoveWordTags(userID int64, wordID int, tags []string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if word, ok := r.db.words[wordID]; !ok || word.UserID != userID {
+		return fmt.Errorf("word not found or not owned by user")
+	}
+
+	for _, name := range tags {
+		if tag := r.db.tagByName(userID, name); tag != nil {
+			delete(r.db.wordTags[wordID], tag.ID)
+		}
+	}
+	return nil
+}
+
+// GetWordTags возвращает имена тегов каждого слова пользователя по алфавиту
+func (r *MemoryWordRepository) GetWordTags(userID int64) (map[int][]string, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	tags := make(map[int][]string)
+	for wordID, tagIDs := range r.db.wordTags {
+		if r.db.words[wordID].UserID != userID {
+			continue
+		}
+		for tagID := range tagIDs {
+			tags[wordID] = append(tags[wordID], r.db.tags[tagID].Name)
+		}
+		sort.Strings(tags[wordID])
+	}
+	return tags, nil
+}
+
+// GetUserTags возвращает теги пользователя, у которых есть слова, по алфавиту
+func (r *MemoryWordRepository) GetUserTags(userID int64) ([]*Tag, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var tags []*Tag
+	for _, tag := range r.db.tags {
+		if tag.UserID != userID {
+			continue
+		}
+		if copied := r.db.tagCopy(tag); copied.Words > 0 {
+			tags = append(tags, copied)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// GetTagByName получает тег пользователя по имени или nil, если его нет
+func (r *MemoryWordRepository) GetTagByName(userID int64, name string) (*Tag, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if tag := r.db.tagByName(userID, name); tag != nil {
+		return r.db.tagCopy(tag), nil
+	}
+	return nil, nil // Тег не найден
+}
+
+// GetTag получает тег по ID или nil, если его нет
+func (r *MemoryWordRepository) GetTag(tagID int) (*Tag, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if tag, ok := r.db.tags[tagID]; ok {
+		return r.db.tagCopy(tag), nil
+	}
+	return nil, nil // Тег не найден
+}
+
+// attachTags создает недостающие теги пользователя и связывает их со словом.
+// Вызывающий должен удерживать мьютекс.
+func (d *MemoryDatabase) attachTags(userID int64, wordID int, names []string) {
+	for _, name := range names {
+		tag := d.tagByName(userID, name)
+		if tag == nil {
+			tag = &Tag{ID: d.nextTagID, UserID: userID, Name: name}
+			d.tags[tag.ID] = tag
+			d.nextTagID++
+		}
+		d.tagWord(wordID, tag.ID)
+	}
+}
+
+// tagWord связывает слово с тегом. Вызывающий должен удерживать мьютекс.
+func (d *MemoryDatabase) tagWord(wordID, tagID int) {
+	if d.wordTags[wordID] == nil {
+		d.wordTags[wordID] = make(map[int]bool)
+	}
+	d.wordTags[wordID][tagID] = true
+}
+
+// tagByName ищет тег пользователя по имени. Вызывающий должен удерживать мьютекс.
+func (d *MemoryDatabase) tagByName(userID int64, name string) *Tag {
+	for _, tag := range d.tags {
+		if tag.UserID == userID && tag.Name == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+// tagCopy возвращает копию тега с числом его слов. Вызывающий должен удерживать мьютекс.
+func (d *MemoryDatabase) tagCopy(tag *Tag) *Tag {
+	copied := *tag
+	copied.Words = 0
+	for _, tagIDs := range d.wordTags {
+		if tagIDs[tag.ID] {
+			copied.Words++
+		}
+	}
+	return &copied
+}
+
+// CreateDeck сохраняет новую колоду и заполняет ее ID и дату создания
+func (r *MemoryWordRepository) CreateDeck(deck *Deck) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.insertDeck(deck)
+}
+
+// GetDeck получает колоду по ID или nil, если ее нет
+func (r *MemoryWordRepository) GetDeck(deckID int) (*Deck, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if deck, ok := r.db.decks[deckID]; ok {
+		return r.db.deckCopy(deck), nil
+	}
+	return nil, nil // Колода не найдена
+}
+
+// GetUserDecks возвращает колоды пользователя в порядке создания
+func (r *MemoryWordRepository) GetUserDecks(userID int64) ([]*Deck, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var decks []*Deck
+	for _, deck := range r.db.decks {
+		if deck.UserID == userID {
+			decks = append(decks, r.db.deckCopy(deck))
+		}
+	}
+	sort.Slice(decks, func(i, j int) bool { return decks[i].ID < decks[j].ID })
+	return decks, nil
+}
+
+// UpdateDeck сохраняет название, описание, языки и настройки колоды пользователя
+func (r *MemoryWordRepository) UpdateDeck(deck *Deck) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.updateDeck(deck)
+}
+
+// UpdateDeckScheduler сохраняет колоду и состояния ее слов в обоих направлениях атомарно
+func (r *MemoryWordRepository) UpdateDeckScheduler(deck *Deck, states, reverse map[int]ReviewState) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, wordStates := range []map[int]ReviewState{states, reverse} {
+		for wordID := range wordStates {
+			if _, ok := r.db.words[wordID]; !ok {
+				return fmt.Errorf("failed to update word review: word %d not found", wordID)
+			}
+		}
+	}
+	if err := r.db.updateDeck(deck); err != nil {
+		return err
+	}
+	for wordID, state := range states {
+		r.db.words[wordID].ReviewState = state
+	}
+	for wordID, state := range reverse {
+		r.db.reverse[wordID] = state
+	}
+
+	return nil
+}
+
+// updateDeck сохраняет поля колоды. Вызывающий должен удерживать мьютекс.
+func (d *MemoryDatabase) updateDeck(deck *Deck) error {
+	stored, ok := d.decks[deck.ID]
+	if !ok || stored.UserID != deck.UserID {
+		return fmt.Errorf("deck not found or not owned by user")
+	}
+	for _, other := range d.decks {
+		if other.ID != deck.ID && other.UserID == deck.UserID && other.Name == deck.Name {
+			return fmt.Errorf("failed to update deck: deck %q already exists", deck.Name)
+		}
+	}
+
+	stored.Name = deck.Name
+	stored.Description = deck.Description
+	stored.SourceLang = deck.SourceLang
+	stored.TargetLang = deck.TargetLang
+	stored.NewPerDay = deck.NewPerDay
+	stored.Scheduler = deck.Scheduler
+	return nil
+}
+
+// SetActiveDeck делает колоду пользователя активной, а остальные его колоды — неактивными
+func (r *MemoryWordRepository) SetActiveDeck(userID int64, deckID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if deck, ok := r.db.decks[deckID]; !ok || deck.UserID != userID {
+		return fmt.Errorf("deck not found or not owned by user")
+	}
+	for _, deck := range r.db.decks {
+		if deck.UserID == userID {
+			deck.Active = deck.ID == deckID
+		}
+	}
+	return nil
+}
+
+// MoveWords переносит слова пользователя в его колоду deckID: либо все, либо ни одного
+func (r *MemoryWordRepository) MoveWords(userID int64, wordIDs []int, deckID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if deck, ok := r.db.decks[deckID]; !ok || deck.UserID != userID {
+		return fmt.Errorf("deck not found or not owned by user")
+	}
+	for _, wordID := range wordIDs {
+		if word, ok := r.db.words[wordID]; !ok || word.UserID != userID {
+			return fmt.Errorf("word %d not found or not owned by user", wordID)
+		}
+	}
+
+	for _, wordID := range wordIDs {
+		r.db.words[wordID].DeckID = deckID
+	}
+	return nil
+}
+
+// DeleteDeck удаляет колоду пользователя вместе с ее словами
+func (r *MemoryWordRepository) DeleteDeck(userID int64, deckID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if deck, ok := r.db.decks[deckID]; !ok || deck.UserID != userID {
+		return fmt.Errorf("deck not found or not owned by user")
+	}
+	for id, word := range r.db.words {
+		if word.DeckID == deckID && word.UserID == userID {
+			r.db.deleteWord(id)
+		}
+	}
+	delete(r.db.decks, deckID)
+	return nil
+}
+
+// CountNewWordsReviewed возвращает, сколько слов колоды впервые попали в журнал
+// ответов начиная с since
+func (r *MemoryWordRepository) CountNewWordsReviewed(userID int64, deckID int, since time.Time) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	first := make(map[int]time.Time)
+	for _, quiz := range r.db.quizzes {
+		if at, ok := first[quiz.WordID]; !ok || quiz.CreatedAt.Before(at) {
+			first[quiz.WordID] = quiz.CreatedAt
+		}
+	}
+
+	count := 0
+	for _, word := range r.db.words {
+		at, ok := first[word.ID]
+		if word.UserID == userID && word.DeckID == deckID && ok && !at.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// SetDeckShareCode публикует колоду пользователя под кодом code;
+// пустой code отзывает ссылку на колоду
+func (r *MemoryWordRepository) SetDeckShareCode(userID int64, deckID int, code string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	deck, ok := r.db.decks[deckID]
+	if !ok || deck.UserID != userID {
+		return fmt.Errorf("deck not found or not owned by user")
+	}
+	if code != "" {
+		for _, other := range r.db.decks {
+			if other.ID != deckID && other.ShareCode == code {
+				return fmt.Errorf("failed to set deck share code: code %q is taken", code)
+			}
+		}
+	}
+
+	deck.ShareCode = code
+	return nil
+}
+
+// GetDeckByShareCode получает опубликованную колоду по коду ссылки или nil, если ее нет
+func (r *MemoryWordRepository) GetDeckByShareCode(code string) (*Deck, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if code == "" {
+		return nil, nil // Пустой код означает, что колода не опубликована
+	}
+	for _, deck := range r.db.decks {
+		if deck.ShareCode == code {
+			return r.db.deckCopy(deck), nil
+		}
+	}
+	return nil, nil // Колода не найдена
+}
+
+// ImportDeck создает колоду deck со словами words и увеличивает счетчик
+// импортов колоды sourceID: либо все, либо ничего
+func (r *MemoryWordRepository) ImportDeck(deck *Deck, words []*Word, sourceID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	source, ok := r.db.decks[sourceID]
+	if !ok {
+		return fmt.Errorf("failed to import deck: deck %d does not exist", sourceID)
+	}
+	if err := r.db.insertDeck(deck); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, word := range words {
+		word.UserID, word.DeckID = deck.UserID, deck.ID
+		r.db.insertWord(word, now)
+	}
+	source.Imports++
+
+	deck.Words = len(words)
+	return nil
+}
+
+// insertDeck добавляет колоду и заполняет ее ID и дату создания.
+// Вызывающий должен удерживать мьютекс.
+func (d *MemoryDatabase) insertDeck(deck *Deck) error {
+	if _, ok := d.users[deck.UserID]; !ok {
+		return fmt.Errorf("failed to create deck: user %d does not exist", deck.UserID)
+	}
+	for _, other := range d.decks {
+		if other.UserID == deck.UserID && other.Name == deck.Name {
+			return fmt.Errorf("failed to create deck: deck %q already exists", deck.Name)
+		}
+		if other.UserID == deck.UserID && other.Active && deck.Active {
+			return fmt.Errorf("failed to create deck: user %d already has an active deck", deck.UserID)
+		}
+	}
+
+	deck.ID = d.nextDeckID
+	deck.CreatedAt = time.Now()
+	d.nextDeckID++
+
+	stored := *deck
+	stored.Words, stored.ShareCode, stored.Imports = 0, "", 0
+	d.decks[stored.ID] = &stored
+	return nil
+}
+
+// deckCopy возвращает копию колоды с числом ее слов. Вызывающий должен удерживать мьютекс.
+func (d *MemoryDatabase) deckCopy(deck *Deck) *Deck {
+	copied := *deck
+	copied.Words = 0
+	for _, word := range d.words {
+		if word.DeckID == deck.ID {
+			copied.Words++
+		}
+	}
+	return &copied
+}
+
+// MemoryReviewLogRepository реализует ReviewLogStore поверх MemoryDatabase
+type MemoryReviewLogRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryReviewLogRepository создает журнал ответов в памяти
+func NewMemoryReviewLogRepository(database *MemoryDatabase) *MemoryReviewLogRepository {
+	return &MemoryReviewLogRepository{db: database}
+}
+
+// SaveQuiz записывает ответ в журнал
+func (r *MemoryReviewLogRepository) SaveQuiz(quiz *Quiz) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[quiz.UserID]; !ok {
+		return fmt.Errorf("failed to save quiz: user %d does not exist", quiz.UserID)
+	}
+	if _, ok := r.db.words[quiz.WordID]; !ok {
+		return fmt.Errorf("failed to save quiz: word %d does not exist", quiz.WordID)
+	}
+
+	quiz.ID = r.db.nextQuizID
+	quiz.CreatedAt = time.Now()
+	r.db.nextQuizID++
+
+	stored := *quiz
+	r.db.quizzes = append(r.db.quizzes, &stored)
+
+	return nil
+}
+
+// GetUserQuizzes возвращает последние ответы пользователя, начиная с новых
+func (r *MemoryReviewLogRepository) GetUserQuizzes(userID int64, limit int) ([]*Quiz, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var quizzes []*Quiz
+	// Записи добавляются по порядку, поэтому идем с конца
+	for i := len(r.db.quizzes) - 1; i >= 0 && len(quizzes) < limit; i-- {
+		if quiz := r.db.quizzes[i]; quiz.UserID == userID {
+			copied := *quiz
+			quizzes = append(quizzes, &copied)
+		}
+	}
+
+	return quizzes, nil
+}
+
+// GetWordQuizzes возвращает всю историю ответов по слову в хронологическом порядке
+func (r *MemoryReviewLogRepository) GetWordQuizzes(wordID int) ([]*Quiz, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var quizzes []*Quiz
+	for _, quiz := range r.db.quizzes {
+		if quiz.WordID == wordID {
+			copied := *quiz
+			quizzes = append(quizzes, &copied)
+		}
+	}
+
+	return quizzes, nil
+}
+
+// MemoryQuizSessionRepository реализует QuizSessionStore поверх MemoryDatabase
+type MemoryQuizSessionRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryQuizSessionRepository создает хранилище сессий тестов в памяти
+func NewMemoryQuizSessionRepository(database *MemoryDatabase) *MemoryQuizSessionRepository {
+	return &MemoryQuizSessionRepository{db: database}
+}
+
+// CreateQuizSession сохраняет новую сессию теста
+func (r *MemoryQuizSessionRepository) CreateQuizSession(session *QuizSession) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[session.UserID]; !ok {
+		return fmt.Errorf("failed to create quiz session: user %d does not exist", session.UserID)
+	}
+	if _, ok := r.db.words[session.WordID]; !ok {
+		return fmt.Errorf("failed to create quiz session: word %d does not exist", session.WordID)
+	}
+	if _, ok := r.db.sessions[session.ID]; ok {
+		return fmt.Errorf("failed to create quiz session: session %s already exists", session.ID)
+	}
+	if _, ok := r.db.rounds[session.RoundID]; session.RoundID != "" && !ok {
+		return fmt.Errorf("failed to create quiz session: round %s does not exist", session.RoundID)
+	}
+
+	stored := *session
+	stored.Options = append([]string(nil), session.Options...)
+	r.db.sessions[session.ID] = &stored
+
+	return nil
+}
+
+// GetQuizSession получает сессию теста по ID
+func (r *MemoryQuizSessionRepository) GetQuizSession(sessionID string) (*QuizSession, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	session, ok := r.db.sessions[sessionID]
+	if !ok {
+		return nil, nil // Сессия не найдена
+	}
+
+	result := *session
+	result.Options = append([]string(nil), session.Options...)
+	return &result, nil
+}
+
+// GetQuizSessionByPoll получает сессию теста по ID опроса Telegram
+func (r *MemoryQuizSessionRepository) GetQuizSessionByPoll(pollID string) (*QuizSession, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, session := range r.db.sessions {
+		if pollID != "" && session.PollID == pollID {
+			result := *session
+			result.Options = append([]string(nil), session.Options...)
+			return &result, nil
+		}
+	}
+
+	return nil, nil // Сессия не найдена
+}
+
+// SetQuizSessionPoll запоминает опрос Telegram, в котором задан вопрос сессии
+func (r *MemoryQuizSessionRepository) SetQuizSessionPoll(sessionID, pollID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for id, session := range r.db.sessions {
+		if id != sessionID && pollID != "" && session.PollID == pollID {
+			return fmt.Errorf("failed to set quiz session poll: poll %s is already used", pollID)
+		}
+	}
+	if session, ok := r.db.sessions[sessionID]; ok {
+		session.PollID = pollID
+	}
+
+	return nil
+}
+
+// MarkQuizSessionAnswered отмечает ответ на сессию; false — ответ уже был записан
+func (r *MemoryQuizSessionRepository) MarkQuizSessionAnswered(sessionID string, chosenIdx int,
+	answeredAt time.Time) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	session, ok := r.db.sessions[sessionID]
+	if !ok || session.Answered() {
+		return false, nil
+	}
+
+	session.ChosenIdx = chosenIdx
+	session.AnsweredAt = answeredAt
+	return true, nil
+}
+
+// ClearQuizSessionAnswer снимает отметку ответа, чтобы на вопрос можно было ответить снова
+func (r *MemoryQuizSessionRepository) ClearQuizSessionAnswer(sessionID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if session, ok := r.db.sessions[sessionID]; ok {
+		session.ChosenIdx = 0
+		session.AnsweredAt = time.Time{}
+	}
+	return nil
+}
+
+// SaveQuizSessionReview запоминает, как ответ сдвинул дату следующего повторения слова
+func (r *MemoryQuizSessionRepository) SaveQuizSessionReview(sessionID string, nextReviewBefore,
+	nextReviewAfter time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if session, ok := r.db.sessions[sessionID]; ok {
+		session.NextReviewBefore = nextReviewBefore
+		session.NextReviewAfter = nextReviewAfter
+	}
+
+	return nil
+}
+
+// DeleteExpiredQuizSessions удаляет сессии и раунды, срок которых истек до указанного времени
+func (r *MemoryQuizSessionRepository) DeleteExpiredQuizSessions(before time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for id, round := range r.db.rounds {
+		if round.ExpiresAt.Before(before) {
+			delete(r.db.rounds, id)
+		}
+	}
+	for id, session := range r.db.sessions {
+		// Как ON DELETE CASCADE, вместе с раундом удаляются его вопросы
+		_, roundExists := r.db.rounds[session.RoundID]
+		if session.ExpiresAt.Before(before) || (session.RoundID != "" && !roundExists) {
+			delete(r.db.sessions, id)
+		}
+	}
+
+	return nil
+}
+
+// CreateQuizRound сохраняет новый раунд теста
+func (r *MemoryQuizSessionRepository) CreateQuizRound(round *QuizRound) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[round.UserID]; !ok {
+		return fmt.Errorf("failed to create quiz round: user %d does not exist", round.UserID)
+	}
+	if _, ok := r.db.rounds[round.ID]; ok {
+		return fmt.Errorf("failed to create quiz round: round %s already exists", round.ID)
+	}
+
+	stored := *round
+	r.db.rounds[round.ID] = &stored
+
+	return nil
+}
+
+// GetQuizRound получает раунд теста по ID
+func (r *MemoryQuizSessionRepository) GetQuizRound(roundID string) (*QuizRound, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	round, ok := r.db.rounds[roundID]
+	if !ok {
+		return nil, nil // Раунд не найден
+	}
+
+	result := *round
+	return &result, nil
+}
+
+// GetRoundSessions возвращает вопросы раунда в порядке их показа
+func (r *MemoryQuizSessionRepository) GetRoundSessions(roundID string) ([]*QuizSession, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var sessions []*QuizSession
+	for _, session := range r.db.sessions {
+		if session.RoundID == roundID {
+			copied := *session
+			copied.Options = append([]string(nil), session.Options...)
+			sessions = append(sessions, &copied)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Position < sessions[j].Position
+	})
+
+	return sessions, nil
+}
+
+// FinishQuizRound завершает раунд; false — раунд уже был завершен
+func (r *MemoryQuizSessionRepository) FinishQuizRound(roundID string, aborted bool,
+	finishedAt time.Time) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	round, ok := r.db.rounds[roundID]
+	if !ok || round.Finished() {
+		return false, nil
+	}
+
+	round.FinishedAt = finishedAt
+	round.Aborted = aborted
+	return true, nil
+}
+
+// wordList возвращает слова пользователя, отобранные фильтрами q (тег, колода,
+// срок повторения), в порядке q.Sort: начиная с q.FromID или, если q.Backward, все слова перед
+// ним. Как в SQL-версии, если у пользователя нет слова FromID, список начинается
+// сначала, а перед ним слов нет.
+func (d *MemoryDatabase) wordList(q WordListQuery) ([]*Word, error) {
+	less, ok := memoryWordSorts[q.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown word sort %q", q.Sort)
+	}
+
+	words := d.userWords(q.UserID, func(w *Word) bool {
+		return (q.TagID == 0 || d.wordTags[w.ID][q.TagID]) && (q.DeckID == 0 || w.DeckID == q.DeckID) &&
+			(q.DueBy.IsZero() || !w.NextReview.After(q.DueBy))
+	})
+	sort.Slice(words, func(i, j int) bool { return less(words[i], words[j]) })
+	if q.FromID == 0 {
+		return words, nil
+	}
+
+	anchor, ok := d.words[q.FromID]
+	if !ok || anchor.UserID != q.UserID {
+		if q.Backward {
+			return nil, nil
+		}
+		return words, nil
+	}
+	// Первое слово, которое не идет раньше FromID
+	start := sort.Search(len(words), func(i int) bool { return !less(words[i], anchor) })
+	if q.Backward {
+		return words[:start], nil
+	}
+	return words[start:], nil
+}
+
+// memoryWordSorts повторяет ключи сортировки wordSortKeys SQL-версии
+var memoryWordSorts = map[string]func(a, b *Word) bool{
+	WordSortRecent: func(a, b *Word) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	},
+	WordSortAlpha: func(a, b *Word) bool {
+		if wa, wb := strings.ToLower(a.Word), strings.ToLower(b.Word); wa != wb {
+			return wa < wb
+		}
+		return a.ID < b.ID
+	},
+	WordSortHardest: func(a, b *Word) bool {
+		if a.Lapses != b.Lapses {
+			return a.Lapses > b.Lapses
+		}
+		if a.EaseFactor != b.EaseFactor {
+			return a.EaseFactor < b.EaseFactor
+		}
+		return a.ID < b.ID
+	},
+	WordSortDue: func(a, b *Word) bool {
+		if !a.NextReview.Equal(b.NextReview) {
+			return a.NextReview.Before(b.NextReview)
+		}
+		return a.ID < b.ID
+	},
+}
+
+// userWords возвращает копии слов пользователя, удовлетворяющих фильтру.
+// Вызывающий должен удерживать мьютекс.
+func (d *MemoryDatabase) userWords(userID int64, keep func(*Word) bool) []*Word {
+	var words []*Word
+	for _, word := range d.words {
+		if word.UserID == userID && keep(word) {
+			copied := *word
+			words = append(words, &copied)
+		}
+	}
+	return words
+}
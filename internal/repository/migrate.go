@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrationsFS embed.FS
+
+// ErrSchemaTooNew возвращается, если база данных была мигрирована более новой версией бота
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary supports")
+
+// migration представляет одну пронумерованную миграцию схемы
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// loadMigrations читает встроенные файлы вида NNNN_name.up.sql / NNNN_name.down.sql
+// из каталога миграций указанного драйвера
+func loadMigrations(driver string) ([]migration, error) {
+	files, err := fs.Glob(migrationsFS, "migrations/"+driver+"/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no migrations found for driver %q", driver)
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, file := range files {
+		base := path.Base(file)
+
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s must end with .up.sql or .down.sql", base)
+		}
+
+		stem := strings.TrimSuffix(base, "."+direction+".sql")
+		versionPart, name, found := strings.Cut(stem, "_")
+		if !found {
+			return nil, fmt.Errorf("migration %s must be named NNNN_name", base)
+		}
+		version, err := strconv.Atoi(versionPart)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s has invalid version", base)
+		}
+
+		content, err := migrationsFS.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", base, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: name}
+			byVersion[version] = m
+		}
+		if m.name != name {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.name, name)
+		}
+		if direction == "up" {
+			m.up = string(content)
+		} else {
+			m.down = string(content)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down steps", m.version, m.name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return nil, fmt.Errorf("migration versions must be sequential, missing %04d", i+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+// ensureMigrationsTable создает таблицу учета примененных миграций
+func (d *Database) ensureMigrationsTable() error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	if _, err := d.Exec(query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return nil
+}
+
+// SchemaVersion возвращает номер последней примененной миграции (0 для пустой базы)
+func (d *Database) SchemaVersion() (int, error) {
+	if err := d.ensureMigrationsTable(); err != nil {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	if err := d.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	return int(version.Int64), nil
+}
+
+// Migrate применяет все еще не примененные миграции
+func (d *Database) Migrate() error {
+	migrations, err := loadMigrations(d.driver)
+	if err != nil {
+		return err
+	}
+
+	current, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	latest := len(migrations)
+	if current > latest {
+		return fmt.Errorf("%w: database is at version %d, binary knows up to %d", ErrSchemaTooNew, current, latest)
+	}
+
+	for _, m := range migrations[current:] {
+		if err := d.applyMigration(m.version, m.name, m.up, true); err != nil {
+			return err
+		}
+		log.Printf("Applied migration %04d_%s", m.version, m.name)
+	}
+
+	return nil
+}
+
+// MigrateDown откатывает указанное количество последних миграций
+func (d *Database) MigrateDown(steps int) error {
+	migrations, err := loadMigrations(d.driver)
+	if err != nil {
+		return err
+	}
+
+	current, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	if current > len(migrations) {
+		return fmt.Errorf("%w: cannot roll back from version %d", ErrSchemaTooNew, current)
+	}
+
+	for i := 0; i < steps && current > 0; i++ {
+		m := migrations[current-1]
+		if err := d.applyMigration(m.version, m.name, m.down, false); err != nil {
+			return err
+		}
+		log.Printf("Rolled back migration %04d_%s", m.version, m.name)
+		current--
+	}
+
+	return nil
+}
+
+// applyMigration выполняет шаг миграции и обновляет schema_migrations в одной транзакции
+func (d *Database) applyMigration(version int, name, script string, up bool) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %04d: %w", version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		return fmt.Errorf("failed to execute migration %04d_%s: %w", version, name, err)
+	}
+
+	if up {
+		_, err = tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, version, name)
+	} else {
+		_, err = tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %04d: %w", version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %04d: %w", version, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	postgres, err := loadMigrations(DriverPostgres)
+	if err != nil {
+		t.Fatalf("Expected embedded PostgreSQL migrations to load, got: %v", err)
+	}
+
+	sqlite, err := loadMigrations(DriverSQLite)
+	if err != nil {
+		t.Fatalf("Expected embedded SQLite migrations to load, got: %v", err)
+	}
+
+	// Каждая миграция должна существовать для обоих диалектов
+	if len(postgres) != len(sqlite) {
+		t.Fatalf("Expected the same number of migrations, got %d for PostgreSQL and %d for SQLite",
+			len(postgres), len(sqlite))
+	}
+	for i := range postgres {
+		if postgres[i].name != sqlite[i].name {
+			t.Errorf("Migration %04d is named %q for PostgreSQL but %q for SQLite",
+				postgres[i].version, postgres[i].name, sqlite[i].name)
+		}
+	}
+
+	checkMigrations(t, postgres)
+	checkMigrations(t, sqlite)
+}
+
+func checkMigrations(t *testing.T, migrations []migration) {
+	t.Helper()
+
+	if len(migrations) == 0 {
+		t.Fatal("Expected at least one migration")
+	}
+
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("Expected migration %d to have version %d, got %d", i, i+1, m.version)
+		}
+		if strings.TrimSpace(m.up) == "" || strings.TrimSpace(m.down) == "" {
+			t.Errorf("Migration %04d_%s has an empty step", m.version, m.name)
+		}
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	db, err := NewSQLiteDatabase(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("Failed to open SQLite database: %v", err)
+	}
+	defer db.Close()
+
+	latest, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("Failed to get schema version: %v", err)
+	}
+
+	if err := db.MigrateDown(latest); err != nil {
+		t.Fatalf("Failed to roll back migrations: %v", err)
+	}
+	if version, _ := db.SchemaVersion(); version != 0 {
+		t.Errorf("Expected version 0 after rollback, got %d", version)
+	}
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Failed to re-apply migrations: %v", err)
+	}
+	if version, _ := db.SchemaVersion(); version != latest {
+		t.Errorf("Expected version %d after migrate, got %d", latest, version)
+	}
+}
+
+func TestWithoutMigrate_RollsBackFromCurrentVersion(t *testing.T) {
+	path := t.TempDir() + "/test.db"
+	db, err := NewSQLiteDatabase(path)
+	if err != nil {
+		t.Fatalf("Failed to open SQLite database: %v", err)
+	}
+	latest, _ := db.SchemaVersion()
+	// База отстает от бинарника на две миграции
+	if err := db.MigrateDown(2); err != nil {
+		t.Fatalf("Failed to roll back migrations: %v", err)
+	}
+	db.Close()
+
+	db, err = NewSQLiteDatabase(path, WithoutMigrate())
+	if err != nil {
+		t.Fatalf("Failed to reopen SQLite database: %v", err)
+	}
+	defer db.Close()
+
+	if version, _ := db.SchemaVersion(); version != latest-2 {
+		t.Fatalf("Expected version %d without migrating, got %d", latest-2, version)
+	}
+	if err := db.MigrateDown(1); err != nil {
+		t.Fatalf("Failed to roll back migration: %v", err)
+	}
+	if version, _ := db.SchemaVersion(); version != latest-3 {
+		t.Errorf("Expected version %d after rolling back one migration, got %d", latest-3, version)
+	}
+}
+
+func TestMigrate_RefusesNewerSchema(t *testing.T) {
+	db, err := NewSQLiteDatabase(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("Failed to open SQLite database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, 9999, "future"); err != nil {
+		t.Fatalf("Failed to insert future migration: %v", err)
+	}
+
+	if err := db.Migrate(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Expected ErrSchemaTooNew, got: %v", err)
+	}
+}
+
+func TestMigrateSM2_ConvertsExistingWords(t *testing.T) {
+	db, err := NewSQLiteDatabase(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("Failed to open SQLite database: %v", err)
+	}
+	defer db.Close()
+
+	// Возвращаемся к схеме до SM-2 и создаем слова в старом формате
+	latest, _ := db.SchemaVersion()
+	if err := db.MigrateDown(latest - 1); err != nil {
+		t.Fatalf("Failed to roll back migrations: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO users (id) VALUES ($1)`, testUserID); err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO words (id, user_id, word, translation, interval, difficulty)
+		VALUES (1, $1, 'apple', 'яблоко', 15, 3), (2, $1, 'pear', 'груша', 1, 5)`, testUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert words: %v", err)
+	}
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	words, err := NewWordRepository(db).GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	byID := make(map[int]*Word)
+	for _, w := range words {
+		byID[w.ID] = w
+	}
+
+	if w := byID[1]; w.Repetitions != 2 || w.EaseFactor < 1.89 || w.EaseFactor > 1.91 {
+		t.Errorf("Expected 2 repetitions and ease 1.9, got %d and %v", w.Repetitions, w.EaseFactor)
+	}
+	if w := byID[2]; w.Repetitions != 0 || w.EaseFactor < 1.49 || w.EaseFactor > 1.51 {
+		t.Errorf("Expected 0 repetitions and ease 1.5, got %d and %v", w.Repetitions, w.EaseFactor)
+	}
+}
+
+func TestMigrateReviewLog_KeepsExistingQuizzes(t *testing.T) {
+	db, err := NewSQLiteDatabase(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("Failed to open SQLite database: %v", err)
+	}
+	defer db.Close()
+
+	// Откатываемся до схемы 0003, где quizzes еще не был журналом
+	latest, _ := db.SchemaVersion()
+	if err := db.MigrateDown(latest - 3); err != nil {
+		t.Fatalf("Failed to roll back migrations: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO users (id) VALUES ($1)`, testUserID); err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO words (id, user_id, word, translation) VALUES (1, $1, 'apple', 'яблоко')`, testUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert word: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO quizzes (user_id, word_id, correct) VALUES ($1, 1, TRUE)`, testUserID); err != nil {
+		t.Fatalf("Failed to insert quiz: %v", err)
+	}
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	quizzes, err := NewReviewLogRepository(db).GetWordQuizzes(1)
+	if err != nil {
+		t.Fatalf("Failed to get quizzes: %v", err)
+	}
+	if len(quizzes) != 1 || !quizzes[0].Correct {
+		t.Fatalf("Expected existing quiz to be kept, got %+v", quizzes)
+	}
+
+	// После миграции слово с историей удаляется вместе с ней
+	if err := NewWordRepository(db).DeleteWord(1, testUserID); err != nil {
+		t.Errorf("Failed to delete word with history: %v", err)
+	}
+}
+
+func TestMigrateWordNorm_FillsExistingWords(t *testing.T) {
+	path := t.TempDir() + "/test.db"
+	db, err := NewSQLiteDatabase(path)
+	if err != nil {
+		t.Fatalf("Failed to open SQLite database: %v", err)
+	}
+
+	// Слова, сохраненные до 0014_word_norm, включая дубликат
+	latest, _ := db.SchemaVersion()
+	if err := db.MigrateDown(latest - 13); err != nil {
+		t.Fatalf("Failed to roll back migrations: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO users (id) VALUES ($1)`, testUserID); err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO words (id, user_id, word, translation)
+		VALUES (1, $1, 'Ёлка', 'fir'), (2, $1, ' ЕЛКА ', 'fir tree')`, testUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert words: %v", err)
+	}
+	db.Close()
+
+	db, err = NewSQLiteDatabase(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen SQLite database: %v", err)
+	}
+	defer db.Close()
+
+	words := NewWordRepository(db)
+	if list, _ := words.GetUserWords(testUserID); len(list) != 2 {
+		t.Errorf("Expected duplicates to be kept until /duplicates, got %d words", len(list))
+	}
+	if fir, err := words.GetWordByNorm(testUserID, "елка"); err != nil || fir == nil || fir.ID != 1 {
+		t.Errorf("Expected the existing word to be found by its normalized spelling, got %+v, %v", fir, err)
+	}
+}
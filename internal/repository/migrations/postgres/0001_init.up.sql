@@ -0,0 +1,31 @@
+-- Начальная схема. IF NOT EXISTS позволяет принять под управление
+-- базы, созданные до появления миграций (Database.createTables).
+CREATE TABLE IF NOT EXISTS users (
+	id BIGINT PRIMARY KEY,
+	username VARCHAR(255),
+	first_name VARCHAR(255),
+	last_name VARCHAR(255),
+	state VARCHAR(50) DEFAULT 'idle',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS words (
+	id SERIAL PRIMARY KEY,
+	user_id BIGINT REFERENCES users(id),
+	word VARCHAR(255) NOT NULL,
+	translation VARCHAR(255) NOT NULL,
+	context TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	last_review TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	next_review TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	interval INTEGER DEFAULT 1,
+	difficulty INTEGER DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS quizzes (
+	id SERIAL PRIMARY KEY,
+	user_id BIGINT REFERENCES users(id),
+	word_id INTEGER REFERENCES words(id),
+	correct BOOLEAN NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
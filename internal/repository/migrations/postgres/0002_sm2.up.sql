@@ -0,0 +1,11 @@
+-- Параметры алгоритма SM-2 для каждого слова
+ALTER TABLE words ADD COLUMN ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5;
+ALTER TABLE words ADD COLUMN repetitions INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE words ADD COLUMN lapses INTEGER NOT NULL DEFAULT 0;
+
+-- Стартовые значения для существующих слов: каждая единица старой
+-- сложности (0–5) снижает коэффициент легкости, а слова с интервалом
+-- больше дня считаются уже дважды повторенными
+UPDATE words SET
+	ease_factor = GREATEST(1.3, 2.5 - 0.2 * COALESCE(difficulty, 0)),
+	repetitions = CASE WHEN interval > 1 THEN 2 ELSE 0 END;
@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN scheduler;
+ALTER TABLE words DROP COLUMN retrievability;
+ALTER TABLE words DROP COLUMN fsrs_difficulty;
+ALTER TABLE words DROP COLUMN stability;
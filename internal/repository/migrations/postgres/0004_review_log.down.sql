@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS quizzes_word_id_idx;
+DROP INDEX IF EXISTS quizzes_user_id_created_at_idx;
+
+ALTER TABLE quizzes DROP CONSTRAINT IF EXISTS quizzes_word_id_fkey;
+ALTER TABLE quizzes ADD CONSTRAINT quizzes_word_id_fkey
+	FOREIGN KEY (word_id) REFERENCES words(id);
+
+ALTER TABLE quizzes DROP COLUMN latency_ms;
+ALTER TABLE quizzes DROP COLUMN interval_after;
+ALTER TABLE quizzes DROP COLUMN interval_before;
+ALTER TABLE quizzes DROP COLUMN chosen_option;
@@ -0,0 +1,14 @@
+-- Журнал ответов: каждая строка quizzes — один ответ пользователя
+-- с выбранным вариантом, интервалом до и после и временем ответа.
+ALTER TABLE quizzes ADD COLUMN chosen_option TEXT NOT NULL DEFAULT '';
+ALTER TABLE quizzes ADD COLUMN interval_before INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quizzes ADD COLUMN interval_after INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quizzes ADD COLUMN latency_ms BIGINT NOT NULL DEFAULT 0;
+
+-- Журнал удаляется вместе со словом, иначе слово с историей нельзя удалить
+ALTER TABLE quizzes DROP CONSTRAINT IF EXISTS quizzes_word_id_fkey;
+ALTER TABLE quizzes ADD CONSTRAINT quizzes_word_id_fkey
+	FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE;
+
+CREATE INDEX quizzes_user_id_created_at_idx ON quizzes (user_id, created_at);
+CREATE INDEX quizzes_word_id_idx ON quizzes (word_id);
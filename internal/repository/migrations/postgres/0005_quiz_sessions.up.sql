@@ -0,0 +1,16 @@
+-- Вопросы теста хранятся на сервере, а в callback data передается
+-- только непрозрачный идентификатор сессии и номер выбранного варианта.
+CREATE TABLE quiz_sessions (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES users(id),
+	word_id INTEGER NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+	question TEXT NOT NULL,
+	options TEXT NOT NULL,
+	correct_idx INTEGER NOT NULL,
+	chosen_idx INTEGER,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	answered_at TIMESTAMP
+);
+
+CREATE INDEX quiz_sessions_expires_at_idx ON quiz_sessions (expires_at);
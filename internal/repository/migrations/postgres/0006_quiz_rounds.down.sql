@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS quiz_sessions_round_id_idx;
+ALTER TABLE quiz_sessions DROP COLUMN next_review_after;
+ALTER TABLE quiz_sessions DROP COLUMN next_review_before;
+ALTER TABLE quiz_sessions DROP COLUMN position;
+ALTER TABLE quiz_sessions DROP COLUMN round_id;
+
+DROP TABLE quiz_rounds;
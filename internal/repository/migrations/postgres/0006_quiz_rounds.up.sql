@@ -0,0 +1,21 @@
+-- Раунд теста из нескольких вопросов. Вопросы раунда — сессии quiz_sessions
+-- с общим round_id; по ним строится итог раунда.
+CREATE TABLE quiz_rounds (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES users(id),
+	size INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	finished_at TIMESTAMP,
+	aborted BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX quiz_rounds_expires_at_idx ON quiz_rounds (expires_at);
+
+ALTER TABLE quiz_sessions ADD COLUMN round_id VARCHAR(64) REFERENCES quiz_rounds(id) ON DELETE CASCADE;
+ALTER TABLE quiz_sessions ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+-- Дата следующего повторения слова до и после ответа, для итога раунда
+ALTER TABLE quiz_sessions ADD COLUMN next_review_before TIMESTAMP;
+ALTER TABLE quiz_sessions ADD COLUMN next_review_after TIMESTAMP;
+
+CREATE INDEX quiz_sessions_round_id_idx ON quiz_sessions (round_id);
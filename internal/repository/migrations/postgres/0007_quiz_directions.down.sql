@@ -0,0 +1,5 @@
+ALTER TABLE quiz_rounds DROP COLUMN mode;
+ALTER TABLE quiz_sessions DROP COLUMN direction;
+ALTER TABLE quizzes DROP COLUMN direction;
+ALTER TABLE users DROP COLUMN quiz_mode;
+DROP TABLE reverse_reviews;
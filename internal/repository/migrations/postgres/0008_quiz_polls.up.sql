@@ -0,0 +1,7 @@
+-- Как показывать вопросы /quiz: кнопками под сообщением или опросом Telegram
+ALTER TABLE users ADD COLUMN quiz_presentation VARCHAR(20) NOT NULL DEFAULT 'buttons';
+
+-- Опрос Telegram, в котором задан вопрос. Ответ на опрос приходит
+-- отдельным обновлением poll_answer только с ID опроса.
+ALTER TABLE quiz_sessions ADD COLUMN poll_id VARCHAR(64);
+CREATE UNIQUE INDEX quiz_sessions_poll_id_idx ON quiz_sessions (poll_id);
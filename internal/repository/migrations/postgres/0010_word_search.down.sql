@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS words_search_idx;
+DROP INDEX IF EXISTS words_translation_trgm_idx;
+DROP INDEX IF EXISTS words_word_trgm_idx;
+
+-- Расширение pg_trgm не удаляется: им могут пользоваться другие базы и схемы
@@ -0,0 +1,8 @@
+-- Поиск /find: триграммы для нечеткого совпадения и подстрок,
+-- полнотекстовый индекс по слову, переводу и контексту
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX words_word_trgm_idx ON words USING GIN (word gin_trgm_ops);
+CREATE INDEX words_translation_trgm_idx ON words USING GIN (translation gin_trgm_ops);
+CREATE INDEX words_search_idx ON words
+	USING GIN (to_tsvector('simple', word || ' ' || translation || ' ' || COALESCE(context, '')));
@@ -0,0 +1,3 @@
+ALTER TABLE quiz_rounds DROP COLUMN tag_id;
+DROP TABLE word_tags;
+DROP TABLE tags;
@@ -0,0 +1,29 @@
+-- Колоды: именованные наборы слов пользователя со своей языковой парой
+-- и настройками повторения. С активной колодой работают /add, /quiz и /review.
+CREATE TABLE decks (
+	id SERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES users(id),
+	name VARCHAR(64) NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	source_lang VARCHAR(8) NOT NULL DEFAULT 'en',
+	target_lang VARCHAR(8) NOT NULL DEFAULT 'ru',
+	-- Сколько новых слов в день показывать в /review
+	new_per_day INTEGER NOT NULL DEFAULT 20,
+	-- Алгоритм повторения колоды; пустая строка — алгоритм из /settings
+	scheduler VARCHAR(16) NOT NULL DEFAULT '',
+	active BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (user_id, name)
+);
+
+-- Активная колода у пользователя одна
+CREATE UNIQUE INDEX decks_active_idx ON decks (user_id) WHERE active;
+
+-- Колода слова; 0 — слово без колоды. Слова колоды удаляются вместе с ней
+-- в DeleteDeck, поэтому внешнего ключа нет.
+ALTER TABLE words ADD COLUMN deck_id INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX words_deck_id_idx ON words (deck_id);
+
+-- Все слова существующих пользователей попадают в основную колоду
+INSERT INTO decks (user_id, name, active) SELECT id, 'Основная', TRUE FROM users;
+UPDATE words SET deck_id = COALESCE((SELECT decks.id FROM decks WHERE decks.user_id = words.user_id), 0);
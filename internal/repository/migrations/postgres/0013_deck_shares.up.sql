@@ -0,0 +1,7 @@
+-- Ссылки на колоды: по t.me/<бот>?start=deck_<код> другой пользователь
+-- импортирует копию колоды. Пустой код — колода не опубликована.
+ALTER TABLE decks ADD COLUMN share_code VARCHAR(16) NOT NULL DEFAULT '';
+-- Сколько раз колоду импортировали по ссылке
+ALTER TABLE decks ADD COLUMN imports INTEGER NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX decks_share_code_idx ON decks (share_code) WHERE share_code <> '';
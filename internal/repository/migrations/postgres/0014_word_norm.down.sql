@@ -0,0 +1,2 @@
+DROP INDEX words_user_word_norm_idx;
+ALTER TABLE words DROP COLUMN word_norm;
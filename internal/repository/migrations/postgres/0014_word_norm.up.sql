@@ -0,0 +1,8 @@
+-- Нормализованное написание слова (см. textutil.NormalizeWord): по нему бот
+-- ищет дубликаты. Правило нормализации реализовано в Go (lower() в SQLite меняет
+-- регистр только латиницы), поэтому уже сохраненные слова получают word_norm
+-- при запуске бота. Дубликаты, добавленные раньше, не объединяются: это
+-- решает пользователь в /duplicates.
+ALTER TABLE words ADD COLUMN word_norm VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX words_user_word_norm_idx ON words (user_id, word_norm);
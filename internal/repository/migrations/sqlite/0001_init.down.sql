@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS quizzes;
+DROP TABLE IF EXISTS words;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,31 @@
+-- Начальная схема для SQLite. Столбцы дат объявлены как TIMESTAMP,
+-- чтобы драйвер возвращал их как time.Time.
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY,
+	username VARCHAR(255),
+	first_name VARCHAR(255),
+	last_name VARCHAR(255),
+	state VARCHAR(50) DEFAULT 'idle',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS words (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER REFERENCES users(id),
+	word VARCHAR(255) NOT NULL,
+	translation VARCHAR(255) NOT NULL,
+	context TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	last_review TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	next_review TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	interval INTEGER DEFAULT 1,
+	difficulty INTEGER DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS quizzes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER REFERENCES users(id),
+	word_id INTEGER REFERENCES words(id),
+	correct BOOLEAN NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
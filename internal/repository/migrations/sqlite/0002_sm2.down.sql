@@ -0,0 +1,3 @@
+ALTER TABLE words DROP COLUMN lapses;
+ALTER TABLE words DROP COLUMN repetitions;
+ALTER TABLE words DROP COLUMN ease_factor;
@@ -0,0 +1,8 @@
+-- Состояние FSRS для каждого слова. Нулевая стабильность означает,
+-- что слово еще не повторялось по FSRS.
+ALTER TABLE words ADD COLUMN stability REAL NOT NULL DEFAULT 0;
+ALTER TABLE words ADD COLUMN fsrs_difficulty REAL NOT NULL DEFAULT 0;
+ALTER TABLE words ADD COLUMN retrievability REAL NOT NULL DEFAULT 0;
+
+-- Алгоритм повторения, выбранный пользователем в /settings
+ALTER TABLE users ADD COLUMN scheduler VARCHAR(20) NOT NULL DEFAULT 'sm2';
@@ -0,0 +1,13 @@
+CREATE TABLE quizzes_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER REFERENCES users(id),
+	word_id INTEGER REFERENCES words(id),
+	correct BOOLEAN NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO quizzes_old (id, user_id, word_id, correct, created_at)
+SELECT id, user_id, word_id, correct, created_at FROM quizzes;
+
+DROP TABLE quizzes;
+ALTER TABLE quizzes_old RENAME TO quizzes;
@@ -0,0 +1,24 @@
+-- Журнал ответов: каждая строка quizzes — один ответ пользователя
+-- с выбранным вариантом, интервалом до и после и временем ответа.
+-- SQLite не умеет менять внешние ключи, поэтому таблица пересоздается:
+-- журнал удаляется вместе со словом.
+CREATE TABLE quizzes_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER REFERENCES users(id),
+	word_id INTEGER REFERENCES words(id) ON DELETE CASCADE,
+	correct BOOLEAN NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	chosen_option TEXT NOT NULL DEFAULT '',
+	interval_before INTEGER NOT NULL DEFAULT 0,
+	interval_after INTEGER NOT NULL DEFAULT 0,
+	latency_ms INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO quizzes_new (id, user_id, word_id, correct, created_at)
+SELECT id, user_id, word_id, correct, created_at FROM quizzes;
+
+DROP TABLE quizzes;
+ALTER TABLE quizzes_new RENAME TO quizzes;
+
+CREATE INDEX quizzes_user_id_created_at_idx ON quizzes (user_id, created_at);
+CREATE INDEX quizzes_word_id_idx ON quizzes (word_id);
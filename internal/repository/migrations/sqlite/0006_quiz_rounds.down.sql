@@ -0,0 +1,25 @@
+-- SQLite не удаляет столбцы с внешним ключом, поэтому таблица пересоздается
+CREATE TABLE quiz_sessions_old (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users(id),
+	word_id INTEGER NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+	question TEXT NOT NULL,
+	options TEXT NOT NULL,
+	correct_idx INTEGER NOT NULL,
+	chosen_idx INTEGER,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	answered_at TIMESTAMP
+);
+
+INSERT INTO quiz_sessions_old (id, user_id, word_id, question, options, correct_idx, chosen_idx,
+	created_at, expires_at, answered_at)
+SELECT id, user_id, word_id, question, options, correct_idx, chosen_idx, created_at, expires_at, answered_at
+FROM quiz_sessions;
+
+DROP TABLE quiz_sessions;
+ALTER TABLE quiz_sessions_old RENAME TO quiz_sessions;
+
+CREATE INDEX quiz_sessions_expires_at_idx ON quiz_sessions (expires_at);
+
+DROP TABLE quiz_rounds;
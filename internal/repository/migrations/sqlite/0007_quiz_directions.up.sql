@@ -0,0 +1,25 @@
+-- Состояние повторения слова в обратном направлении (перевод → слово).
+-- Узнать слово и вспомнить его — разные навыки, поэтому расписания у них
+-- независимые. Прямое направление по-прежнему хранится в words, а строка
+-- здесь появляется после первого ответа в обратном направлении.
+CREATE TABLE reverse_reviews (
+	word_id INTEGER PRIMARY KEY REFERENCES words(id) ON DELETE CASCADE,
+	last_review TIMESTAMP NOT NULL,
+	next_review TIMESTAMP NOT NULL,
+	interval INTEGER NOT NULL DEFAULT 1,
+	difficulty INTEGER NOT NULL DEFAULT 0,
+	ease_factor REAL NOT NULL DEFAULT 2.5,
+	repetitions INTEGER NOT NULL DEFAULT 0,
+	lapses INTEGER NOT NULL DEFAULT 0,
+	stability REAL NOT NULL DEFAULT 0,
+	fsrs_difficulty REAL NOT NULL DEFAULT 0,
+	retrievability REAL NOT NULL DEFAULT 0
+);
+
+-- Режим /quiz, выбранный пользователем в /settings: forward, reverse или mixed
+ALTER TABLE users ADD COLUMN quiz_mode VARCHAR(20) NOT NULL DEFAULT 'forward';
+
+-- Направление каждого ответа и вопроса, режим раунда
+ALTER TABLE quizzes ADD COLUMN direction VARCHAR(20) NOT NULL DEFAULT 'forward';
+ALTER TABLE quiz_sessions ADD COLUMN direction VARCHAR(20) NOT NULL DEFAULT 'forward';
+ALTER TABLE quiz_rounds ADD COLUMN mode VARCHAR(20) NOT NULL DEFAULT 'forward';
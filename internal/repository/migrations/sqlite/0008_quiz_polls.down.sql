@@ -0,0 +1,3 @@
+DROP INDEX quiz_sessions_poll_id_idx;
+ALTER TABLE quiz_sessions DROP COLUMN poll_id;
+ALTER TABLE users DROP COLUMN quiz_presentation;
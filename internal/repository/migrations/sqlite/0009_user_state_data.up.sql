@@ -0,0 +1,3 @@
+-- Данные текущего состояния пользователя, например черновик слова
+-- в пошаговом /add. В state помещается только короткое имя шага.
+ALTER TABLE users ADD COLUMN state_data TEXT NOT NULL DEFAULT '';
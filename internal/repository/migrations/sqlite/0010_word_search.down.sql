@@ -0,0 +1 @@
+DROP INDEX IF EXISTS words_user_id_idx;
@@ -0,0 +1,3 @@
+-- В SQLite нет триграммных индексов: /find сравнивает слова пользователя в Go,
+-- поэтому достаточно индекса по владельцу, чтобы быстро выбрать его слова
+CREATE INDEX words_user_id_idx ON words (user_id);
@@ -0,0 +1,21 @@
+-- Теги пользователя для группировки слов по темам («travel», «work»).
+-- Имя тега хранится нормализованным, поэтому у пользователя оно уникально.
+CREATE TABLE tags (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL REFERENCES users(id),
+	name VARCHAR(64) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (user_id, name)
+);
+
+-- Связь слов и тегов: у слова может быть несколько тегов, у тега — много слов
+CREATE TABLE word_tags (
+	word_id INTEGER NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+	tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (word_id, tag_id)
+);
+
+CREATE INDEX word_tags_tag_id_idx ON word_tags (tag_id);
+
+-- Тег, по словам которого идет раунд /quiz; 0 — все слова
+ALTER TABLE quiz_rounds ADD COLUMN tag_id INTEGER NOT NULL DEFAULT 0;
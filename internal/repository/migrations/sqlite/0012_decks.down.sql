@@ -0,0 +1,3 @@
+DROP INDEX words_deck_id_idx;
+ALTER TABLE words DROP COLUMN deck_id;
+DROP TABLE decks;
@@ -0,0 +1,3 @@
+DROP INDEX decks_share_code_idx;
+ALTER TABLE decks DROP COLUMN imports;
+ALTER TABLE decks DROP COLUMN share_code;
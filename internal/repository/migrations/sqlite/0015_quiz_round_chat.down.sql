@@ -0,0 +1 @@
+ALTER TABLE quiz_rounds DROP COLUMN chat_id;
@@ -0,0 +1,4 @@
+-- Чат, в котором идет раунд: туда бот присылает следующий вопрос и итог,
+-- когда ответ пришел опросом (в обновлении poll_answer чата нет). 0 — раунд
+-- начат до этой миграции, тогда вопросы идут в личный чат пользователя.
+ALTER TABLE quiz_rounds ADD COLUMN chat_id INTEGER NOT NULL DEFAULT 0;
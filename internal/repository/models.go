@@ -4,14 +4,41 @@ import "time"
 
 // User представляет пользователя бота
 type User struct {
-	ID        int64     `json:"id"`
-	Username  string    `json:"username"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	State     string    `json:"state"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	State     string `json:"state"`
+	StateData string `json:"state_data"` // Данные состояния, например черновик слова в /add
+	Scheduler string `json:"scheduler"`  // Алгоритм повторения: sm2 или fsrs
+	QuizMode  string `json:"quiz_mode"`  // Режим /quiz: forward, reverse или mixed
+	// Как показывать вопросы /quiz: buttons или poll
+	QuizPresentation string    `json:"quiz_presentation"`
+	CreatedAt        time.Time `json:"created_at"`
 }
 
+// Алгоритмы интервального повторения, которые пользователь выбирает в /settings
+const (
+	SchedulerSM2  = "sm2"
+	SchedulerFSRS = "fsrs"
+)
+
+// Направления вопроса теста. У каждого направления свое расписание повторений.
+const (
+	DirectionForward = "forward" // Слово → перевод
+	DirectionReverse = "reverse" // Перевод → слово
+)
+
+// QuizModeMixed — режим /quiz, в котором направления вопросов чередуются.
+// Остальные режимы совпадают с названиями направлений.
+const QuizModeMixed = "mixed"
+
+// Способы показа вопросов /quiz
+const (
+	PresentationButtons = "buttons" // Сообщение с кнопками вариантов
+	PresentationPoll    = "poll"    // Опрос Telegram в режиме викторины
+)
+
 // Word представляет слово для изучения
 type Word struct {
 	ID          int       `json:"id"`
@@ -19,18 +46,149 @@ type Word struct {
 	Word        string    `json:"word"`
 	Translation string    `json:"translation"`
 	Context     string    `json:"context"`
+	DeckID      int       `json:"deck_id"` // Колода слова; 0 — слово без колоды
 	CreatedAt   time.Time `json:"created_at"`
+	// Имена тегов слова. SaveWord сохраняет их вместе со словом, а при чтении
+	// они заполняются только там, где их загружают явно (GetWordTags).
+	Tags []string `json:"tags,omitempty"`
+	ReviewState
+}
+
+// Tag — тег, которым пользователь группирует слова по темам
+type Tag struct {
+	ID     int    `json:"id"`
+	UserID int64  `json:"user_id"`
+	Name   string `json:"name"`  // Нормализованное имя без «#»
+	Words  int    `json:"words"` // Сколько слов с этим тегом
+}
+
+// Deck — колода: именованный набор слов пользователя со своей языковой парой
+// и настройками повторения
+type Deck struct {
+	ID          int    `json:"id"`
+	UserID      int64  `json:"user_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	SourceLang  string `json:"source_lang"` // Язык слов, например en
+	TargetLang  string `json:"target_lang"` // Язык переводов, например ru
+	NewPerDay   int    `json:"new_per_day"` // Сколько новых слов в день показывать в /review
+	// Алгоритм повторения слов колоды; пустая строка — алгоритм пользователя из /settings
+	Scheduler string `json:"scheduler"`
+	Active    bool   `json:"active"` // С активной колодой работают /add, /quiz и /review
+	Words     int    `json:"words"`  // Сколько слов в колоде
+	// Код ссылки t.me/<бот>?start=deck_<код>; пустая строка — колода не опубликована
+	ShareCode string    `json:"share_code"`
+	Imports   int       `json:"imports"` // Сколько раз колоду импортировали по ссылке
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReviewState содержит параметры интервального повторения слова.
+// Репозиторий только хранит их, а вычисляет service.Scheduler.
+type ReviewState struct {
 	LastReview  time.Time `json:"last_review"`
 	NextReview  time.Time `json:"next_review"`
-	Interval    int       `json:"interval"`   // Интервал в днях для повторения
-	Difficulty  int       `json:"difficulty"` // Сложность слова (0-5)
+	Interval    int       `json:"interval"`    // Интервал в днях для повторения
+	Difficulty  int       `json:"difficulty"`  // Сложность слова (0-5)
+	EaseFactor  float64   `json:"ease_factor"` // Коэффициент легкости SM-2
+	Repetitions int       `json:"repetitions"` // Успешных повторений подряд
+	Lapses      int       `json:"lapses"`      // Сколько раз выученное слово было забыто
+
+	// Состояние FSRS
+	Stability      float64 `json:"stability"`       // Стабильность памяти в днях
+	FSRSDifficulty float64 `json:"fsrs_difficulty"` // Сложность FSRS (1-10)
+	Retrievability float64 `json:"retrievability"`  // Вероятность вспомнить в момент последнего повторения
+}
+
+// Порядок списка слов в /words
+const (
+	WordSortRecent  = "recent" // Сначала недавно добавленные
+	WordSortAlpha   = "alpha"  // По алфавиту
+	WordSortHardest = "hard"   // Сначала чаще забываемые и трудные
+	WordSortDue     = "due"    // Сначала те, что пора повторять
+)
+
+// WordListQuery описывает страницу списка слов. Страницы выбираются по ключу
+// сортировки (keyset), а не по смещению: FromID — первое слово страницы,
+// 0 — начало списка. Если Backward, выбираются слова перед FromID. FromID,
+// которого нет среди слов пользователя, означает первую страницу.
+type WordListQuery struct {
+	UserID   int64
+	Sort     string
+	FromID   int
+	Backward bool
+	Limit    int       // 0 — без ограничения
+	TagID    int       // Только слова с этим тегом; 0 — все слова
+	DeckID   int       // Только слова этой колоды; 0 — все слова
+	DueBy    time.Time // Только слова, которые пора повторить к этому времени; нулевое время — все слова
 }
 
-// Quiz представляет тест
+// Quiz представляет один ответ в тесте. Таблица quizzes служит журналом
+// повторений: по ней строится статистика и подбираются параметры алгоритмов.
 type Quiz struct {
-	ID        int       `json:"id"`
-	UserID    int64     `json:"user_id"`
-	WordID    int       `json:"word_id"`
-	Correct   bool      `json:"correct"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             int       `json:"id"`
+	UserID         int64     `json:"user_id"`
+	WordID         int       `json:"word_id"`
+	ChosenOption   string    `json:"chosen_option"` // Вариант, который выбрал пользователь
+	Direction      string    `json:"direction"`     // Направление вопроса
+	Correct        bool      `json:"correct"`
+	IntervalBefore int       `json:"interval_before"` // Интервал слова до ответа, в днях
+	IntervalAfter  int       `json:"interval_after"`  // Интервал слова после ответа, в днях
+	LatencyMs      int64     `json:"latency_ms"`      // Время от вопроса до ответа
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// QuizSession хранит заданный вопрос теста на сервере, чтобы правильный ответ
+// не попадал в callback data и не мог быть подделан клиентом
+type QuizSession struct {
+	ID         string    `json:"id"` // Непрозрачный случайный идентификатор
+	UserID     int64     `json:"user_id"`
+	WordID     int       `json:"word_id"`
+	Question   string    `json:"question"`
+	Options    []string  `json:"options"`
+	CorrectIdx int       `json:"correct_idx"`
+	ChosenIdx  int       `json:"chosen_idx"` // Выбранный вариант; имеет смысл, только если Answered
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	AnsweredAt time.Time `json:"answered_at"` // Нулевое время — ответа еще не было
+
+	// Раунд, к которому относится вопрос, и номер вопроса в нем (с нуля)
+	RoundID  string `json:"round_id"`
+	Position int    `json:"position"`
+	// Направление вопроса: какое расписание слова обновит ответ
+	Direction string `json:"direction"`
+	// Дата следующего повторения слова до и после ответа
+	NextReviewBefore time.Time `json:"next_review_before"`
+	NextReviewAfter  time.Time `json:"next_review_after"`
+	// ID опроса Telegram, если вопрос задан опросом
+	PollID string `json:"poll_id"`
+}
+
+// Answered сообщает, был ли уже дан ответ
+func (s *QuizSession) Answered() bool {
+	return !s.AnsweredAt.IsZero()
+}
+
+// QuizRound — раунд теста из нескольких вопросов подряд
+type QuizRound struct {
+	ID         string    `json:"id"`
+	UserID     int64     `json:"user_id"`
+	Size       int       `json:"size"` // Сколько вопросов должно быть в раунде
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	FinishedAt time.Time `json:"finished_at"` // Нулевое время — раунд еще идет
+	Aborted    bool      `json:"aborted"`     // Пользователь прервал раунд досрочно
+	Mode       string    `json:"mode"`        // Режим раунда: forward, reverse или mixed
+	TagID      int       `json:"tag_id"`      // Вопросы только по словам с этим тегом; 0 — по всем
+	ChatID     int64     `json:"chat_id"`     // Чат, где идет раунд; 0 — раунд начат до миграции 0015
+}
+
+// Finished сообщает, завершен ли раунд
+func (r *QuizRound) Finished() bool {
+	return !r.FinishedAt.IsZero()
+}
+
+// IsNew сообщает, что слово еще ни разу не повторялось: при добавлении
+// last_review совпадает с created_at, а любое повторение сдвигает его вперед
+func (w *Word) IsNew() bool {
+	return !w.LastReview.After(w.CreatedAt)
 }
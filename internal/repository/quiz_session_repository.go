@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// QuizSessionRepository хранит сессии и раунды тестов в таблицах quiz_sessions и quiz_rounds
+type QuizSessionRepository struct {
+	db *Database
+}
+
+// NewQuizSessionRepository создает репозиторий сессий тестов
+func NewQuizSessionRepository(database *Database) *QuizSessionRepository {
+	return &QuizSessionRepository{db: database}
+}
+
+// CreateQuizSession сохраняет новую сессию теста
+func (r *QuizSessionRepository) CreateQuizSession(session *QuizSession) error {
+	options, err := json.Marshal(session.Options)
+	if err != nil {
+		return fmt.Errorf("failed to encode quiz options: %w", err)
+	}
+
+	query := `
+		INSERT INTO quiz_sessions (id, user_id, word_id, question, options, correct_idx, created_at, expires_at,
+			round_id, position, direction)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	var roundID sql.NullString
+	if session.RoundID != "" {
+		roundID = sql.NullString{String: session.RoundID, Valid: true}
+	}
+
+	_, err = r.db.Exec(query, session.ID, session.UserID, session.WordID, session.Question, string(options),
+		session.CorrectIdx, session.CreatedAt, session.ExpiresAt, roundID, session.Position, session.Direction)
+	if err != nil {
+		return fmt.Errorf("failed to create quiz session: %w", err)
+	}
+
+	return nil
+}
+
+// sessionColumns перечисляет столбцы, которые читает scanSessions
+const sessionColumns = `id, user_id, word_id, question, options, correct_idx, chosen_idx, created_at, expires_at,
+	answered_at, COALESCE(round_id, ''), position, next_review_before, next_review_after, direction,
+	COALESCE(poll_id, '')`
+
+// GetQuizSession получает сессию теста по ID
+func (r *QuizSessionRepository) GetQuizSession(sessionID string) (*QuizSession, error) {
+	rows, err := r.db.Query(`SELECT `+sessionColumns+` FROM quiz_sessions WHERE id = $1`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiz session: %w", err)
+	}
+	defer rows.Close()
+
+	sessions, err := scanSessions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, nil // Сессия не найдена
+	}
+
+	return sessions[0], nil
+}
+
+// GetQuizSessionByPoll получает сессию теста по ID опроса Telegram
+func (r *QuizSessionRepository) GetQuizSessionByPoll(pollID string) (*QuizSession, error) {
+	rows, err := r.db.Query(`SELECT `+sessionColumns+` FROM quiz_sessions WHERE poll_id = $1`, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiz session by poll: %w", err)
+	}
+	defer rows.Close()
+
+	sessions, err := scanSessions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, nil // Сессия не найдена
+	}
+
+	return sessions[0], nil
+}
+
+// SetQuizSessionPoll запоминает опрос Telegram, в котором задан вопрос сессии
+func (r *QuizSessionRepository) SetQuizSessionPoll(sessionID, pollID string) error {
+	_, err := r.db.Exec(`UPDATE quiz_sessions SET poll_id = $1 WHERE id = $2`, pollID, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to set quiz session poll: %w", err)
+	}
+
+	return nil
+}
+
+// GetRoundSessions возвращает вопросы раунда в порядке их показа
+func (r *QuizSessionRepository) GetRoundSessions(roundID string) ([]*QuizSession, error) {
+	query := `SELECT ` + sessionColumns + `
+		FROM quiz_sessions WHERE round_id = $1 ORDER BY position ASC
+	`
+
+	rows, err := r.db.Query(query, roundID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get round sessions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSessions(rows)
+}
+
+// MarkQuizSessionAnswered отмечает ответ на сессию. Возвращает false, если ответ
+// уже был записан раньше — так повторное нажатие кнопки не засчитывается дважды.
+func (r *QuizSessionRepository) MarkQuizSessionAnswered(sessionID string, chosenIdx int,
+	answeredAt time.Time) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE quiz_sessions SET chosen_idx = $1, answered_at = $2
+		WHERE id = $3 AND answered_at IS NULL
+	`, chosenIdx, answeredAt, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark quiz session answered: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark quiz session answered: %w", err)
+	}
+
+	return rows == 1, nil
+}
+
+// ClearQuizSessionAnswer снимает отметку ответа, чтобы на вопрос можно было ответить
+// снова, — если ответ не удалось записать
+func (r *QuizSessionRepository) ClearQuizSessionAnswer(sessionID string) error {
+	_, err := r.db.Exec(`UPDATE quiz_sessions SET chosen_idx = NULL, answered_at = NULL WHERE id = $1`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to clear quiz session answer: %w", err)
+	}
+	return nil
+}
+
+// SaveQuizSessionReview запоминает, как ответ сдвинул дату следующего повторения слова
+func (r *QuizSessionRepository) SaveQuizSessionReview(sessionID string,
+	nextReviewBefore, nextReviewAfter time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE quiz_sessions SET next_review_before = $1, next_review_after = $2 WHERE id = $3
+	`, nextReviewBefore, nextReviewAfter, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to save quiz session review: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredQuizSessions удаляет сессии и раунды, срок которых истек до указанного времени
+func (r *QuizSessionRepository) DeleteExpiredQuizSessions(before time.Time) error {
+	_, err := r.db.Exec(`DELETE FROM quiz_sessions WHERE expires_at < $1`, before)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired quiz sessions: %w", err)
+	}
+
+	_, err = r.db.Exec(`DELETE FROM quiz_rounds WHERE expires_at < $1`, before)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired quiz rounds: %w", err)
+	}
+
+	return nil
+}
+
+// CreateQuizRound сохраняет новый раунд теста
+func (r *QuizSessionRepository) CreateQuizRound(round *QuizRound) error {
+	_, err := r.db.Exec(`
+		INSERT INTO quiz_rounds (id, user_id, size, created_at, expires_at, mode, tag_id, chat_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, round.ID, round.UserID, round.Size, round.CreatedAt, round.ExpiresAt, round.Mode, round.TagID, round.ChatID)
+	if err != nil {
+		return fmt.Errorf("failed to create quiz round: %w", err)
+	}
+
+	return nil
+}
+
+// GetQuizRound получает раунд теста по ID
+func (r *QuizSessionRepository) GetQuizRound(roundID string) (*QuizRound, error) {
+	query := `
+		SELECT id, user_id, size, created_at, expires_at, finished_at, aborted, mode, tag_id, chat_id
+		FROM quiz_rounds WHERE id = $1
+	`
+
+	round := &QuizRound{}
+	var finishedAt sql.NullTime
+	err := r.db.QueryRow(query, roundID).Scan(&round.ID, &round.UserID, &round.Size, &round.CreatedAt,
+		&round.ExpiresAt, &finishedAt, &round.Aborted, &round.Mode, &round.TagID, &round.ChatID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Раунд не найден
+		}
+		return nil, fmt.Errorf("failed to get quiz round: %w", err)
+	}
+	round.FinishedAt = finishedAt.Time
+
+	return round, nil
+}
+
+// FinishQuizRound завершает раунд. Возвращает false, если раунд уже был завершен.
+func (r *QuizSessionRepository) FinishQuizRound(roundID string, aborted bool, finishedAt time.Time) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE quiz_rounds SET finished_at = $1, aborted = $2
+		WHERE id = $3 AND finished_at IS NULL
+	`, finishedAt, aborted, roundID)
+	if err != nil {
+		return false, fmt.Errorf("failed to finish quiz round: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to finish quiz round: %w", err)
+	}
+
+	return rows == 1, nil
+}
+
+// scanSessions читает строки, выбранные со столбцами sessionColumns
+func scanSessions(rows *sql.Rows) ([]*QuizSession, error) {
+	var sessions []*QuizSession
+	for rows.Next() {
+		session := &QuizSession{}
+		var options string
+		var chosenIdx sql.NullInt64
+		var answeredAt, nextReviewBefore, nextReviewAfter sql.NullTime
+		err := rows.Scan(&session.ID, &session.UserID, &session.WordID, &session.Question, &options,
+			&session.CorrectIdx, &chosenIdx, &session.CreatedAt, &session.ExpiresAt, &answeredAt,
+			&session.RoundID, &session.Position, &nextReviewBefore, &nextReviewAfter, &session.Direction,
+			&session.PollID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quiz session: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(options), &session.Options); err != nil {
+			return nil, fmt.Errorf("failed to decode quiz options: %w", err)
+		}
+		session.ChosenIdx = int(chosenIdx.Int64)
+		session.AnsweredAt = answeredAt.Time
+		session.NextReviewBefore = nextReviewBefore.Time
+		session.NextReviewAfter = nextReviewAfter.Time
+
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read quiz sessions: %w", err)
+	}
+
+	return sessions, nil
+}
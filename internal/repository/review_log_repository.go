@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ReviewLogRepository хранит журнал ответов в таблице quizzes
+type ReviewLogRepository struct {
+	db *Database
+}
+
+// NewReviewLogRepository создает репозиторий журнала ответов
+func NewReviewLogRepository(database *Database) *ReviewLogRepository {
+	return &ReviewLogRepository{db: database}
+}
+
+// SaveQuiz записывает ответ в журнал
+func (r *ReviewLogRepository) SaveQuiz(quiz *Quiz) error {
+	query := `
+		INSERT INTO quizzes (user_id, word_id, chosen_option, correct, interval_before, interval_after, latency_ms,
+			direction)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(query, quiz.UserID, quiz.WordID, quiz.ChosenOption, quiz.Correct,
+		quiz.IntervalBefore, quiz.IntervalAfter, quiz.LatencyMs, quiz.Direction).
+		Scan(&quiz.ID, &quiz.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save quiz: %w", err)
+	}
+
+	return nil
+}
+
+// quizColumns перечисляет столбцы, которые читает scanQuizzes
+const quizColumns = `id, user_id, word_id, chosen_option, correct, interval_before, interval_after, latency_ms,
+	created_at, direction`
+
+// GetUserQuizzes возвращает последние ответы пользователя, начиная с новых
+func (r *ReviewLogRepository) GetUserQuizzes(userID int64, limit int) ([]*Quiz, error) {
+	query := `SELECT ` + quizColumns + `
+		FROM quizzes WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2
+	`
+
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user quizzes: %w", err)
+	}
+	defer rows.Close()
+
+	return scanQuizzes(rows)
+}
+
+// GetWordQuizzes возвращает всю историю ответов по слову в хронологическом порядке
+func (r *ReviewLogRepository) GetWordQuizzes(wordID int) ([]*Quiz, error) {
+	query := `SELECT ` + quizColumns + `
+		FROM quizzes WHERE word_id = $1 ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query, wordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get word quizzes: %w", err)
+	}
+	defer rows.Close()
+
+	return scanQuizzes(rows)
+}
+
+// scanQuizzes читает строки, выбранные со столбцами quizColumns
+func scanQuizzes(rows *sql.Rows) ([]*Quiz, error) {
+	var quizzes []*Quiz
+	for rows.Next() {
+		quiz := &Quiz{}
+		err := rows.Scan(&quiz.ID, &quiz.UserID, &quiz.WordID, &quiz.ChosenOption, &quiz.Correct,
+			&quiz.IntervalBefore, &quiz.IntervalAfter, &quiz.LatencyMs, &quiz.CreatedAt, &quiz.Direction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quiz: %w", err)
+		}
+		quizzes = append(quizzes, quiz)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read quizzes: %w", err)
+	}
+
+	return quizzes, nil
+}
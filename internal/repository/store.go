@@ -0,0 +1,94 @@
+package repository
+
+import "time"
+
+// UserStore описывает хранилище пользователей
+type UserStore interface {
+	CreateOrUpdateUser(user *User) error
+	GetUser(userID int64) (*User, error)
+	UpdateUserState(userID int64, state string) error
+	UpdateUserStateData(userID int64, state, data string) error
+	UpdateUserScheduler(userID int64, scheduler string) error
+	UpdateUserQuizMode(userID int64, mode string) error
+	UpdateUserQuizPresentation(userID int64, presentation string) error
+}
+
+// WordStore описывает хранилище слов
+type WordStore interface {
+	SaveWord(word *Word) error
+	SaveWords(words []*Word) error
+	GetUserWords(userID int64) ([]*Word, error)
+	ListWords(q WordListQuery) ([]*Word, error)
+	CountWords(q WordListQuery) (int, error)
+	SearchWords(userID int64, query string, limit int) ([]*Word, error)
+	GetWordsForReview(userID int64) ([]*Word, error)
+	GetWord(wordID int) (*Word, error)
+	GetWordByNorm(userID int64, norm string) (*Word, error)
+	SaveReviewState(wordID int, state ReviewState) error
+	SaveReviewStates(states map[int]ReviewState) error
+	SaveDueReviewState(wordID int, state ReviewState, now time.Time) (bool, error)
+	ResetReviewState(wordID int, state ReviewState) error
+	GetReverseReviewState(wordID int) (*ReviewState, error)
+	GetReverseReviewStates(userID int64) (map[int]ReviewState, error)
+	SaveReverseReviewState(wordID int, state ReviewState) error
+	SaveReverseReviewStates(states map[int]ReviewState) error
+	UpdateWord(word *Word) error
+	MergeWords(keep *Word, duplicateIDs []int) error
+	DeleteWord(wordID int, userID int64) error
+
+	AddWordTags(userID int64, wordID int, tags []string) error
+	RemoveWordTags(userID int64, wordID int, tags []string) error
+	GetWordTags(userID int64) (map[int][]string, error)
+	GetUserTags(userID int64) ([]*Tag, error)
+	GetTagByName(userID int64, name string) (*Tag, error)
+	GetTag(tagID int) (*Tag, error)
+
+	CreateDeck(deck *Deck) error
+	GetDeck(deckID int) (*Deck, error)
+	GetUserDecks(userID int64) ([]*Deck, error)
+	UpdateDeck(deck *Deck) error
+	UpdateDeckScheduler(deck *Deck, states, reverse map[int]ReviewState) error
+	SetActiveDeck(userID int64, deckID int) error
+	MoveWords(userID int64, wordIDs []int, deckID int) error
+	DeleteDeck(userID int64, deckID int) error
+	CountNewWordsReviewed(userID int64, deckID int, since time.Time) (int, error)
+	SetDeckShareCode(userID int64, deckID int, code string) error
+	GetDeckByShareCode(code string) (*Deck, error)
+	ImportDeck(deck *Deck, words []*Word, sourceID int) error
+}
+
+// ReviewLogStore описывает журнал ответов пользователя
+type ReviewLogStore interface {
+	SaveQuiz(quiz *Quiz) error
+	GetUserQuizzes(userID int64, limit int) ([]*Quiz, error)
+	GetWordQuizzes(wordID int) ([]*Quiz, error)
+}
+
+// QuizSessionStore описывает хранилище сессий и раундов тестов
+type QuizSessionStore interface {
+	CreateQuizSession(session *QuizSession) error
+	GetQuizSession(sessionID string) (*QuizSession, error)
+	GetQuizSessionByPoll(pollID string) (*QuizSession, error)
+	SetQuizSessionPoll(sessionID, pollID string) error
+	MarkQuizSessionAnswered(sessionID string, chosenIdx int, answeredAt time.Time) (bool, error)
+	ClearQuizSessionAnswer(sessionID string) error
+	SaveQuizSessionReview(sessionID string, nextReviewBefore, nextReviewAfter time.Time) error
+	DeleteExpiredQuizSessions(before time.Time) error
+
+	CreateQuizRound(round *QuizRound) error
+	GetQuizRound(roundID string) (*QuizRound, error)
+	GetRoundSessions(roundID string) ([]*QuizSession, error)
+	FinishQuizRound(roundID string, aborted bool, finishedAt time.Time) (bool, error)
+}
+
+// Проверяем, что реализации соответствуют интерфейсам
+var (
+	_ UserStore        = (*UserRepository)(nil)
+	_ UserStore        = (*MemoryUserRepository)(nil)
+	_ WordStore        = (*WordRepository)(nil)
+	_ WordStore        = (*MemoryWordRepository)(nil)
+	_ ReviewLogStore   = (*ReviewLogRepository)(nil)
+	_ ReviewLogStore   = (*MemoryReviewLogRepository)(nil)
+	_ QuizSessionStore = (*QuizSessionRepository)(nil)
+	_ QuizSessionStore = (*MemoryQuizSessionRepository)(nil)
+)
@@ -0,0 +1,1477 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Идентификаторы пользователей, которые создает общий набор тестов
+const (
+	testUserID      int64 = 990000000001
+	testOtherUserID int64 = 990000000002
+)
+
+// testStores объединяет хранилища одной реализации
+type testStores struct {
+	users    UserStore
+	words    WordStore
+	logs     ReviewLogStore
+	sessions QuizSessionStore
+}
+
+// storeFactory возвращает чистые хранилища для одного теста
+type storeFactory func(t *testing.T) testStores
+
+func TestMemoryStores(t *testing.T) {
+	runStoreSuite(t, func(t *testing.T) testStores {
+		db := NewMemoryDatabase()
+		return testStores{
+			users:    NewMemoryUserRepository(db),
+			words:    NewMemoryWordRepository(db),
+			logs:     NewMemoryReviewLogRepository(db),
+			sessions: NewMemoryQuizSessionRepository(db),
+		}
+	})
+}
+
+func TestSQLiteStores(t *testing.T) {
+	runStoreSuite(t, func(t *testing.T) testStores {
+		db, err := NewSQLiteDatabase(t.TempDir() + "/test.db")
+		if err != nil {
+			t.Fatalf("Failed to open SQLite database: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return newSQLStores(db)
+	})
+}
+
+func TestPostgresStores(t *testing.T) {
+	db := openTestPostgres(t)
+
+	runStoreSuite(t, func(t *testing.T) testStores {
+		cleanupTestUsers(t, db)
+		t.Cleanup(func() { cleanupTestUsers(t, db) })
+		return newSQLStores(db)
+	})
+}
+
+// TestPostgresSearchWords проверяет по отдельности условия SQL-поиска:
+// полнотекстовый поиск по контексту, ILIKE по подстроке и триграммы pg_trgm
+func TestPostgresSearchWords(t *testing.T) {
+	db := openTestPostgres(t)
+	cleanupTestUsers(t, db)
+	t.Cleanup(func() { cleanupTestUsers(t, db) })
+
+	s := newSQLStores(db)
+	mustCreateUser(t, s.users, testUserID)
+	apple := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+	pineapple := mustSaveWord(t, s.words, testUserID, "pineapple", "ананас")
+	percent := mustSaveWord(t, s.words, testUserID, "50% off", "скидка половина")
+	mustSaveWord(t, s.words, testUserID, "500 miles", "пятьсот миль")
+	juice := &Word{UserID: testUserID, Word: "juice", Translation: "сок", Context: "orange juice for breakfast"}
+	if err := s.words.SaveWord(juice); err != nil {
+		t.Fatalf("Failed to save word: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    int
+		notWant string
+	}{
+		{name: "tsvector matches a context word", query: "breakfast", want: juice.ID},
+		{name: "ILIKE matches a substring", query: "INEAP", want: pineapple.ID},
+		{name: "ILIKE escapes the percent sign", query: "50%", want: percent.ID, notWant: "500 miles"},
+		{name: "trigrams match a typo", query: "appel", want: apple.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := s.words.SearchWords(testUserID, tt.query, 10)
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+			if len(found) == 0 || found[0].ID != tt.want {
+				t.Errorf("Expected word %d first for %q, got %+v", tt.want, tt.query, found)
+			}
+			for _, word := range found {
+				if word.Word == tt.notWant {
+					t.Errorf("Expected %q not to match %q", tt.query, word.Word)
+				}
+			}
+		})
+	}
+}
+
+// openTestPostgres подключается к PostgreSQL из DB_HOST или пропускает тест
+func openTestPostgres(t *testing.T) *Database {
+	t.Helper()
+
+	host := os.Getenv("DB_HOST")
+	if host == "" {
+		t.Skip("DB_HOST is not set, skipping PostgreSQL tests")
+	}
+
+	db, err := NewDatabase(host, getTestEnv("DB_PORT", "5432"), getTestEnv("DB_USER", "user"),
+		getTestEnv("DB_PASSWORD", "password"), getTestEnv("DB_NAME", "english_bot_db"))
+	if err != nil {
+		t.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// newSQLStores создает SQL-репозитории поверх одной базы
+func newSQLStores(db *Database) testStores {
+	return testStores{
+		users:    NewUserRepository(db),
+		words:    NewWordRepository(db),
+		logs:     NewReviewLogRepository(db),
+		sessions: NewQuizSessionRepository(db),
+	}
+}
+
+// cleanupTestUsers удаляет данные тестовых пользователей из общей базы
+func cleanupTestUsers(t *testing.T, d *Database) {
+	t.Helper()
+
+	queries := []string{
+		`DELETE FROM quiz_sessions WHERE user_id IN ($1, $2)`,
+		`DELETE FROM quiz_rounds WHERE user_id IN ($1, $2)`,
+		`DELETE FROM quizzes WHERE user_id IN ($1, $2)`,
+		`DELETE FROM words WHERE user_id IN ($1, $2)`,
+		`DELETE FROM tags WHERE user_id IN ($1, $2)`,
+		`DELETE FROM decks WHERE user_id IN ($1, $2)`,
+		`DELETE FROM users WHERE id IN ($1, $2)`,
+	}
+	for _, query := range queries {
+		if _, err := d.db.Exec(query, testUserID, testOtherUserID); err != nil {
+			t.Fatalf("Failed to clean up test data: %v", err)
+		}
+	}
+}
+
+func getTestEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// runStoreSuite проверяет поведение, общее для всех реализаций хранилищ
+func runStoreSuite(t *testing.T, newStores storeFactory) {
+	t.Run("GetUser returns nil for unknown user", func(t *testing.T) {
+		s := newStores(t)
+
+		user, err := s.users.GetUser(testUserID)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if user != nil {
+			t.Errorf("Expected nil user, got: %+v", user)
+		}
+	})
+
+	t.Run("UpdateUserStateData stores data until the state changes", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+
+		if err := s.users.UpdateUserStateData(testUserID, "add:translation", `{"word":"apple"}`); err != nil {
+			t.Fatalf("Failed to update state data: %v", err)
+		}
+		user, _ := s.users.GetUser(testUserID)
+		if user.State != "add:translation" || user.StateData != `{"word":"apple"}` {
+			t.Errorf("Expected state with data, got %q, %q", user.State, user.StateData)
+		}
+
+		if err := s.users.UpdateUserState(testUserID, "idle"); err != nil {
+			t.Fatalf("Failed to update state: %v", err)
+		}
+		user, _ = s.users.GetUser(testUserID)
+		if user.State != "idle" || user.StateData != "" {
+			t.Errorf("Expected data to be cleared with the state, got %q, %q", user.State, user.StateData)
+		}
+	})
+
+	t.Run("CreateOrUpdateUser keeps state on update", func(t *testing.T) {
+		s := newStores(t)
+
+		mustCreateUser(t, s.users, testUserID)
+		if err := s.users.UpdateUserState(testUserID, "adding"); err != nil {
+			t.Fatalf("Failed to update state: %v", err)
+		}
+
+		err := s.users.CreateOrUpdateUser(&User{ID: testUserID, Username: "renamed", FirstName: "New", State: "idle"})
+		if err != nil {
+			t.Fatalf("Failed to update user: %v", err)
+		}
+
+		user, err := s.users.GetUser(testUserID)
+		if err != nil || user == nil {
+			t.Fatalf("Expected user, got %v, %v", user, err)
+		}
+		if user.Username != "renamed" || user.FirstName != "New" {
+			t.Errorf("Expected profile to be updated, got: %+v", user)
+		}
+		if user.State != "adding" {
+			t.Errorf("Expected state to be preserved, got: %q", user.State)
+		}
+		if user.CreatedAt.IsZero() {
+			t.Error("Expected CreatedAt to be set")
+		}
+	})
+
+	t.Run("SaveWord assigns ID and initial schedule", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+
+		word := &Word{UserID: testUserID, Word: "apple", Translation: "яблоко", Context: "red apple"}
+		if err := s.words.SaveWord(word); err != nil {
+			t.Fatalf("Failed to save word: %v", err)
+		}
+		if word.ID == 0 || word.CreatedAt.IsZero() {
+			t.Fatalf("Expected ID and CreatedAt to be set, got: %+v", word)
+		}
+
+		list, err := s.words.GetUserWords(testUserID)
+		if err != nil {
+			t.Fatalf("Failed to get words: %v", err)
+		}
+		if len(list) != 1 {
+			t.Fatalf("Expected 1 word, got %d", len(list))
+		}
+
+		saved := list[0]
+		if saved.ID != word.ID || saved.Word != "apple" || saved.Translation != "яблоко" || saved.Context != "red apple" {
+			t.Errorf("Unexpected saved word: %+v", saved)
+		}
+		if saved.Interval != 1 || saved.Difficulty != 0 {
+			t.Errorf("Expected interval 1 and difficulty 0, got %d and %d", saved.Interval, saved.Difficulty)
+		}
+		if !saved.NextReview.After(time.Now()) {
+			t.Errorf("Expected next review in the future, got %v", saved.NextReview)
+		}
+		if !saved.IsNew() {
+			t.Errorf("Expected saved word to be new, got last review %v and created at %v",
+				saved.LastReview, saved.CreatedAt)
+		}
+	})
+
+	t.Run("SaveWord fails for unknown user", func(t *testing.T) {
+		s := newStores(t)
+
+		if err := s.words.SaveWord(&Word{UserID: testUserID, Word: "apple", Translation: "яблоко"}); err == nil {
+			t.Error("Expected error for unknown user, got nil")
+		}
+	})
+
+	t.Run("SaveWords saves all words or none", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+
+		words := []*Word{
+			{UserID: testUserID, Word: "apple", Translation: "яблоко"},
+			{UserID: testUserID, Word: "pear", Translation: "груша", Context: "ripe pear"},
+		}
+		if err := s.words.SaveWords(words); err != nil {
+			t.Fatalf("Failed to save words: %v", err)
+		}
+		if words[0].ID == 0 || words[1].ID == 0 || words[0].ID == words[1].ID {
+			t.Errorf("Expected distinct IDs, got %d and %d", words[0].ID, words[1].ID)
+		}
+
+		err := s.words.SaveWords([]*Word{
+			{UserID: testUserID, Word: "plum", Translation: "слива"},
+			{UserID: testOtherUserID, Word: "lemon", Translation: "лимон"}, // Пользователя нет
+		})
+		if err == nil {
+			t.Fatal("Expected error for unknown user, got nil")
+		}
+
+		list, err := s.words.GetUserWords(testUserID)
+		if err != nil {
+			t.Fatalf("Failed to get words: %v", err)
+		}
+		if len(list) != 2 {
+			t.Errorf("Expected the failed batch to be rolled back, got %d words", len(list))
+		}
+	})
+
+	t.Run("ListWords pages by key in every sort order", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustCreateUser(t, s.users, testOtherUserID)
+
+		names := []string{"delta", "Alpha", "echo", "charlie", "bravo"}
+		ids := make(map[string]int)
+		for i, name := range names {
+			word := mustSaveWord(t, s.words, testUserID, name, name+"-ru")
+			ids[name] = word.ID
+			state := word.ReviewState
+			state.EaseFactor = 2.5 - 0.1*float64(i)
+			state.NextReview = time.Now().Add(time.Duration(len(names)-i) * time.Hour)
+			if err := s.words.SaveReviewState(word.ID, state); err != nil {
+				t.Fatalf("Failed to save review state: %v", err)
+			}
+		}
+		foreign := mustSaveWord(t, s.words, testOtherUserID, "aaa", "чужое")
+
+		orders := map[string][]string{
+			WordSortRecent:  {"bravo", "charlie", "echo", "Alpha", "delta"},
+			WordSortAlpha:   {"Alpha", "bravo", "charlie", "delta", "echo"},
+			WordSortHardest: {"bravo", "charlie", "echo", "Alpha", "delta"},
+			WordSortDue:     {"bravo", "charlie", "echo", "Alpha", "delta"},
+		}
+		for sortName, want := range orders {
+			// Вперед страницами по два слова, каждая начинается со следующего слова
+			var got []string
+			fromID := 0
+			for {
+				page, err := s.words.ListWords(WordListQuery{UserID: testUserID, Sort: sortName, FromID: fromID, Limit: 3})
+				if err != nil {
+					t.Fatalf("%s: failed to list words: %v", sortName, err)
+				}
+				for _, word := range page[:min(2, len(page))] {
+					got = append(got, word.Word)
+				}
+				if len(page) < 3 {
+					break
+				}
+				fromID = page[2].ID
+			}
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("%s: expected %v, got %v", sortName, want, got)
+			}
+
+			// Назад от последнего слова — два предыдущих в порядке показа
+			last := ids[want[len(want)-1]]
+			back, err := s.words.ListWords(WordListQuery{UserID: testUserID, Sort: sortName, FromID: last,
+				Backward: true, Limit: 2})
+			if err != nil {
+				t.Fatalf("%s: failed to list backward: %v", sortName, err)
+			}
+			if len(back) != 2 || back[0].Word != want[2] || back[1].Word != want[3] {
+				t.Errorf("%s: expected %v before %s, got %+v", sortName, want[2:4], want[4], back)
+			}
+
+			before, err := s.words.CountWords(WordListQuery{UserID: testUserID, Sort: sortName, FromID: last, Backward: true})
+			if err != nil || before != 4 {
+				t.Errorf("%s: expected 4 words before the last, got %d, %v", sortName, before, err)
+			}
+		}
+
+		total, err := s.words.CountWords(WordListQuery{UserID: testUserID, Sort: WordSortRecent})
+		if err != nil || total != len(names) {
+			t.Errorf("Expected %d words in total, got %d, %v", len(names), total, err)
+		}
+		if _, err := s.words.ListWords(WordListQuery{UserID: testUserID, Sort: "random"}); err == nil {
+			t.Error("Expected error for unknown sort, got nil")
+		}
+
+		// Чужое или удаленное слово в курсоре означает первую страницу
+		for _, fromID := range []int{foreign.ID, foreign.ID + 1000} {
+			page, err := s.words.ListWords(WordListQuery{UserID: testUserID, Sort: WordSortAlpha, FromID: fromID, Limit: 2})
+			if err != nil || len(page) != 2 || page[0].Word != "Alpha" {
+				t.Errorf("Expected the first page for cursor %d, got %+v, %v", fromID, page, err)
+			}
+			query := WordListQuery{UserID: testUserID, Sort: WordSortAlpha, FromID: fromID, Backward: true}
+			if before, err := s.words.CountWords(query); err != nil || before != 0 {
+				t.Errorf("Expected no words before cursor %d, got %d, %v", fromID, before, err)
+			}
+			query.Backward = false
+			if count, err := s.words.CountWords(query); err != nil || count != len(names) {
+				t.Errorf("Expected all %d words from cursor %d, got %d, %v", len(names), fromID, count, err)
+			}
+		}
+	})
+
+	t.Run("Word tags filter lists and follow merged words", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustCreateUser(t, s.users, testOtherUserID)
+
+		apple := &Word{UserID: testUserID, Word: "apple", Translation: "яблоко", Tags: []string{"food", "fruit"}}
+		if err := s.words.SaveWord(apple); err != nil {
+			t.Fatalf("Failed to save word: %v", err)
+		}
+		pear := mustSaveWord(t, s.words, testUserID, "pear", "груша")
+		train := mustSaveWord(t, s.words, testUserID, "train", "поезд")
+		other := mustSaveWord(t, s.words, testOtherUserID, "plum", "слива")
+
+		if err := s.words.AddWordTags(testUserID, pear.ID, []string{"fruit", "fruit"}); err != nil {
+			t.Fatalf("Failed to tag word: %v", err)
+		}
+		if err := s.words.AddWordTags(testUserID, train.ID, []string{"travel"}); err != nil {
+			t.Fatalf("Failed to tag word: %v", err)
+		}
+		if err := s.words.AddWordTags(testUserID, other.ID, []string{"fruit"}); err == nil {
+			t.Error("Expected error for someone else's word, got nil")
+		}
+
+		fruit, err := s.words.GetTagByName(testUserID, "fruit")
+		if err != nil || fruit == nil || fruit.Words != 2 {
+			t.Fatalf("Expected fruit tag with 2 words, got %+v, %v", fruit, err)
+		}
+		if got, _ := s.words.GetTag(fruit.ID); got == nil || got.Name != "fruit" || got.UserID != testUserID {
+			t.Errorf("Expected tag by ID, got %+v", got)
+		}
+		if got, _ := s.words.GetTagByName(testOtherUserID, "fruit"); got != nil {
+			t.Errorf("Expected tags to be per user, got %+v", got)
+		}
+
+		list, err := s.words.ListWords(WordListQuery{UserID: testUserID, Sort: WordSortAlpha, TagID: fruit.ID})
+		if err != nil || len(list) != 2 || list[0].ID != apple.ID || list[1].ID != pear.ID {
+			t.Fatalf("Expected apple and pear with the fruit tag, got %+v, %v", list, err)
+		}
+		page, err := s.words.ListWords(WordListQuery{UserID: testUserID, Sort: WordSortAlpha, TagID: fruit.ID,
+			FromID: pear.ID, Backward: true})
+		if err != nil || len(page) != 1 || page[0].ID != apple.ID {
+			t.Errorf("Expected apple before pear, got %+v, %v", page, err)
+		}
+		fruitWords := WordListQuery{UserID: testUserID, Sort: WordSortRecent, TagID: fruit.ID}
+		if count, _ := s.words.CountWords(fruitWords); count != 2 {
+			t.Errorf("Expected 2 fruit words, got %d", count)
+		}
+
+		tags, err := s.words.GetWordTags(testUserID)
+		if err != nil || strings.Join(tags[apple.ID], ",") != "food,fruit" || len(tags[train.ID]) != 1 {
+			t.Errorf("Unexpected word tags: %v, %v", tags, err)
+		}
+
+		if err := s.words.RemoveWordTags(testUserID, apple.ID, []string{"food"}); err != nil {
+			t.Fatalf("Failed to remove tag: %v", err)
+		}
+		userTags, err := s.words.GetUserTags(testUserID)
+		if err != nil || len(userTags) != 2 || userTags[0].Name != "fruit" || userTags[0].Words != 2 ||
+			userTags[1].Name != "travel" {
+			t.Errorf("Expected fruit and travel without the unused food tag, got %+v, %v", userTags, err)
+		}
+
+		// Теги дубликата переходят к оставшемуся слову
+		if err := s.words.MergeWords(pear, []int{train.ID}); err != nil {
+			t.Fatalf("Failed to merge words: %v", err)
+		}
+		tags, _ = s.words.GetWordTags(testUserID)
+		if strings.Join(tags[pear.ID], ",") != "fruit,travel" {
+			t.Errorf("Expected merged tags, got %v", tags[pear.ID])
+		}
+	})
+
+	t.Run("Decks keep one active deck and own their words", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustCreateUser(t, s.users, testOtherUserID)
+
+		mainDeck := &Deck{UserID: testUserID, Name: "Main", SourceLang: "en", TargetLang: "ru", NewPerDay: 20, Active: true}
+		if err := s.words.CreateDeck(mainDeck); err != nil || mainDeck.ID == 0 {
+			t.Fatalf("Failed to create deck: %+v, %v", mainDeck, err)
+		}
+		travel := &Deck{UserID: testUserID, Name: "Travel", SourceLang: "de", TargetLang: "ru", NewPerDay: 5}
+		if err := s.words.CreateDeck(travel); err != nil {
+			t.Fatalf("Failed to create deck: %v", err)
+		}
+		if err := s.words.CreateDeck(&Deck{UserID: testUserID, Name: "Travel"}); err == nil {
+			t.Error("Expected error for a duplicate deck name, got nil")
+		}
+		other := &Deck{UserID: testOtherUserID, Name: "Main", Active: true}
+		if err := s.words.CreateDeck(other); err != nil {
+			t.Fatalf("Expected deck names to be per user, got %v", err)
+		}
+
+		apple := &Word{UserID: testUserID, Word: "apple", Translation: "яблоко", DeckID: mainDeck.ID}
+		bahn := &Word{UserID: testUserID, Word: "Bahn", Translation: "дорога", DeckID: mainDeck.ID}
+		if err := s.words.SaveWords([]*Word{apple, bahn}); err != nil {
+			t.Fatalf("Failed to save words: %v", err)
+		}
+		if got, _ := s.words.GetWord(apple.ID); got.DeckID != mainDeck.ID {
+			t.Errorf("Expected the word in the main deck, got deck %d", got.DeckID)
+		}
+
+		if err := s.words.MoveWords(testUserID, []int{bahn.ID}, other.ID); err == nil {
+			t.Error("Expected error for someone else's deck, got nil")
+		}
+		if err := s.words.MoveWords(testUserID, []int{bahn.ID}, travel.ID); err != nil {
+			t.Fatalf("Failed to move word: %v", err)
+		}
+		list, err := s.words.ListWords(WordListQuery{UserID: testUserID, Sort: WordSortRecent, DeckID: travel.ID})
+		if err != nil || len(list) != 1 || list[0].ID != bahn.ID {
+			t.Fatalf("Expected only Bahn in the travel deck, got %+v, %v", list, err)
+		}
+
+		if err := s.words.SetActiveDeck(testUserID, travel.ID); err != nil {
+			t.Fatalf("Failed to switch deck: %v", err)
+		}
+		if err := s.words.SetActiveDeck(testUserID, other.ID); err == nil {
+			t.Error("Expected error for someone else's deck, got nil")
+		}
+		travel.Name, travel.Description, travel.NewPerDay, travel.Scheduler = "Reisen", "Поездки", 10, SchedulerFSRS
+		if err := s.words.UpdateDeck(travel); err != nil {
+			t.Fatalf("Failed to update deck: %v", err)
+		}
+
+		decks, err := s.words.GetUserDecks(testUserID)
+		if err != nil || len(decks) != 2 {
+			t.Fatalf("Expected 2 decks, got %+v, %v", decks, err)
+		}
+		if decks[0].ID != mainDeck.ID || decks[0].Active || decks[0].Words != 1 {
+			t.Errorf("Expected inactive main deck with one word, got %+v", decks[0])
+		}
+		got := decks[1]
+		if !got.Active || got.Words != 1 || got.Name != "Reisen" || got.Description != "Поездки" ||
+			got.SourceLang != "de" || got.NewPerDay != 10 || got.Scheduler != SchedulerFSRS {
+			t.Errorf("Expected the updated active travel deck, got %+v", got)
+		}
+
+		// Новым слово считается до первого ответа; считаются ответы начиная с since
+		since := time.Now().Add(-time.Minute)
+		if err := s.logs.SaveQuiz(&Quiz{UserID: testUserID, WordID: bahn.ID, Correct: true}); err != nil {
+			t.Fatalf("Failed to save quiz: %v", err)
+		}
+		if count, err := s.words.CountNewWordsReviewed(testUserID, travel.ID, since); err != nil || count != 1 {
+			t.Errorf("Expected one new word reviewed, got %d, %v", count, err)
+		}
+		if count, _ := s.words.CountNewWordsReviewed(testUserID, travel.ID, time.Now().Add(time.Minute)); count != 0 {
+			t.Errorf("Expected no new words after the answer, got %d", count)
+		}
+
+		state := bahn.ReviewState
+		state.NextReview = time.Now().Add(-time.Hour)
+		if err := s.words.SaveReviewState(bahn.ID, state); err != nil {
+			t.Fatalf("Failed to save review state: %v", err)
+		}
+		due, err := s.words.ListWords(WordListQuery{UserID: testUserID, Sort: WordSortDue, DueBy: time.Now()})
+		if err != nil || len(due) != 1 || due[0].ID != bahn.ID {
+			t.Errorf("Expected only the due word, got %+v, %v", due, err)
+		}
+
+		if err := s.words.DeleteDeck(testOtherUserID, travel.ID); err == nil {
+			t.Error("Expected error for deleting someone else's deck, got nil")
+		}
+		if err := s.words.DeleteDeck(testUserID, travel.ID); err != nil {
+			t.Fatalf("Failed to delete deck: %v", err)
+		}
+		if got, _ := s.words.GetWord(bahn.ID); got != nil {
+			t.Errorf("Expected deck words to be deleted, got %+v", got)
+		}
+		if got, _ := s.words.GetDeck(travel.ID); got != nil {
+			t.Errorf("Expected the deck to be deleted, got %+v", got)
+		}
+		if got, _ := s.words.GetWord(apple.ID); got == nil {
+			t.Error("Expected words of other decks to stay")
+		}
+	})
+
+	t.Run("UpdateDeckScheduler saves the deck and word states together", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+
+		deck := &Deck{UserID: testUserID, Name: "Main", SourceLang: "en", TargetLang: "ru", Active: true}
+		if err := s.words.CreateDeck(deck); err != nil {
+			t.Fatalf("Failed to create deck: %v", err)
+		}
+		apple := &Word{UserID: testUserID, Word: "apple", Translation: "яблоко", DeckID: deck.ID}
+		if err := s.words.SaveWords([]*Word{apple}); err != nil {
+			t.Fatalf("Failed to save words: %v", err)
+		}
+
+		converted := apple.ReviewState
+		converted.Stability, converted.FSRSDifficulty = 2.5, 5
+		deck.Scheduler = SchedulerFSRS
+		err := s.words.UpdateDeckScheduler(deck, map[int]ReviewState{apple.ID: converted, apple.ID + 1000: converted}, nil)
+		if err == nil {
+			t.Fatal("Expected error for an unknown word, got nil")
+		}
+		if got, _ := s.words.GetDeck(deck.ID); got.Scheduler != "" {
+			t.Errorf("Expected the deck scheduler to stay unchanged, got %q", got.Scheduler)
+		}
+		if got, _ := s.words.GetWord(apple.ID); got.Stability != apple.Stability {
+			t.Errorf("Expected the word state to stay unchanged, got %+v", got.ReviewState)
+		}
+
+		reverse := map[int]ReviewState{apple.ID: converted}
+		if err := s.words.UpdateDeckScheduler(deck, map[int]ReviewState{apple.ID: converted}, reverse); err != nil {
+			t.Fatalf("Failed to update deck scheduler: %v", err)
+		}
+		if got, _ := s.words.GetDeck(deck.ID); got.Scheduler != SchedulerFSRS {
+			t.Errorf("Expected the FSRS deck, got %q", got.Scheduler)
+		}
+		if got, _ := s.words.GetWord(apple.ID); got.Stability != 2.5 {
+			t.Errorf("Expected the converted word state, got %+v", got.ReviewState)
+		}
+		if got, err := s.words.GetReverseReviewState(apple.ID); err != nil || got == nil || got.Stability != 2.5 {
+			t.Errorf("Expected the converted reverse state, got %+v, %v", got, err)
+		}
+	})
+
+	t.Run("Shared decks are imported as fresh copies", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustCreateUser(t, s.users, testOtherUserID)
+
+		source := &Deck{UserID: testUserID, Name: "Travel", SourceLang: "en", TargetLang: "ru", NewPerDay: 20, Active: true}
+		if err := s.words.CreateDeck(source); err != nil {
+			t.Fatalf("Failed to create deck: %v", err)
+		}
+		if err := s.words.SetDeckShareCode(testOtherUserID, source.ID, "abc"); err == nil {
+			t.Error("Expected error for sharing someone else's deck, got nil")
+		}
+		if err := s.words.SetDeckShareCode(testUserID, source.ID, "abc"); err != nil {
+			t.Fatalf("Failed to share deck: %v", err)
+		}
+		shared, err := s.words.GetDeckByShareCode("abc")
+		if err != nil || shared == nil || shared.ID != source.ID || shared.ShareCode != "abc" {
+			t.Fatalf("Expected the shared deck, got %+v, %v", shared, err)
+		}
+		if got, _ := s.words.GetDeckByShareCode(""); got != nil {
+			t.Errorf("Expected no deck for an empty code, got %+v", got)
+		}
+
+		copied := &Deck{UserID: testOtherUserID, Name: "Travel", SourceLang: "en", TargetLang: "ru", NewPerDay: 20}
+		train := &Word{Word: "train", Translation: "поезд", Tags: []string{"transport"}}
+		if err := s.words.ImportDeck(copied, []*Word{train}, source.ID); err != nil {
+			t.Fatalf("Failed to import deck: %v", err)
+		}
+		if got, _ := s.words.GetWord(train.ID); got == nil || got.UserID != testOtherUserID || got.DeckID != copied.ID {
+			t.Errorf("Expected the copied word in the new deck, got %+v", got)
+		}
+		if tags, _ := s.words.GetWordTags(testOtherUserID); len(tags[train.ID]) != 1 {
+			t.Errorf("Expected the copied word to keep its tag, got %v", tags)
+		}
+		if got, _ := s.words.GetDeck(source.ID); got.Imports != 1 {
+			t.Errorf("Expected one import, got %d", got.Imports)
+		}
+		if err := s.words.ImportDeck(&Deck{UserID: testOtherUserID, Name: "Travel"}, nil, source.ID); err == nil {
+			t.Error("Expected error for a duplicate deck name, got nil")
+		}
+		if got, _ := s.words.GetDeck(source.ID); got.Imports != 1 {
+			t.Errorf("Expected a failed import not to count, got %d", got.Imports)
+		}
+
+		if err := s.words.SetDeckShareCode(testUserID, source.ID, ""); err != nil {
+			t.Fatalf("Failed to revoke link: %v", err)
+		}
+		if got, _ := s.words.GetDeckByShareCode("abc"); got != nil {
+			t.Errorf("Expected the revoked code to find nothing, got %+v", got)
+		}
+	})
+
+	t.Run("SearchWords finds substrings, typos and context", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustCreateUser(t, s.users, testOtherUserID)
+
+		apple := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		pineapple := mustSaveWord(t, s.words, testUserID, "pineapple", "ананас")
+		mustSaveWord(t, s.words, testUserID, "pear", "груша")
+		mustSaveWord(t, s.words, testOtherUserID, "apple", "яблоко")
+		juice := &Word{UserID: testUserID, Word: "juice", Translation: "сок", Context: "orange juice for breakfast"}
+		if err := s.words.SaveWord(juice); err != nil {
+			t.Fatalf("Failed to save word: %v", err)
+		}
+
+		found, err := s.words.SearchWords(testUserID, "apple", 10)
+		if err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+		if len(found) != 2 || found[0].ID != apple.ID || found[1].ID != pineapple.ID {
+			t.Errorf("Expected apple before pineapple, got %+v", found)
+		}
+
+		if found, _ := s.words.SearchWords(testUserID, "aple", 10); len(found) == 0 || found[0].ID != apple.ID {
+			t.Errorf("Expected the typo to find apple, got %+v", found)
+		}
+		if found, _ := s.words.SearchWords(testUserID, "ЯБЛОКО", 10); len(found) != 1 || found[0].ID != apple.ID {
+			t.Errorf("Expected the translation to be found, got %+v", found)
+		}
+		if found, _ := s.words.SearchWords(testUserID, "breakfast", 10); len(found) != 1 || found[0].ID != juice.ID {
+			t.Errorf("Expected the context to be found, got %+v", found)
+		}
+		if found, _ := s.words.SearchWords(testUserID, "apple", 1); len(found) != 1 {
+			t.Errorf("Expected the limit to apply, got %d", len(found))
+		}
+		if found, _ := s.words.SearchWords(testUserID, "zebra", 10); len(found) != 0 {
+			t.Errorf("Expected nothing for zebra, got %+v", found)
+		}
+	})
+
+	t.Run("GetUserWords returns newest first and only own words", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustCreateUser(t, s.users, testOtherUserID)
+
+		mustSaveWord(t, s.words, testUserID, "one", "один")
+		mustSaveWord(t, s.words, testOtherUserID, "foreign", "чужой")
+		mustSaveWord(t, s.words, testUserID, "two", "два")
+		mustSaveWord(t, s.words, testUserID, "three", "три")
+
+		list, err := s.words.GetUserWords(testUserID)
+		if err != nil {
+			t.Fatalf("Failed to get words: %v", err)
+		}
+		got := make([]string, 0, len(list))
+		for _, w := range list {
+			got = append(got, w.Word)
+		}
+		want := []string{"three", "two", "one"}
+		if len(got) != len(want) {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("Expected %v, got %v", want, got)
+			}
+		}
+	})
+
+	t.Run("New words are not due for review", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+
+		due, err := s.words.GetWordsForReview(testUserID)
+		if err != nil {
+			t.Fatalf("Failed to get words for review: %v", err)
+		}
+		if len(due) != 0 {
+			t.Errorf("Expected no due words, got %d", len(due))
+		}
+	})
+
+	t.Run("GetWord returns the word or nil", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		saved := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+
+		word, err := s.words.GetWord(saved.ID)
+		if err != nil {
+			t.Fatalf("Failed to get word: %v", err)
+		}
+		if word == nil || word.Word != "apple" || word.UserID != testUserID {
+			t.Errorf("Unexpected word: %+v", word)
+		}
+
+		missing, err := s.words.GetWord(-1)
+		if err != nil {
+			t.Fatalf("Expected no error for unknown word, got: %v", err)
+		}
+		if missing != nil {
+			t.Errorf("Expected nil for unknown word, got: %+v", missing)
+		}
+	})
+
+	t.Run("SaveReviewState persists every field", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		saved := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+
+		now := time.Now().Truncate(time.Second)
+		state := ReviewState{
+			LastReview:     now,
+			NextReview:     now.AddDate(0, 0, 15),
+			Interval:       15,
+			Difficulty:     2,
+			EaseFactor:     2.36,
+			Repetitions:    3,
+			Lapses:         1,
+			Stability:      14.8,
+			FSRSDifficulty: 5.2,
+			Retrievability: 0.89,
+		}
+		if err := s.words.SaveReviewState(saved.ID, state); err != nil {
+			t.Fatalf("Failed to save review state: %v", err)
+		}
+
+		word, err := s.words.GetWord(saved.ID)
+		if err != nil || word == nil {
+			t.Fatalf("Failed to get word: %v", err)
+		}
+		got := word.ReviewState
+		if !got.LastReview.Equal(state.LastReview) || !got.NextReview.Equal(state.NextReview) {
+			t.Errorf("Expected review dates %v/%v, got %v/%v",
+				state.LastReview, state.NextReview, got.LastReview, got.NextReview)
+		}
+		got.LastReview, got.NextReview = state.LastReview, state.NextReview
+		if got != state {
+			t.Errorf("Expected state %+v, got %+v", state, got)
+		}
+	})
+
+	t.Run("SaveDueReviewState saves only a due word once", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		now := time.Now()
+
+		later := ReviewState{LastReview: now, NextReview: now.Add(24 * time.Hour), Interval: 1, EaseFactor: 2.5}
+		if saved, err := s.words.SaveDueReviewState(word.ID, later, now); err != nil || saved {
+			t.Errorf("Expected a word that is not due to be left alone, got %v, %v", saved, err)
+		}
+
+		due := later
+		due.NextReview = now.Add(-time.Hour)
+		if err := s.words.SaveReviewState(word.ID, due); err != nil {
+			t.Fatalf("Failed to save review state: %v", err)
+		}
+		if saved, err := s.words.SaveDueReviewState(word.ID, later, now); err != nil || !saved {
+			t.Fatalf("Expected the due word to be saved, got %v, %v", saved, err)
+		}
+		// Вторая оценка той же карточки уже не проходит
+		if saved, err := s.words.SaveDueReviewState(word.ID, later, now); err != nil || saved {
+			t.Errorf("Expected the second grade to be rejected, got %v, %v", saved, err)
+		}
+		if got, _ := s.words.GetWord(word.ID); !got.NextReview.After(now) {
+			t.Errorf("Expected the new schedule to be stored, got %v", got.NextReview)
+		}
+	})
+
+	t.Run("SaveReviewState fails for unknown word", func(t *testing.T) {
+		s := newStores(t)
+
+		if err := s.words.SaveReviewState(-1, ReviewState{Interval: 1}); err == nil {
+			t.Error("Expected error for unknown word, got nil")
+		}
+	})
+
+	t.Run("GetWordsForReview returns due words ordered by next review", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+
+		now := time.Now()
+		states := make(map[int]ReviewState)
+		var dueIDs []int
+		for i := 0; i < 12; i++ {
+			w := mustSaveWord(t, s.words, testUserID, fmt.Sprintf("word%d", i), "слово")
+			// Первые 11 слов просрочены, от самого старого к самому новому
+			next := now.Add(-time.Duration(12-i) * time.Hour)
+			if i == 11 {
+				next = now.Add(time.Hour)
+			} else {
+				dueIDs = append(dueIDs, w.ID)
+			}
+			states[w.ID] = ReviewState{LastReview: now, NextReview: next, Interval: 1, EaseFactor: 2.5}
+		}
+		if err := s.words.SaveReviewStates(states); err != nil {
+			t.Fatalf("Failed to save review states: %v", err)
+		}
+
+		due, err := s.words.GetWordsForReview(testUserID)
+		if err != nil {
+			t.Fatalf("Failed to get words for review: %v", err)
+		}
+		if len(due) != 10 {
+			t.Fatalf("Expected 10 due words, got %d", len(due))
+		}
+		for i, w := range due {
+			if w.ID != dueIDs[i] {
+				t.Fatalf("Expected due word %d at position %d, got %d", dueIDs[i], i, w.ID)
+			}
+		}
+	})
+
+	t.Run("UpdateUserScheduler stores the chosen algorithm", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+
+		user, _ := s.users.GetUser(testUserID)
+		if user.Scheduler != SchedulerSM2 {
+			t.Errorf("Expected default scheduler %q, got %q", SchedulerSM2, user.Scheduler)
+		}
+
+		if err := s.users.UpdateUserScheduler(testUserID, SchedulerFSRS); err != nil {
+			t.Fatalf("Failed to update scheduler: %v", err)
+		}
+		user, _ = s.users.GetUser(testUserID)
+		if user.Scheduler != SchedulerFSRS {
+			t.Errorf("Expected scheduler %q, got %q", SchedulerFSRS, user.Scheduler)
+		}
+	})
+
+	t.Run("UpdateUserQuizMode stores the chosen mode", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+
+		user, _ := s.users.GetUser(testUserID)
+		if user.QuizMode != DirectionForward {
+			t.Errorf("Expected default quiz mode %q, got %q", DirectionForward, user.QuizMode)
+		}
+
+		if err := s.users.UpdateUserQuizMode(testUserID, QuizModeMixed); err != nil {
+			t.Fatalf("Failed to update quiz mode: %v", err)
+		}
+		user, _ = s.users.GetUser(testUserID)
+		if user.QuizMode != QuizModeMixed {
+			t.Errorf("Expected quiz mode %q, got %q", QuizModeMixed, user.QuizMode)
+		}
+	})
+
+	t.Run("UpdateUserQuizPresentation stores the chosen presentation", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+
+		user, _ := s.users.GetUser(testUserID)
+		if user.QuizPresentation != PresentationButtons {
+			t.Errorf("Expected default presentation %q, got %q", PresentationButtons, user.QuizPresentation)
+		}
+
+		if err := s.users.UpdateUserQuizPresentation(testUserID, PresentationPoll); err != nil {
+			t.Fatalf("Failed to update quiz presentation: %v", err)
+		}
+		user, _ = s.users.GetUser(testUserID)
+		if user.QuizPresentation != PresentationPoll {
+			t.Errorf("Expected presentation %q, got %q", PresentationPoll, user.QuizPresentation)
+		}
+	})
+
+	t.Run("Reverse review state is kept apart from the word", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustCreateUser(t, s.users, testOtherUserID)
+		apple := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		foreign := mustSaveWord(t, s.words, testOtherUserID, "foreign", "чужой")
+
+		missing, err := s.words.GetReverseReviewState(apple.ID)
+		if err != nil || missing != nil {
+			t.Fatalf("Expected no reverse state for a new word, got %v, %v", missing, err)
+		}
+
+		now := time.Now().Truncate(time.Second)
+		state := ReviewState{LastReview: now, NextReview: now.AddDate(0, 0, 6), Interval: 6, EaseFactor: 2.5, Repetitions: 2}
+		if err := s.words.SaveReverseReviewState(apple.ID, state); err != nil {
+			t.Fatalf("Failed to save reverse state: %v", err)
+		}
+		// Повторное сохранение обновляет строку, а не добавляет новую
+		state.Interval, state.Repetitions, state.Lapses = 1, 0, 1
+		if err := s.words.SaveReverseReviewStates(map[int]ReviewState{apple.ID: state, foreign.ID: state}); err != nil {
+			t.Fatalf("Failed to update reverse states: %v", err)
+		}
+
+		got, err := s.words.GetReverseReviewState(apple.ID)
+		if err != nil || got == nil {
+			t.Fatalf("Expected reverse state, got %v, %v", got, err)
+		}
+		if got.Interval != 1 || got.Lapses != 1 || !got.NextReview.Equal(state.NextReview) {
+			t.Errorf("Expected %+v, got %+v", state, *got)
+		}
+
+		states, err := s.words.GetReverseReviewStates(testUserID)
+		if err != nil {
+			t.Fatalf("Failed to get reverse states: %v", err)
+		}
+		if _, ok := states[apple.ID]; len(states) != 1 || !ok {
+			t.Errorf("Expected only the user's word, got %v", states)
+		}
+
+		// Прямое направление не меняется
+		word, _ := s.words.GetWord(apple.ID)
+		if word.Interval != 1 || word.Repetitions != 0 || word.Lapses != 0 {
+			t.Errorf("Expected forward state to be untouched, got %+v", word.ReviewState)
+		}
+
+		if err := s.words.SaveReverseReviewState(999999, state); err == nil {
+			t.Error("Expected error for unknown word, got nil")
+		}
+
+		if err := s.words.DeleteWord(apple.ID, testUserID); err != nil {
+			t.Fatalf("Failed to delete word: %v", err)
+		}
+		if got, _ := s.words.GetReverseReviewState(apple.ID); got != nil {
+			t.Errorf("Expected reverse state to be deleted with the word, got %+v", got)
+		}
+	})
+
+	t.Run("DeleteWord checks ownership", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+
+		if err := s.words.DeleteWord(word.ID, testOtherUserID); err == nil {
+			t.Error("Expected error when deleting someone else's word, got nil")
+		}
+		if err := s.words.DeleteWord(word.ID, testUserID); err != nil {
+			t.Fatalf("Failed to delete word: %v", err)
+		}
+		if err := s.words.DeleteWord(word.ID, testUserID); err == nil {
+			t.Error("Expected error when deleting a deleted word, got nil")
+		}
+
+		list, err := s.words.GetUserWords(testUserID)
+		if err != nil {
+			t.Fatalf("Failed to get words: %v", err)
+		}
+		if len(list) != 0 {
+			t.Errorf("Expected no words after delete, got %d", len(list))
+		}
+	})
+
+	t.Run("UpdateWord changes text but keeps the schedule", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		state := ReviewState{Interval: 6, EaseFactor: 2.5, Repetitions: 2, NextReview: time.Now().Add(6 * 24 * time.Hour)}
+		if err := s.words.SaveReviewState(word.ID, state); err != nil {
+			t.Fatalf("Failed to save review state: %v", err)
+		}
+
+		foreign := &Word{ID: word.ID, UserID: testOtherUserID, Word: "x", Translation: "y"}
+		if err := s.words.UpdateWord(foreign); err == nil {
+			t.Error("Expected error when updating someone else's word, got nil")
+		}
+
+		word.Translation, word.Context = "яблоко, яблоня", "green apple"
+		if err := s.words.UpdateWord(word); err != nil {
+			t.Fatalf("Failed to update word: %v", err)
+		}
+		got, _ := s.words.GetWord(word.ID)
+		if got.Word != "apple" || got.Translation != "яблоко, яблоня" || got.Context != "green apple" {
+			t.Errorf("Unexpected word after update: %+v", got)
+		}
+		if got.Interval != 6 || got.Repetitions != 2 {
+			t.Errorf("Expected schedule to be kept, got %+v", got.ReviewState)
+		}
+	})
+
+	t.Run("ResetReviewState replaces forward state and drops reverse state", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		reviewed := ReviewState{Interval: 6, EaseFactor: 2.5, Repetitions: 2, NextReview: time.Now().Add(6 * 24 * time.Hour)}
+		if err := s.words.SaveReviewState(word.ID, reviewed); err != nil {
+			t.Fatalf("Failed to save review state: %v", err)
+		}
+		if err := s.words.SaveReverseReviewState(word.ID, reviewed); err != nil {
+			t.Fatalf("Failed to save reverse state: %v", err)
+		}
+
+		fresh := ReviewState{LastReview: word.CreatedAt, NextReview: word.CreatedAt, Interval: 1, EaseFactor: 2.5}
+		if err := s.words.ResetReviewState(word.ID, fresh); err != nil {
+			t.Fatalf("Failed to reset review state: %v", err)
+		}
+		got, _ := s.words.GetWord(word.ID)
+		if got.Interval != 1 || got.Repetitions != 0 {
+			t.Errorf("Expected forward state to be reset, got %+v", got.ReviewState)
+		}
+		if reverse, _ := s.words.GetReverseReviewState(word.ID); reverse != nil {
+			t.Errorf("Expected reverse state to be deleted, got %+v", reverse)
+		}
+
+		if err := s.words.ResetReviewState(word.ID+100, fresh); err == nil {
+			t.Error("Expected error for unknown word, got nil")
+		}
+	})
+
+	t.Run("MergeWords moves the review log and deletes duplicates", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustCreateUser(t, s.users, testOtherUserID)
+		keep := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		dup := mustSaveWord(t, s.words, testUserID, "Apple", "яблоня")
+		foreign := mustSaveWord(t, s.words, testOtherUserID, "apple", "яблоко")
+		if err := s.logs.SaveQuiz(&Quiz{UserID: testUserID, WordID: dup.ID, Correct: true}); err != nil {
+			t.Fatalf("Failed to save quiz: %v", err)
+		}
+
+		keep.Translation = "яблоко, яблоня"
+		if err := s.words.MergeWords(keep, []int{dup.ID, foreign.ID}); err == nil {
+			t.Fatal("Expected error when merging someone else's word, got nil")
+		}
+		if got, _ := s.words.GetWord(dup.ID); got == nil {
+			t.Fatal("Expected the failed merge to be rolled back")
+		}
+
+		if err := s.words.MergeWords(keep, []int{dup.ID}); err != nil {
+			t.Fatalf("Failed to merge words: %v", err)
+		}
+		if got, _ := s.words.GetWord(dup.ID); got != nil {
+			t.Errorf("Expected duplicate to be deleted, got %+v", got)
+		}
+		if got, _ := s.words.GetWord(keep.ID); got.Translation != "яблоко, яблоня" {
+			t.Errorf("Expected merged translation, got %q", got.Translation)
+		}
+		history, err := s.logs.GetWordQuizzes(keep.ID)
+		if err != nil {
+			t.Fatalf("Failed to get history: %v", err)
+		}
+		if len(history) != 1 {
+			t.Errorf("Expected the duplicate's answer to move to the kept word, got %d", len(history))
+		}
+	})
+
+	t.Run("GetWordByNorm finds the word by normalized spelling", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustCreateUser(t, s.users, testOtherUserID)
+		apple := mustSaveWord(t, s.words, testUserID, "Apple", "яблоко")
+		mustSaveWord(t, s.words, testUserID, "apple ", "яблоня") // Дубликат до /duplicates
+		pear := mustSaveWord(t, s.words, testUserID, "pear", "груша")
+		mustSaveWord(t, s.words, testOtherUserID, "plum", "слива")
+
+		if got, err := s.words.GetWordByNorm(testUserID, "apple"); err != nil || got == nil || got.ID != apple.ID {
+			t.Errorf("Expected the earliest apple, got %+v, %v", got, err)
+		}
+		if got, _ := s.words.GetWordByNorm(testUserID, "plum"); got != nil {
+			t.Errorf("Expected someone else's word not to be found, got %+v", got)
+		}
+
+		pear.Word = "Ice   Cream"
+		if err := s.words.UpdateWord(pear); err != nil {
+			t.Fatalf("Failed to update word: %v", err)
+		}
+		if got, _ := s.words.GetWordByNorm(testUserID, "ice cream"); got == nil || got.ID != pear.ID {
+			t.Errorf("Expected the new spelling to be found, got %+v", got)
+		}
+		if got, _ := s.words.GetWordByNorm(testUserID, "pear"); got != nil {
+			t.Errorf("Expected the old spelling to be gone, got %+v", got)
+		}
+	})
+
+	t.Run("SaveQuiz records the answer", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+
+		quiz := &Quiz{
+			UserID: testUserID, WordID: word.ID, ChosenOption: "груша", Correct: false,
+			IntervalBefore: 6, IntervalAfter: 1, LatencyMs: 4200, Direction: DirectionReverse,
+		}
+		if err := s.logs.SaveQuiz(quiz); err != nil {
+			t.Fatalf("Failed to save quiz: %v", err)
+		}
+		if quiz.ID == 0 || quiz.CreatedAt.IsZero() {
+			t.Errorf("Expected ID and CreatedAt to be set, got: %+v", quiz)
+		}
+
+		history, err := s.logs.GetWordQuizzes(word.ID)
+		if err != nil {
+			t.Fatalf("Failed to get word quizzes: %v", err)
+		}
+		if len(history) != 1 {
+			t.Fatalf("Expected 1 quiz, got %d", len(history))
+		}
+		got := history[0]
+		if got.ID != quiz.ID || got.UserID != testUserID || got.WordID != word.ID || got.ChosenOption != "груша" ||
+			got.Correct || got.IntervalBefore != 6 || got.IntervalAfter != 1 || got.LatencyMs != 4200 ||
+			got.Direction != DirectionReverse {
+			t.Errorf("Expected %+v, got %+v", quiz, got)
+		}
+	})
+
+	t.Run("SaveQuiz fails for unknown word", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+
+		if err := s.logs.SaveQuiz(&Quiz{UserID: testUserID, WordID: 999999}); err == nil {
+			t.Error("Expected error for unknown word, got nil")
+		}
+	})
+
+	t.Run("Quiz history is ordered and filtered", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		mustCreateUser(t, s.users, testOtherUserID)
+		apple := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		pear := mustSaveWord(t, s.words, testUserID, "pear", "груша")
+		other := mustSaveWord(t, s.words, testOtherUserID, "plum", "слива")
+
+		var saved []*Quiz
+		for _, q := range []*Quiz{
+			{UserID: testUserID, WordID: apple.ID, Correct: true},
+			{UserID: testUserID, WordID: pear.ID, Correct: false},
+			{UserID: testOtherUserID, WordID: other.ID, Correct: true},
+			{UserID: testUserID, WordID: apple.ID, Correct: false},
+		} {
+			if err := s.logs.SaveQuiz(q); err != nil {
+				t.Fatalf("Failed to save quiz: %v", err)
+			}
+			saved = append(saved, q)
+		}
+
+		recent, err := s.logs.GetUserQuizzes(testUserID, 2)
+		if err != nil {
+			t.Fatalf("Failed to get user quizzes: %v", err)
+		}
+		if len(recent) != 2 || recent[0].ID != saved[3].ID || recent[1].ID != saved[1].ID {
+			t.Errorf("Expected two newest quizzes of the user, got %+v", recent)
+		}
+
+		history, err := s.logs.GetWordQuizzes(apple.ID)
+		if err != nil {
+			t.Fatalf("Failed to get word quizzes: %v", err)
+		}
+		if len(history) != 2 || history[0].ID != saved[0].ID || history[1].ID != saved[3].ID {
+			t.Errorf("Expected apple history in chronological order, got %+v", history)
+		}
+	})
+
+	t.Run("DeleteWord removes its quiz history and sessions", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		if err := s.logs.SaveQuiz(&Quiz{UserID: testUserID, WordID: word.ID, Correct: true}); err != nil {
+			t.Fatalf("Failed to save quiz: %v", err)
+		}
+		mustCreateSession(t, s, "session-1", word.ID, time.Now().Add(time.Hour))
+
+		if err := s.words.DeleteWord(word.ID, testUserID); err != nil {
+			t.Fatalf("Failed to delete word with history: %v", err)
+		}
+
+		recent, err := s.logs.GetUserQuizzes(testUserID, 10)
+		if err != nil {
+			t.Fatalf("Failed to get user quizzes: %v", err)
+		}
+		if len(recent) != 0 {
+			t.Errorf("Expected history to be removed, got %d quizzes", len(recent))
+		}
+		if session, _ := s.sessions.GetQuizSession("session-1"); session != nil {
+			t.Error("Expected quiz session to be removed with the word")
+		}
+	})
+
+	t.Run("QuizSession round trip", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		session := mustCreateSession(t, s, "session-1", word.ID, time.Now().Add(time.Hour))
+
+		got, err := s.sessions.GetQuizSession(session.ID)
+		if err != nil || got == nil {
+			t.Fatalf("Expected session, got %v, %v", got, err)
+		}
+		if got.UserID != testUserID || got.WordID != word.ID || got.Question != session.Question ||
+			got.CorrectIdx != 2 || fmt.Sprint(got.Options) != fmt.Sprint(session.Options) {
+			t.Errorf("Expected %+v, got %+v", session, got)
+		}
+		if got.Answered() {
+			t.Error("Expected new session to be unanswered")
+		}
+		if got.CreatedAt.Sub(session.CreatedAt).Abs() > time.Millisecond {
+			t.Errorf("Expected created_at %v with sub-second precision, got %v", session.CreatedAt, got.CreatedAt)
+		}
+
+		missing, err := s.sessions.GetQuizSession("missing")
+		if err != nil || missing != nil {
+			t.Errorf("Expected nil for unknown session, got %v, %v", missing, err)
+		}
+	})
+
+	t.Run("MarkQuizSessionAnswered succeeds only once", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		session := mustCreateSession(t, s, "session-1", word.ID, time.Now().Add(time.Hour))
+
+		ok, err := s.sessions.MarkQuizSessionAnswered(session.ID, 1, time.Now())
+		if err != nil || !ok {
+			t.Fatalf("Expected first answer to be accepted, got %v, %v", ok, err)
+		}
+		ok, err = s.sessions.MarkQuizSessionAnswered(session.ID, 2, time.Now())
+		if err != nil || ok {
+			t.Errorf("Expected second answer to be rejected, got %v, %v", ok, err)
+		}
+
+		got, err := s.sessions.GetQuizSession(session.ID)
+		if err != nil {
+			t.Fatalf("Failed to get session: %v", err)
+		}
+		if !got.Answered() || got.ChosenIdx != 1 {
+			t.Errorf("Expected the first answer to be kept, got answered=%v chosen=%d", got.Answered(), got.ChosenIdx)
+		}
+
+		// После снятия отметки на вопрос можно ответить снова
+		if err := s.sessions.ClearQuizSessionAnswer(session.ID); err != nil {
+			t.Fatalf("Failed to clear answer: %v", err)
+		}
+		if got, _ := s.sessions.GetQuizSession(session.ID); got.Answered() {
+			t.Errorf("Expected the session to be unanswered after clearing")
+		}
+		if ok, err := s.sessions.MarkQuizSessionAnswered(session.ID, 2, time.Now()); err != nil || !ok {
+			t.Errorf("Expected the answer to be accepted again, got %v, %v", ok, err)
+		}
+	})
+
+	t.Run("SetQuizSessionPoll finds the session by poll", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		session := mustCreateSession(t, s, "session-1", word.ID, time.Now().Add(time.Hour))
+		other := mustCreateSession(t, s, "session-2", word.ID, time.Now().Add(time.Hour))
+
+		if got, err := s.sessions.GetQuizSessionByPoll("poll-1"); err != nil || got != nil {
+			t.Errorf("Expected nil before the poll is set, got %v, %v", got, err)
+		}
+
+		if err := s.sessions.SetQuizSessionPoll(session.ID, "poll-1"); err != nil {
+			t.Fatalf("Failed to set poll: %v", err)
+		}
+		got, err := s.sessions.GetQuizSessionByPoll("poll-1")
+		if err != nil || got == nil {
+			t.Fatalf("Expected session, got %v, %v", got, err)
+		}
+		if got.ID != session.ID || got.PollID != "poll-1" {
+			t.Errorf("Expected session %s with poll, got %+v", session.ID, got)
+		}
+
+		if err := s.sessions.SetQuizSessionPoll(other.ID, "poll-1"); err == nil {
+			t.Error("Expected error when the poll is used by another session")
+		}
+	})
+
+	t.Run("DeleteExpiredQuizSessions keeps active sessions", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		mustCreateSession(t, s, "expired", word.ID, time.Now().Add(-time.Minute))
+		mustCreateSession(t, s, "active", word.ID, time.Now().Add(time.Hour))
+
+		if err := s.sessions.DeleteExpiredQuizSessions(time.Now()); err != nil {
+			t.Fatalf("Failed to delete expired sessions: %v", err)
+		}
+
+		if got, _ := s.sessions.GetQuizSession("expired"); got != nil {
+			t.Error("Expected expired session to be deleted")
+		}
+		if got, _ := s.sessions.GetQuizSession("active"); got == nil {
+			t.Error("Expected active session to be kept")
+		}
+	})
+
+	t.Run("QuizRound keeps its sessions in order", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		apple := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+		pear := mustSaveWord(t, s.words, testUserID, "pear", "груша")
+
+		now := time.Now()
+		round := &QuizRound{ID: "round-1", UserID: testUserID, Size: 2, CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+			Mode: QuizModeMixed, TagID: 7, ChatID: -100123}
+		if err := s.sessions.CreateQuizRound(round); err != nil {
+			t.Fatalf("Failed to create round: %v", err)
+		}
+		for _, session := range []*QuizSession{
+			{ID: "second", UserID: testUserID, WordID: pear.ID, Options: []string{"a"}, RoundID: round.ID, Position: 1,
+				Direction: DirectionReverse, CreatedAt: now, ExpiresAt: round.ExpiresAt},
+			{ID: "first", UserID: testUserID, WordID: apple.ID, Options: []string{"a"}, RoundID: round.ID, Position: 0,
+				Direction: DirectionForward, CreatedAt: now, ExpiresAt: round.ExpiresAt},
+		} {
+			if err := s.sessions.CreateQuizSession(session); err != nil {
+				t.Fatalf("Failed to create session: %v", err)
+			}
+		}
+
+		before, after := now.AddDate(0, 0, 6), now.AddDate(0, 0, 1)
+		if err := s.sessions.SaveQuizSessionReview("first", before, after); err != nil {
+			t.Fatalf("Failed to save session review: %v", err)
+		}
+
+		sessions, err := s.sessions.GetRoundSessions(round.ID)
+		if err != nil {
+			t.Fatalf("Failed to get round sessions: %v", err)
+		}
+		if len(sessions) != 2 || sessions[0].ID != "first" || sessions[1].ID != "second" {
+			t.Fatalf("Expected sessions ordered by position, got %+v", sessions)
+		}
+		if sessions[0].RoundID != round.ID || sessions[0].NextReviewBefore.Sub(before).Abs() > time.Millisecond ||
+			sessions[0].NextReviewAfter.Sub(after).Abs() > time.Millisecond {
+			t.Errorf("Expected review change to be stored, got %+v", sessions[0])
+		}
+		if sessions[0].Direction != DirectionForward || sessions[1].Direction != DirectionReverse {
+			t.Errorf("Expected directions to be stored, got %q and %q", sessions[0].Direction, sessions[1].Direction)
+		}
+		if !sessions[1].NextReviewBefore.IsZero() {
+			t.Errorf("Expected unanswered session without review change, got %v", sessions[1].NextReviewBefore)
+		}
+
+		ok, err := s.sessions.FinishQuizRound(round.ID, true, now)
+		if err != nil || !ok {
+			t.Fatalf("Expected round to be finished, got %v, %v", ok, err)
+		}
+		if ok, _ := s.sessions.FinishQuizRound(round.ID, false, now); ok {
+			t.Error("Expected finished round not to be finished again")
+		}
+
+		got, err := s.sessions.GetQuizRound(round.ID)
+		if err != nil || got == nil {
+			t.Fatalf("Expected round, got %v, %v", got, err)
+		}
+		if !got.Finished() || !got.Aborted || got.Size != 2 || got.UserID != testUserID || got.Mode != QuizModeMixed ||
+			got.TagID != 7 || got.ChatID != -100123 {
+			t.Errorf("Unexpected round: %+v", got)
+		}
+	})
+
+	t.Run("DeleteExpiredQuizSessions removes expired rounds with their sessions", func(t *testing.T) {
+		s := newStores(t)
+		mustCreateUser(t, s.users, testUserID)
+		word := mustSaveWord(t, s.words, testUserID, "apple", "яблоко")
+
+		now := time.Now()
+		round := &QuizRound{ID: "round-1", UserID: testUserID, Size: 1, CreatedAt: now, ExpiresAt: now.Add(-time.Minute)}
+		if err := s.sessions.CreateQuizRound(round); err != nil {
+			t.Fatalf("Failed to create round: %v", err)
+		}
+		session := &QuizSession{ID: "in-round", UserID: testUserID, WordID: word.ID, Options: []string{"a"},
+			RoundID: round.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+		if err := s.sessions.CreateQuizSession(session); err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+
+		if err := s.sessions.DeleteExpiredQuizSessions(now); err != nil {
+			t.Fatalf("Failed to delete expired sessions: %v", err)
+		}
+		if got, _ := s.sessions.GetQuizRound(round.ID); got != nil {
+			t.Error("Expected expired round to be deleted")
+		}
+		if got, _ := s.sessions.GetQuizSession(session.ID); got != nil {
+			t.Error("Expected session of the expired round to be deleted")
+		}
+	})
+}
+
+func mustCreateUser(t *testing.T, users UserStore, userID int64) {
+	t.Helper()
+
+	err := users.CreateOrUpdateUser(&User{ID: userID, Username: "tester", FirstName: "Test", State: "idle"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+}
+
+func mustSaveWord(t *testing.T, words WordStore, userID int64, word, translation string) *Word {
+	t.Helper()
+
+	w := &Word{UserID: userID, Word: word, Translation: translation}
+	if err := words.SaveWord(w); err != nil {
+		t.Fatalf("Failed to save word %q: %v", word, err)
+	}
+	return w
+}
+
+func mustCreateSession(t *testing.T, s testStores, id string, wordID int, expiresAt time.Time) *QuizSession {
+	t.Helper()
+
+	session := &QuizSession{
+		ID: id, UserID: testUserID, WordID: wordID, Question: "Как переводится слово: apple?",
+		Options: []string{"груша", "слива", "яблоко", "лимон"}, CorrectIdx: 2,
+		CreatedAt: time.Now(), ExpiresAt: expiresAt,
+	}
+	if err := s.sessions.CreateQuizSession(session); err != nil {
+		t.Fatalf("Failed to create quiz session: %v", err)
+	}
+	return session
+}
+
+func wordsByID(t *testing.T, words WordStore, userID int64) map[int]*Word {
+	t.Helper()
+
+	list, err := words.GetUserWords(userID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	byID := make(map[int]*Word, len(list))
+	for _, w := range list {
+		byID[w.ID] = w
+	}
+	return byID
+}
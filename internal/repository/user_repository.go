@@ -7,12 +7,12 @@ import (
 
 // User представляет собой структуру пользователя
 type UserRepository struct {
-	db *sql.DB
+	db *Database
 }
 
 // User представляет собой структуру пользователя
 func NewUserRepository(database *Database) *UserRepository {
-	return &UserRepository{db: database.db}
+	return &UserRepository{db: database}
 }
 
 // CreateOrUpdateUser создает или обновляет пользователя
@@ -37,13 +37,14 @@ func (r *UserRepository) CreateOrUpdateUser(user *User) error {
 // GetUser получает пользователя по ID
 func (r *UserRepository) GetUser(userID int64) (*User, error) {
 	query := `
-		SELECT id, username, first_name, last_name, state, created_at
+		SELECT id, username, first_name, last_name, state, state_data, scheduler, quiz_mode, quiz_presentation, created_at
 		FROM users WHERE id = $1
 	`
 
 	user := &User{}
 	err := r.db.QueryRow(query, userID).Scan(
-		&user.ID, &user.Username, &user.FirstName, &user.LastName, &user.State, &user.CreatedAt,
+		&user.ID, &user.Username, &user.FirstName, &user.LastName, &user.State, &user.StateData,
+		&user.Scheduler, &user.QuizMode, &user.QuizPresentation, &user.CreatedAt,
 	)
 
 	if err != nil {
@@ -56,14 +57,55 @@ func (r *UserRepository) GetUser(userID int64) (*User, error) {
 	return user, nil
 }
 
-// UpdateUserState обновляет состояние пользователя
+// UpdateUserState обновляет состояние пользователя и сбрасывает данные прежнего состояния
 func (r *UserRepository) UpdateUserState(userID int64, state string) error {
-	query := `UPDATE users SET state = $1 WHERE id = $2`
+	return r.UpdateUserStateData(userID, state, "")
+}
+
+// UpdateUserStateData обновляет состояние пользователя вместе с его данными
+func (r *UserRepository) UpdateUserStateData(userID int64, state, data string) error {
+	query := `UPDATE users SET state = $1, state_data = $2 WHERE id = $3`
 
-	_, err := r.db.Exec(query, state, userID)
+	_, err := r.db.Exec(query, state, data, userID)
 	if err != nil {
 		return fmt.Errorf("failed to update user state: %w", err)
 	}
 
 	return nil
 }
+
+// UpdateUserScheduler сохраняет выбранный пользователем алгоритм повторения
+func (r *UserRepository) UpdateUserScheduler(userID int64, scheduler string) error {
+	query := `UPDATE users SET scheduler = $1 WHERE id = $2`
+
+	_, err := r.db.Exec(query, scheduler, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user scheduler: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateUserQuizMode сохраняет выбранный пользователем режим /quiz
+func (r *UserRepository) UpdateUserQuizMode(userID int64, mode string) error {
+	query := `UPDATE users SET quiz_mode = $1 WHERE id = $2`
+
+	_, err := r.db.Exec(query, mode, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user quiz mode: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateUserQuizPresentation сохраняет выбранный пользователем способ показа вопросов /quiz
+func (r *UserRepository) UpdateUserQuizPresentation(userID int64, presentation string) error {
+	query := `UPDATE users SET quiz_presentation = $1 WHERE id = $2`
+
+	_, err := r.db.Exec(query, presentation, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user quiz presentation: %w", err)
+	}
+
+	return nil
+}
@@ -4,42 +4,75 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/textutil"
 )
 
 // Word представляет собой структуру слова
 type WordRepository struct {
-	db *sql.DB
+	db *Database
 }
 
 // Word представляет собой структуру слова
 func NewWordRepository(database *Database) *WordRepository {
-	return &WordRepository{db: database.db}
+	return &WordRepository{db: database}
 }
 
-// SaveWord сохраняет новое слово
+// SaveWord сохраняет новое слово вместе с его тегами
 func (r *WordRepository) SaveWord(word *Word) error {
+	return r.SaveWords([]*Word{word})
+}
+
+// SaveWords сохраняет несколько слов в одной транзакции: либо все, либо ни одного
+func (r *WordRepository) SaveWords(words []*Word) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, word := range words {
+		if err := saveWord(tx, word); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit words: %w", err)
+	}
+
+	return nil
+}
+
+// saveWord добавляет слово с тегами и заполняет его ID и дату создания
+func saveWord(q querier, word *Word) error {
 	query := `
-		INSERT INTO words (user_id, word, translation, context, next_review)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO words (user_id, word, word_norm, translation, context, next_review, deck_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at
 	`
 
-	err := r.db.QueryRow(query, word.UserID, word.Word, word.Translation, word.Context, time.Now().AddDate(0, 0, 1)).
-		Scan(&word.ID, &word.CreatedAt)
+	err := q.QueryRow(query, word.UserID, word.Word, textutil.NormalizeWord(word.Word), word.Translation, word.Context,
+		time.Now().AddDate(0, 0, 1), word.DeckID).Scan(&word.ID, &word.CreatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to save word: %w", err)
 	}
 
-	return nil
+	return attachTags(q, word.UserID, word.ID, word.Tags)
 }
 
+// wordColumns перечисляет столбцы, которые читает scanWords
+const wordColumns = `id, user_id, word, translation, COALESCE(context, ''), created_at, last_review, next_review,
+	interval, difficulty, ease_factor, repetitions, lapses, stability, fsrs_difficulty, retrievability, deck_id`
+
 // GetUserWords получает все слова пользователя
 func (r *WordRepository) GetUserWords(userID int64) ([]*Word, error) {
-	query := `
-		SELECT id, user_id, word, translation, context, created_at, last_review, next_review, interval, difficulty
-		FROM words WHERE user_id = $1 ORDER BY created_at DESC
+	query := `SELECT ` + wordColumns + `
+		FROM words WHERE user_id = $1 ORDER BY created_at DESC, id DESC
 	`
 	log.Printf("Executing GetUserWords for user %d", userID) // Добавлено
 	rows, err := r.db.Query(query, userID)
@@ -49,26 +82,181 @@ func (r *WordRepository) GetUserWords(userID int64) ([]*Word, error) {
 	}
 	defer rows.Close()
 
-	var words []*Word
-	for rows.Next() {
-		word := &Word{}
-		err := rows.Scan(
-			&word.ID, &word.UserID, &word.Word, &word.Translation, &word.Context,
-			&word.CreatedAt, &word.LastReview, &word.NextReview, &word.Interval, &word.Difficulty,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan word: %w", err)
-		}
-		words = append(words, word)
+	words, err := scanWords(rows)
+	if err != nil {
+		return nil, err
 	}
 	log.Printf("Found %d words for user %d", len(words), userID) // Добавлено
 	return words, nil
 }
 
+// wordSortKey — ключ сортировки списка слов. Столбцы перечислены от старшего
+// к младшему, последним всегда идет id, поэтому ключ уникален и по нему можно
+// продолжать список с любого слова.
+type wordSortKey struct {
+	columns    []string
+	descending bool // Список идет по убыванию ключа
+}
+
+// wordSortKeys — ключи сортировки для каждого порядка WordSort*.
+// У «трудных» число забываний взято со знаком минус, чтобы весь ключ
+// шел по возрастанию: сначала чаще забываемые, затем с меньшей легкостью.
+var wordSortKeys = map[string]wordSortKey{
+	WordSortRecent:  {columns: []string{"created_at", "id"}, descending: true},
+	WordSortAlpha:   {columns: []string{"LOWER(word)", "id"}},
+	WordSortHardest: {columns: []string{"-lapses", "ease_factor", "id"}},
+	WordSortDue:     {columns: []string{"next_review", "id"}},
+}
+
+// wordListFilter возвращает условие WHERE и аргументы для страницы списка слов.
+// Слова до FromID выбираются строгим сравнением ключей, слова начиная с FromID — нестрогим.
+// FromID ищется только среди слов пользователя; если его нет (например, слово
+// удалено), страница начинается с начала списка, а перед ней слов нет.
+func wordListFilter(q WordListQuery, key wordSortKey) (string, []any) {
+	where := "user_id = $1"
+	args := []any{q.UserID}
+	if q.TagID != 0 {
+		args = append(args, q.TagID)
+		where += fmt.Sprintf(" AND id IN (SELECT word_id FROM word_tags WHERE tag_id = $%d)", len(args))
+	}
+	if q.DeckID != 0 {
+		args = append(args, q.DeckID)
+		where += fmt.Sprintf(" AND deck_id = $%d", len(args))
+	}
+	if !q.DueBy.IsZero() {
+		args = append(args, q.DueBy)
+		where += fmt.Sprintf(" AND next_review <= $%d", len(args))
+	}
+	if q.FromID == 0 {
+		return where, args
+	}
+
+	// Сравнение строк ключей: в обоих диалектах (a, b) < (SELECT x, y ...) сравнивает
+	// лексикографически, что и нужно для продолжения списка
+	var op string
+	switch {
+	case !q.Backward && !key.descending:
+		op = ">="
+	case !q.Backward && key.descending:
+		op = "<="
+	case q.Backward && !key.descending:
+		op = "<"
+	default:
+		op = ">"
+	}
+
+	args = append(args, q.FromID)
+	anchor := fmt.Sprintf("FROM words WHERE id = $%d AND user_id = $1", len(args))
+	columns := strings.Join(key.columns, ", ")
+	// Без строки FromID подзапрос дает NULL и сравнение ничего не выбирает:
+	// назад это и нужно, а вперед список начинается сначала
+	compare := fmt.Sprintf("(%s) %s (SELECT %s %s)", columns, op, columns, anchor)
+	if !q.Backward {
+		compare = fmt.Sprintf("(NOT EXISTS (SELECT 1 %s) OR %s)", anchor, compare)
+	}
+	return where + " AND " + compare, args
+}
+
+// ListWords возвращает страницу слов пользователя в порядке показа
+func (r *WordRepository) ListWords(q WordListQuery) ([]*Word, error) {
+	key, ok := wordSortKeys[q.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown word sort %q", q.Sort)
+	}
+
+	where, args := wordListFilter(q, key)
+
+	// Назад список читается в обратном порядке от FromID, а затем разворачивается
+	direction := " ASC"
+	if key.descending != q.Backward {
+		direction = " DESC"
+	}
+	order := make([]string, len(key.columns))
+	for i, column := range key.columns {
+		order[i] = column + direction
+	}
+
+	query := `SELECT ` + wordColumns + ` FROM words WHERE ` + where + ` ORDER BY ` + strings.Join(order, ", ")
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list words: %w", err)
+	}
+	defer rows.Close()
+
+	words, err := scanWords(rows)
+	if err != nil {
+		return nil, err
+	}
+	if q.Backward {
+		slices.Reverse(words)
+	}
+	return words, nil
+}
+
+// CountWords возвращает, сколько слов выбрал бы запрос без ограничения Limit
+func (r *WordRepository) CountWords(q WordListQuery) (int, error) {
+	key, ok := wordSortKeys[q.Sort]
+	if !ok {
+		return 0, fmt.Errorf("unknown word sort %q", q.Sort)
+	}
+
+	where, args := wordListFilter(q, key)
+
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM words WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count words: %w", err)
+	}
+	return count, nil
+}
+
+// SearchWords ищет слова пользователя по слову, переводу и контексту с учетом
+// опечаток и возвращает не больше limit лучших совпадений. В Postgres поиск идет
+// по триграммным и полнотекстовому индексам, в SQLite — перебором в Go.
+func (r *WordRepository) SearchWords(userID int64, query string, limit int) ([]*Word, error) {
+	if r.db.driver == DriverSQLite {
+		words, err := r.GetUserWords(userID)
+		if err != nil {
+			return nil, err
+		}
+		return rankSearch(words, query, limit), nil
+	}
+
+	// Выражение to_tsvector совпадает с индексом words_search_idx, а ILIKE и %
+	// используют триграммные индексы words_*_trgm_idx
+	sqlQuery := `SELECT ` + wordColumns + `
+		FROM words
+		WHERE user_id = $1 AND (
+			to_tsvector('simple', word || ' ' || translation || ' ' || COALESCE(context, ''))
+				@@ plainto_tsquery('simple', $2)
+			OR word ILIKE $3 OR translation ILIKE $3
+			OR word % $2 OR translation % $2
+		)
+		ORDER BY GREATEST(similarity(word, $2), similarity(translation, $2)) DESC, id DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.Query(sqlQuery, userID, query, "%"+escapeLike(query)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search words: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWords(rows)
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // GetWordsForReview получает слова для повторения
 func (r *WordRepository) GetWordsForReview(userID int64) ([]*Word, error) {
-	query := `
-		SELECT id, user_id, word, translation, context, created_at, last_review, next_review, interval, difficulty
+	query := `SELECT ` + wordColumns + `
 		FROM words WHERE user_id = $1 AND next_review <= $2 ORDER BY next_review ASC LIMIT 10
 	`
 
@@ -78,68 +266,275 @@ func (r *WordRepository) GetWordsForReview(userID int64) ([]*Word, error) {
 	}
 	defer rows.Close()
 
-	var words []*Word
-	for rows.Next() {
-		word := &Word{}
-		err := rows.Scan(
-			&word.ID, &word.UserID, &word.Word, &word.Translation, &word.Context,
-			&word.CreatedAt, &word.LastReview, &word.NextReview, &word.Interval, &word.Difficulty,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan word: %w", err)
-		}
-		words = append(words, word)
+	return scanWords(rows)
+}
+
+// GetWord получает слово по ID
+func (r *WordRepository) GetWord(wordID int) (*Word, error) {
+	rows, err := r.db.Query(`SELECT `+wordColumns+` FROM words WHERE id = $1`, wordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get word: %w", err)
 	}
+	defer rows.Close()
 
-	return words, nil
+	words, err := scanWords(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, nil // Слово не найдено
+	}
+
+	return words[0], nil
 }
 
-// UpdateWordReview обновляет информацию о повторении слова
-func (r *WordRepository) UpdateWordReview(wordID int, correct bool) error {
-	// Получаем текущее слово
-	var interval, difficulty int
-	query := `SELECT interval, difficulty FROM words WHERE id = $1`
-	err := r.db.QueryRow(query, wordID).Scan(&interval, &difficulty)
+// GetWordByNorm получает слово пользователя по нормализованному написанию norm
+// (см. textutil.NormalizeWord). Если дубликаты еще не объединены, возвращается
+// самое раннее слово.
+func (r *WordRepository) GetWordByNorm(userID int64, norm string) (*Word, error) {
+	query := `SELECT ` + wordColumns + ` FROM words WHERE user_id = $1 AND word_norm = $2 ORDER BY id LIMIT 1`
+	rows, err := r.db.Query(query, userID, norm)
 	if err != nil {
-		return fmt.Errorf("failed to get word for update: %w", err)
+		return nil, fmt.Errorf("failed to get word: %w", err)
 	}
+	defer rows.Close()
 
-	// Алгоритм интервального повторения (упрощенный SM-2)
-	if correct {
-		if interval == 1 {
-			interval = 6
-		} else {
-			interval = int(float64(interval) * 2.5)
-		}
-		if difficulty > 0 {
-			difficulty--
+	words, err := scanWords(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, nil // Слово не найдено
+	}
+
+	return words[0], nil
+}
+
+// SaveReviewState сохраняет параметры повторения слова
+func (r *WordRepository) SaveReviewState(wordID int, state ReviewState) error {
+	return saveReviewState(r.db, wordID, state)
+}
+
+// SaveReviewStates сохраняет параметры повторения нескольких слов в одной транзакции
+func (r *WordRepository) SaveReviewStates(states map[int]ReviewState) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for wordID, state := range states {
+		if err := saveReviewState(tx, wordID, state); err != nil {
+			return err
 		}
-	} else {
-		interval = 1
-		if difficulty < 5 {
-			difficulty++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit review states: %w", err)
+	}
+
+	return nil
+}
+
+// ResetReviewState в одной транзакции записывает слову состояние state в прямом
+// направлении и удаляет его историю в обратном, чтобы слово начиналось заново
+func (r *WordRepository) ResetReviewState(wordID int, state ReviewState) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveReviewState(tx, wordID, state); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM reverse_reviews WHERE word_id = $1`, wordID); err != nil {
+		return fmt.Errorf("failed to delete reverse review state: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit review reset: %w", err)
+	}
+
+	return nil
+}
+
+// saveReviewState обновляет столбцы повторения слова
+func saveReviewState(q querier, wordID int, state ReviewState) error {
+	result, err := q.Exec(reviewStateUpdate, reviewStateArgs(wordID, state)...)
+	if err != nil {
+		return fmt.Errorf("failed to update word review: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("failed to update word review: word %d not found", wordID)
+	}
+
+	return nil
+}
+
+// reviewStateUpdate записывает параметры повторения слова; аргументы собирает reviewStateArgs
+const reviewStateUpdate = `
+	UPDATE words SET
+		last_review = $1,
+		next_review = $2,
+		interval = $3,
+		difficulty = $4,
+		ease_factor = $5,
+		repetitions = $6,
+		lapses = $7,
+		stability = $8,
+		fsrs_difficulty = $9,
+		retrievability = $10
+	WHERE id = $11
+`
+
+// reviewStateArgs возвращает аргументы запроса reviewStateUpdate
+func reviewStateArgs(wordID int, state ReviewState) []any {
+	return []any{state.LastReview, state.NextReview, state.Interval, state.Difficulty,
+		state.EaseFactor, state.Repetitions, state.Lapses,
+		state.Stability, state.FSRSDifficulty, state.Retrievability, wordID}
+}
+
+// SaveDueReviewState сохраняет параметры повторения слова, только если его пора
+// повторять (next_review не позже now), и сообщает, сохранены ли они. Проверка
+// и запись идут одним запросом, поэтому из двух одновременных оценок одной
+// карточки проходит только первая.
+func (r *WordRepository) SaveDueReviewState(wordID int, state ReviewState, now time.Time) (bool, error) {
+	result, err := r.db.Exec(reviewStateUpdate+` AND next_review <= $12`, append(reviewStateArgs(wordID, state), now)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update word review: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// GetReverseReviewState возвращает состояние слова в обратном направлении
+// или nil, если в этом направлении слово еще не повторялось
+func (r *WordRepository) GetReverseReviewState(wordID int) (*ReviewState, error) {
+	rows, err := r.db.Query(`SELECT `+reverseColumns+` FROM reverse_reviews WHERE word_id = $1`, wordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reverse review state: %w", err)
+	}
+	defer rows.Close()
+
+	states, err := scanReverseStates(rows)
+	if err != nil {
+		return nil, err
+	}
+	state, ok := states[wordID]
+	if !ok {
+		return nil, nil // Слово еще не повторялось в обратном направлении
+	}
+
+	return &state, nil
+}
+
+// GetReverseReviewStates возвращает состояния слов пользователя в обратном направлении
+func (r *WordRepository) GetReverseReviewStates(userID int64) (map[int]ReviewState, error) {
+	query := `SELECT ` + reverseColumns + `
+		FROM reverse_reviews WHERE word_id IN (SELECT id FROM words WHERE user_id = $1)
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reverse review states: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReverseStates(rows)
+}
+
+// SaveReverseReviewState сохраняет состояние слова в обратном направлении
+func (r *WordRepository) SaveReverseReviewState(wordID int, state ReviewState) error {
+	return saveReverseReviewState(r.db, wordID, state)
+}
+
+// SaveReverseReviewStates сохраняет состояния нескольких слов в обратном направлении
+// в одной транзакции
+func (r *WordRepository) SaveReverseReviewStates(states map[int]ReviewState) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for wordID, state := range states {
+		if err := saveReverseReviewState(tx, wordID, state); err != nil {
+			return err
 		}
 	}
 
-	nextReview := time.Now().AddDate(0, 0, interval)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reverse review states: %w", err)
+	}
+
+	return nil
+}
 
-	updateQuery := `
-		UPDATE words SET 
-			last_review = $1, 
-			next_review = $2, 
-			interval = $3, 
-			difficulty = $4 
-		WHERE id = $5
+// reverseColumns перечисляет столбцы, которые читает scanReverseStates
+const reverseColumns = `word_id, last_review, next_review, interval, difficulty, ease_factor, repetitions, lapses,
+	stability, fsrs_difficulty, retrievability`
+
+// saveReverseReviewState создает или обновляет строку reverse_reviews
+func saveReverseReviewState(q querier, wordID int, state ReviewState) error {
+	query := `
+		INSERT INTO reverse_reviews (` + reverseColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (word_id) DO UPDATE SET
+			last_review = EXCLUDED.last_review,
+			next_review = EXCLUDED.next_review,
+			interval = EXCLUDED.interval,
+			difficulty = EXCLUDED.difficulty,
+			ease_factor = EXCLUDED.ease_factor,
+			repetitions = EXCLUDED.repetitions,
+			lapses = EXCLUDED.lapses,
+			stability = EXCLUDED.stability,
+			fsrs_difficulty = EXCLUDED.fsrs_difficulty,
+			retrievability = EXCLUDED.retrievability
 	`
 
-	_, err = r.db.Exec(updateQuery, time.Now(), nextReview, interval, difficulty, wordID)
+	_, err := q.Exec(query, wordID, state.LastReview, state.NextReview, state.Interval, state.Difficulty,
+		state.EaseFactor, state.Repetitions, state.Lapses,
+		state.Stability, state.FSRSDifficulty, state.Retrievability)
 	if err != nil {
-		return fmt.Errorf("failed to update word review: %w", err)
+		return fmt.Errorf("failed to save reverse review state: %w", err)
 	}
 
 	return nil
 }
 
+// scanReverseStates читает строки, выбранные со столбцами reverseColumns
+func scanReverseStates(rows *sql.Rows) (map[int]ReviewState, error) {
+	states := make(map[int]ReviewState)
+	for rows.Next() {
+		var wordID int
+		var state ReviewState
+		err := rows.Scan(&wordID, &state.LastReview, &state.NextReview, &state.Interval, &state.Difficulty,
+			&state.EaseFactor, &state.Repetitions, &state.Lapses,
+			&state.Stability, &state.FSRSDifficulty, &state.Retrievability)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reverse review state: %w", err)
+		}
+		states[wordID] = state
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reverse review states: %w", err)
+	}
+
+	return states, nil
+}
+
 // DeleteWord удаляет слово
 func (r *WordRepository) DeleteWord(wordID int, userID int64) error {
 	query := `DELETE FROM words WHERE id = $1 AND user_id = $2`
@@ -160,3 +555,153 @@ func (r *WordRepository) DeleteWord(wordID int, userID int64) error {
 
 	return nil
 }
+
+// UpdateWord сохраняет написание, перевод и контекст слова пользователя.
+// Параметры повторения не меняются.
+func (r *WordRepository) UpdateWord(word *Word) error {
+	return updateWord(r.db, word)
+}
+
+// updateWord обновляет текстовые поля слова, принадлежащего word.UserID
+func updateWord(q querier, word *Word) error {
+	query := `UPDATE words SET word = $1, word_norm = $2, translation = $3, context = $4 WHERE id = $5 AND user_id = $6`
+
+	result, err := q.Exec(query, word.Word, textutil.NormalizeWord(word.Word), word.Translation, word.Context,
+		word.ID, word.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update word: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("word not found or not owned by user")
+	}
+
+	return nil
+}
+
+// MergeWords в одной транзакции сохраняет текстовые поля keep, переносит на него
+// журнал ответов и теги слов duplicateIDs и удаляет эти слова. Расписание keep остается
+// прежним, состояния удаленных слов удаляются вместе с ними.
+func (r *WordRepository) MergeWords(keep *Word, duplicateIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateWord(tx, keep); err != nil {
+		return err
+	}
+
+	for _, wordID := range duplicateIDs {
+		if wordID == keep.ID {
+			return fmt.Errorf("cannot merge word %d into itself", wordID)
+		}
+		if _, err := tx.Exec(`UPDATE quizzes SET word_id = $1 WHERE word_id = $2`, keep.ID, wordID); err != nil {
+			return fmt.Errorf("failed to move review log: %w", err)
+		}
+		_, err := tx.Exec(`
+			INSERT INTO word_tags (word_id, tag_id)
+			SELECT $1, tag_id FROM word_tags WHERE word_id = $2
+			ON CONFLICT (word_id, tag_id) DO NOTHING
+		`, keep.ID, wordID)
+		if err != nil {
+			return fmt.Errorf("failed to move word tags: %w", err)
+		}
+
+		result, err := tx.Exec(`DELETE FROM words WHERE id = $1 AND user_id = $2`, wordID, keep.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to delete merged word: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("word %d not found or not owned by user", wordID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit merge: %w", err)
+	}
+
+	return nil
+}
+
+// fillWordNorm заполняет word_norm у слов, сохраненных до миграции 0014_word_norm:
+// у них столбец пустой, а у новых слов его пишут saveWord и updateWord. Дубликаты
+// при этом не объединяются.
+func (d *Database) fillWordNorm() error {
+	rows, err := d.Query(`SELECT id, word FROM words WHERE word_norm = ''`)
+	if err != nil {
+		return fmt.Errorf("failed to get words: %w", err)
+	}
+	norms := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var word string
+		if err := rows.Scan(&id, &word); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan word: %w", err)
+		}
+		norms[id] = textutil.NormalizeWord(word)
+	}
+	// Строки закрываются до записи: у SQLite одно соединение
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read words: %w", err)
+	}
+	if len(norms) == 0 {
+		return nil
+	}
+
+	tx, err := d.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for id, norm := range norms {
+		if _, err := tx.Exec(`UPDATE words SET word_norm = $1 WHERE id = $2`, norm, id); err != nil {
+			return fmt.Errorf("failed to save normalized word: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit normalized words: %w", err)
+	}
+
+	log.Printf("Normalized %d words", len(norms))
+	return nil
+}
+
+// scanWords читает строки, выбранные со столбцами wordColumns
+func scanWords(rows *sql.Rows) ([]*Word, error) {
+	var words []*Word
+	for rows.Next() {
+		word := &Word{}
+		err := rows.Scan(
+			&word.ID, &word.UserID, &word.Word, &word.Translation, &word.Context,
+			&word.CreatedAt, &word.LastReview, &word.NextReview, &word.Interval, &word.Difficulty,
+			&word.EaseFactor, &word.Repetitions, &word.Lapses,
+			&word.Stability, &word.FSRSDifficulty, &word.Retrievability, &word.DeckID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan word: %w", err)
+		}
+		words = append(words, word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read words: %w", err)
+	}
+
+	return words, nil
+}
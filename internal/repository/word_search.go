@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/textutil"
+)
+
+// Веса полей слова при поиске без индексов Postgres: совпадение в самом
+// слове важнее совпадения в переводе, а в переводе — важнее, чем в контексте
+const (
+	searchWeightWord        = 3
+	searchWeightTranslation = 2
+	searchWeightContext     = 1
+)
+
+// rankSearch отбирает слова, подходящие под запрос, и сортирует их по убыванию
+// оценки. Используется для SQLite и хранилища в памяти, где нет pg_trgm.
+func rankSearch(words []*Word, query string, limit int) []*Word {
+	scores := make(map[int]float64, len(words))
+	var found []*Word
+	for _, word := range words {
+		if score := searchScore(word, query); score > 0 {
+			scores[word.ID] = score
+			found = append(found, word)
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if scores[found[i].ID] != scores[found[j].ID] {
+			return scores[found[i].ID] > scores[found[j].ID]
+		}
+		return found[i].ID > found[j].ID
+	})
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	return found
+}
+
+// searchScore оценивает, насколько слово подходит под запрос; 0 — не подходит.
+// Запрос целиком ищется как подстрока полей, а если не нашелся — каждое его
+// слово должно совпасть с началом слова в одном из полей или отличаться от
+// него парой опечаток.
+func searchScore(word *Word, query string) float64 {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return 0
+	}
+
+	fields := []struct {
+		text   string
+		weight float64
+	}{
+		{strings.ToLower(word.Word), searchWeightWord},
+		{strings.ToLower(word.Translation), searchWeightTranslation},
+		{strings.ToLower(word.Context), searchWeightContext},
+	}
+
+	score := 0.0
+	for _, field := range fields {
+		switch {
+		case field.text == query:
+			score += field.weight * 3
+		case strings.Contains(field.text, query):
+			score += field.weight * 2
+		}
+	}
+	if score > 0 {
+		return score
+	}
+
+	for _, term := range strings.Fields(query) {
+		best := 0.0
+		for _, field := range fields {
+			for _, token := range strings.Fields(field.text) {
+				best = max(best, field.weight*termSimilarity(term, token))
+			}
+		}
+		if best == 0 {
+			return 0 // Каждое слово запроса должно где-то найтись
+		}
+		score += best
+	}
+	return score
+}
+
+// termSimilarity сравнивает слово запроса со словом поля: 1 — начало слова,
+// меньше — совпадение с опечатками, 0 — слова разные
+func termSimilarity(term, token string) float64 {
+	if strings.HasPrefix(token, term) {
+		return 1
+	}
+
+	n := len([]rune(term))
+	allowed := 0
+	switch {
+	case n > 6:
+		allowed = 2
+	case n > 3:
+		allowed = 1
+	}
+	if allowed == 0 {
+		return 0
+	}
+
+	distance := textutil.Levenshtein(term, token)
+	if distance > allowed {
+		return 0
+	}
+	return 1 - float64(distance)/float64(n+1)
+}
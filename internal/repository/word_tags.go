@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// AddWordTags добавляет слову пользователя теги tags. Новые теги создаются,
+// а теги, которые у слова уже есть, пропускаются.
+func (r *WordRepository) AddWordTags(userID int64, wordID int, tags []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkWordOwner(tx, userID, wordID); err != nil {
+		return err
+	}
+	if err := attachTags(tx, userID, wordID, tags); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit word tags: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveWordTags снимает со слова пользователя теги tags. Сами теги остаются,
+// но без слов они не показываются в GetUserTags.
+func (r *WordRepository) RemoveWordTags(userID int64, wordID int, tags []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkWordOwner(tx, userID, wordID); err != nil {
+		return err
+	}
+	for _, name := range tags {
+		_, err := tx.Exec(`
+			DELETE FROM word_tags
+			WHERE word_id = $1 AND tag_id IN (SELECT id FROM tags WHERE user_id = $2 AND name = $3)
+		`, wordID, userID, name)
+		if err != nil {
+			return fmt.Errorf("failed to remove word tag: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit word tags: %w", err)
+	}
+
+	return nil
+}
+
+// GetWordTags возвращает имена тегов каждого слова пользователя по алфавиту.
+// Слов без тегов в результате нет.
+func (r *WordRepository) GetWordTags(userID int64) (map[int][]string, error) {
+	rows, err := r.db.Query(`
+		SELECT wt.word_id, t.name
+		FROM word_tags wt JOIN tags t ON t.id = wt.tag_id
+		WHERE t.user_id = $1
+		ORDER BY wt.word_id, t.name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get word tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]string)
+	for rows.Next() {
+		var wordID int
+		var name string
+		if err := rows.Scan(&wordID, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan word tag: %w", err)
+		}
+		tags[wordID] = append(tags[wordID], name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read word tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// GetUserTags возвращает теги пользователя, у которых есть слова, по алфавиту
+func (r *WordRepository) GetUserTags(userID int64) ([]*Tag, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.user_id, t.name, COUNT(wt.word_id)
+		FROM tags t JOIN word_tags wt ON wt.tag_id = t.id
+		WHERE t.user_id = $1
+		GROUP BY t.id, t.user_id, t.name
+		ORDER BY t.name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []*Tag
+	for rows.Next() {
+		tag := &Tag{}
+		if err := rows.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Words); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// GetTagByName получает тег пользователя по имени или nil, если его нет
+func (r *WordRepository) GetTagByName(userID int64, name string) (*Tag, error) {
+	return getTag(r.db, `t.user_id = $1 AND t.name = $2`, userID, name)
+}
+
+// GetTag получает тег по ID или nil, если его нет
+func (r *WordRepository) GetTag(tagID int) (*Tag, error) {
+	return getTag(r.db, `t.id = $1`, tagID)
+}
+
+// getTag выбирает один тег с числом его слов по условию where
+func getTag(q querier, where string, args ...any) (*Tag, error) {
+	query := `
+		SELECT t.id, t.user_id, t.name, (SELECT COUNT(*) FROM word_tags wt WHERE wt.tag_id = t.id)
+		FROM tags t WHERE ` + where
+
+	tag := &Tag{}
+	err := q.QueryRow(query, args...).Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Words)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Тег не найден
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// attachTags создает недостающие теги пользователя и связывает их со словом
+func attachTags(q querier, userID int64, wordID int, tags []string) error {
+	for _, name := range tags {
+		_, err := q.Exec(`INSERT INTO tags (user_id, name) VALUES ($1, $2) ON CONFLICT (user_id, name) DO NOTHING`,
+			userID, name)
+		if err != nil {
+			return fmt.Errorf("failed to save tag: %w", err)
+		}
+
+		_, err = q.Exec(`
+			INSERT INTO word_tags (word_id, tag_id)
+			SELECT $1, id FROM tags WHERE user_id = $2 AND name = $3
+			ON CONFLICT (word_id, tag_id) DO NOTHING
+		`, wordID, userID, name)
+		if err != nil {
+			return fmt.Errorf("failed to tag word: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkWordOwner проверяет, что слово wordID принадлежит пользователю
+func checkWordOwner(q querier, userID int64, wordID int) error {
+	var owner int64
+	err := q.QueryRow(`SELECT user_id FROM words WHERE id = $1`, wordID).Scan(&owner)
+	if err == sql.ErrNoRows || (err == nil && owner != userID) {
+		return fmt.Errorf("word not found or not owned by user")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get word: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// Шаги пошагового добавления слова. Шаг хранится в users.state,
+// а уже введенные поля — в users.state_data, поэтому диалог
+// продолжается и после перезапуска бота.
+const (
+	AddStepWord        = "add:word"
+	AddStepTranslation = "add:translation"
+	AddStepContext     = "add:context"
+	// Слово уже есть в словаре: ждем выбора, что с ним сделать
+	AddStepDuplicate = "add:duplicate"
+)
+
+// Ошибки пошагового добавления слова
+var (
+	ErrNoAddWizard       = errors.New("add wizard is not active")
+	ErrAddFieldRequired  = errors.New("field is required")
+	ErrAddChoiceRequired = errors.New("choose what to do with the duplicate")
+)
+
+// AddWizardService ведет диалог добавления слова: слово, перевод, контекст
+type AddWizardService struct {
+	wordService *WordService
+	userService *UserService
+}
+
+// NewAddWizardService создает сервис пошагового добавления слова
+func NewAddWizardService(wordService *WordService, userService *UserService) *AddWizardService {
+	return &AddWizardService{wordService: wordService, userService: userService}
+}
+
+// AddDraft — уже введенные поля слова
+type AddDraft struct {
+	Word        string `json:"word"`
+	Translation string `json:"translation"`
+	Context     string `json:"context,omitempty"`
+	// Теги, указанные в любом из полей как #tag
+	Tags []string `json:"tags,omitempty"`
+	// Слово словаря, с которым совпал черновик, на шаге AddStepDuplicate
+	DuplicateID int `json:"duplicate_id,omitempty"`
+}
+
+// AddWizardStep описывает состояние диалога после очередного ввода
+type AddWizardStep struct {
+	Step  string // Следующий шаг; пустой, если слово сохранено
+	Draft AddDraft
+	// Совпавшее слово словаря на шаге AddStepDuplicate
+	Existing *repository.Word
+}
+
+// Done сообщает, что слово сохранено и диалог завершен
+func (s *AddWizardStep) Done() bool {
+	return s.Step == ""
+}
+
+// IsAddWizardState сообщает, что пользователь сейчас добавляет слово по шагам
+func IsAddWizardState(state string) bool {
+	return state == AddStepWord || state == AddStepTranslation || state == AddStepContext ||
+		state == AddStepDuplicate
+}
+
+// Start начинает диалог с первого шага, отбрасывая прежний черновик
+func (s *AddWizardService) Start(userID int64) error {
+	return s.userService.UpdateUserStateData(userID, AddStepWord, "")
+}
+
+// Input принимает ответ на текущий шаг и переходит к следующему.
+// На последнем шаге слово сохраняется, а состояние сбрасывается.
+func (s *AddWizardService) Input(userID int64, text string) (*AddWizardStep, error) {
+	step, draft, err := s.current(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if step == AddStepDuplicate {
+		return nil, ErrAddChoiceRequired
+	}
+
+	text, tags := ExtractTags(text)
+	draft.Tags = appendTags(draft.Tags, tags)
+	if text == "" && step != AddStepContext {
+		return nil, ErrAddFieldRequired
+	}
+
+	switch step {
+	case AddStepWord:
+		draft.Word = text
+		return s.advance(userID, AddStepTranslation, draft)
+	case AddStepTranslation:
+		draft.Translation = text
+		return s.advance(userID, AddStepContext, draft)
+	default:
+		draft.Context = text
+		return s.finish(userID, draft)
+	}
+}
+
+// Skip пропускает необязательный шаг. Пропустить можно только контекст.
+func (s *AddWizardService) Skip(userID int64) (*AddWizardStep, error) {
+	step, draft, err := s.current(userID)
+	if err != nil {
+		return nil, err
+	}
+	if step != AddStepContext {
+		return nil, ErrAddFieldRequired
+	}
+
+	return s.finish(userID, draft)
+}
+
+// Add сохраняет слово, введенное целиком одной командой. Если такое слово уже
+// есть, черновик запоминается и диалог переходит к шагу AddStepDuplicate.
+func (s *AddWizardService) Add(userID int64, draft AddDraft) (*AddWizardStep, error) {
+	err := s.wordService.AddWord(userID, draft.Word, draft.Translation, draft.Context, draft.Tags...)
+
+	var duplicate *DuplicateWordError
+	if errors.As(err, &duplicate) {
+		draft.DuplicateID = duplicate.Existing.ID
+		step, err := s.advance(userID, AddStepDuplicate, draft)
+		if err != nil {
+			return nil, err
+		}
+		step.Existing = duplicate.Existing
+		return step, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &AddWizardStep{Draft: draft}, nil
+}
+
+// ResolveDuplicate применяет выбор пользователя (DuplicateKeep, DuplicateAlternative
+// или DuplicateReplace) к совпавшему слову, завершает диалог и возвращает слово.
+// Если слово изменено, ему достаются и теги черновика.
+func (s *AddWizardService) ResolveDuplicate(userID int64, choice string) (*repository.Word, error) {
+	step, draft, err := s.current(userID)
+	if err != nil {
+		return nil, err
+	}
+	if step != AddStepDuplicate {
+		return nil, ErrNoAddWizard
+	}
+
+	word, err := s.wordService.ResolveDuplicate(userID, draft.DuplicateID, choice, draft.Translation, draft.Context)
+	if err != nil {
+		return nil, err
+	}
+	if choice != DuplicateKeep && len(draft.Tags) > 0 {
+		if word.Tags, err = s.wordService.AddTags(userID, word.ID, draft.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.userService.UpdateUserState(userID, StateIdle); err != nil {
+		return nil, err
+	}
+
+	return word, nil
+}
+
+// Cancel прерывает диалог без сохранения слова
+func (s *AddWizardService) Cancel(userID int64) error {
+	if _, _, err := s.current(userID); err != nil {
+		return err
+	}
+	return s.userService.UpdateUserState(userID, StateIdle)
+}
+
+// current возвращает текущий шаг и черновик пользователя
+func (s *AddWizardService) current(userID int64) (string, AddDraft, error) {
+	var draft AddDraft
+
+	user, err := s.userService.GetUser(userID)
+	if err != nil {
+		return "", draft, err
+	}
+	if user == nil || !IsAddWizardState(user.State) {
+		return "", draft, ErrNoAddWizard
+	}
+
+	if user.StateData != "" {
+		if err := json.Unmarshal([]byte(user.StateData), &draft); err != nil {
+			return "", draft, fmt.Errorf("failed to decode add draft: %w", err)
+		}
+	}
+
+	return user.State, draft, nil
+}
+
+// advance сохраняет черновик и переходит к шагу step
+func (s *AddWizardService) advance(userID int64, step string, draft AddDraft) (*AddWizardStep, error) {
+	data, err := json.Marshal(draft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode add draft: %w", err)
+	}
+	if err := s.userService.UpdateUserStateData(userID, step, string(data)); err != nil {
+		return nil, err
+	}
+
+	return &AddWizardStep{Step: step, Draft: draft}, nil
+}
+
+// finish сохраняет слово и завершает диалог, если слово не оказалось дубликатом
+func (s *AddWizardService) finish(userID int64, draft AddDraft) (*AddWizardStep, error) {
+	step, err := s.Add(userID, draft)
+	if err != nil || !step.Done() {
+		return step, err
+	}
+	if err := s.userService.UpdateUserState(userID, StateIdle); err != nil {
+		return nil, err
+	}
+
+	return step, nil
+}
+
+// appendTags добавляет к тегам черновика новые, пропуская повторы
+func appendTags(tags, extra []string) []string {
+	for _, tag := range extra {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
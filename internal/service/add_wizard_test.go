@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+// newTestAddWizard создает сервис пошагового добавления поверх хранилища в памяти
+func newTestAddWizard(t *testing.T) (*AddWizardService, *UserService, *WordService) {
+	t.Helper()
+
+	userService, wordService := newTestServices(t)
+	return NewAddWizardService(wordService, userService), userService, wordService
+}
+
+func TestAddWizardService_FullFlow(t *testing.T) {
+	wizard, userService, wordService := newTestAddWizard(t)
+
+	if err := wizard.Start(testUserID); err != nil {
+		t.Fatalf("Failed to start wizard: %v", err)
+	}
+	if _, err := wizard.Input(testUserID, "  "); !errors.Is(err, ErrAddFieldRequired) {
+		t.Errorf("Expected ErrAddFieldRequired for empty word, got %v", err)
+	}
+
+	step, err := wizard.Input(testUserID, " apple ")
+	if err != nil || step.Step != AddStepTranslation {
+		t.Fatalf("Expected translation step, got %+v, %v", step, err)
+	}
+	if _, err := wizard.Skip(testUserID); !errors.Is(err, ErrAddFieldRequired) {
+		t.Errorf("Expected translation not to be skippable, got %v", err)
+	}
+
+	// Черновик хранится в пользователе, поэтому новый сервис продолжает диалог
+	wizard = NewAddWizardService(wordService, userService)
+	step, err = wizard.Input(testUserID, "яблоко")
+	if err != nil || step.Step != AddStepContext || step.Draft.Word != "apple" {
+		t.Fatalf("Expected context step with the draft, got %+v, %v", step, err)
+	}
+
+	step, err = wizard.Input(testUserID, "red apple")
+	if err != nil || !step.Done() {
+		t.Fatalf("Expected the word to be saved, got %+v, %v", step, err)
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 1 || words[0].Word != "apple" || words[0].Translation != "яблоко" || words[0].Context != "red apple" {
+		t.Errorf("Unexpected words: %+v", words)
+	}
+	user, _ := userService.GetUser(testUserID)
+	if user.State != StateIdle || user.StateData != "" {
+		t.Errorf("Expected idle state without data, got %q, %q", user.State, user.StateData)
+	}
+}
+
+func TestAddWizardService_SkipAndCancel(t *testing.T) {
+	wizard, _, wordService := newTestAddWizard(t)
+
+	if _, err := wizard.Input(testUserID, "apple"); !errors.Is(err, ErrNoAddWizard) {
+		t.Errorf("Expected ErrNoAddWizard before start, got %v", err)
+	}
+
+	_ = wizard.Start(testUserID)
+	_, _ = wizard.Input(testUserID, "apple")
+	_, _ = wizard.Input(testUserID, "яблоко")
+	step, err := wizard.Skip(testUserID)
+	if err != nil || !step.Done() {
+		t.Fatalf("Expected context to be skipped, got %+v, %v", step, err)
+	}
+
+	_ = wizard.Start(testUserID)
+	_, _ = wizard.Input(testUserID, "pear")
+	if err := wizard.Cancel(testUserID); err != nil {
+		t.Fatalf("Failed to cancel: %v", err)
+	}
+	if err := wizard.Cancel(testUserID); !errors.Is(err, ErrNoAddWizard) {
+		t.Errorf("Expected ErrNoAddWizard after cancel, got %v", err)
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 1 || words[0].Word != "apple" || words[0].Context != "" {
+		t.Errorf("Expected only the skipped-context word, got %+v", words)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/textutil"
+)
+
+// AnswerGrade — оценка введенного ответа
+type AnswerGrade int
+
+const (
+	GradeWrong   AnswerGrade = iota // Ответ неверный
+	GradeAlmost                     // Ответ верный, но с опечаткой
+	GradeCorrect                    // Ответ верный
+)
+
+// AnswerMatch описывает, насколько введенный ответ совпал с ожидаемым
+type AnswerMatch struct {
+	Grade    AnswerGrade
+	Closest  string // Вариант из ожидаемых, ближайший к ответу
+	Distance int    // Расстояние Левенштейна до ближайшего варианта после нормализации
+}
+
+// answerArticles — служебные слова, которые не влияют на проверку ответа
+var answerArticles = map[string]bool{"a": true, "an": true, "the": true}
+
+// NormalizeAnswer приводит ответ к виду для сравнения: нижний регистр, ё как е,
+// без знаков препинания, лишних пробелов и артикля в начале
+func NormalizeAnswer(answer string) string {
+	answer = strings.ReplaceAll(strings.ToLower(answer), "ё", "е")
+
+	var b strings.Builder
+	for _, r := range answer {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '\'' || r == '’':
+			// Апостроф не разделяет слово: don't и dont считаются одинаковыми
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	fields := strings.Fields(b.String())
+	if len(fields) > 1 && answerArticles[fields[0]] {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, " ")
+}
+
+// AnswerAlternatives разбивает перевод на варианты, перечисленные через запятую
+// или точку с запятой
+func AnswerAlternatives(expected string) []string {
+	var alternatives []string
+	for _, part := range strings.FieldsFunc(expected, func(r rune) bool { return r == ',' || r == ';' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			alternatives = append(alternatives, part)
+		}
+	}
+	return alternatives
+}
+
+// MatchAnswer сравнивает введенный ответ с ожидаемым. Подходит любой из вариантов,
+// перечисленных через запятую; небольшая опечатка дает оценку GradeAlmost.
+func MatchAnswer(answer, expected string) AnswerMatch {
+	normalized := NormalizeAnswer(answer)
+	alternatives := AnswerAlternatives(expected)
+	if len(alternatives) == 0 {
+		return AnswerMatch{Grade: GradeWrong, Closest: expected}
+	}
+
+	best := AnswerMatch{Grade: GradeWrong, Closest: alternatives[0], Distance: -1}
+	for _, alternative := range alternatives {
+		target := NormalizeAnswer(alternative)
+		distance := textutil.Levenshtein(normalized, target)
+		if best.Distance >= 0 && distance >= best.Distance {
+			continue
+		}
+
+		best = AnswerMatch{Grade: GradeWrong, Closest: alternative, Distance: distance}
+		switch {
+		case normalized == "":
+			// Пустой ответ не может быть даже почти правильным
+		case distance == 0:
+			best.Grade = GradeCorrect
+		case distance <= allowedTypos(target):
+			best.Grade = GradeAlmost
+		}
+	}
+	return best
+}
+
+// allowedTypos возвращает, сколько опечаток допускается в ответе такой длины:
+// в коротких словах любая опечатка может дать другое слово
+func allowedTypos(target string) int {
+	switch n := len([]rune(target)); {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import "testing"
+
+func TestNormalizeAnswer(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  Apple ", "apple"},
+		{"Ёлка", "елка"},
+		{"the apple", "apple"},
+		{"An Orange!", "orange"},
+		{"the", "the"},
+		{"don't", "dont"},
+		{"ice-cream", "ice cream"},
+		{"так,   себе...", "так себе"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeAnswer(tt.in); got != tt.want {
+			t.Errorf("NormalizeAnswer(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected string
+		grade    AnswerGrade
+		closest  string
+	}{
+		{"exact", "яблоко", "яблоко", GradeCorrect, "яблоко"},
+		{"normalised", "Ёж!", "еж", GradeCorrect, "еж"},
+		{"article", "the apple", "apple", GradeCorrect, "apple"},
+		{"any alternative", "красивый", "прекрасный, красивый; милый", GradeCorrect, "красивый"},
+		{"typo", "яблако", "яблоко", GradeAlmost, "яблоко"},
+		{"typo in alternative", "красивй", "прекрасный, красивый", GradeAlmost, "красивый"},
+		{"two typos in a long word", "beautyfull", "beautiful", GradeAlmost, "beautiful"},
+		{"typo in a short word", "cot", "cat", GradeWrong, "cat"},
+		{"wrong", "груша", "яблоко", GradeWrong, "яблоко"},
+		{"empty", "", "яблоко", GradeWrong, "яблоко"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MatchAnswer(tt.answer, tt.expected)
+			if got.Grade != tt.grade || got.Closest != tt.closest {
+				t.Errorf("MatchAnswer(%q, %q) = %+v, want grade %d closest %q",
+					tt.answer, tt.expected, got, tt.grade, tt.closest)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/textutil"
+)
+
+// ErrWordLineFormat возвращается для строки не в формате «слово - перевод - контекст»
+var ErrWordLineFormat = errors.New("line must be in the format: word - translation - context")
+
+// Результат обработки строки при добавлении нескольких слов
+const (
+	LineAdded     = "added"
+	LineDuplicate = "duplicate"
+	LineInvalid   = "invalid"
+)
+
+// LineResult — результат обработки одной строки сообщения
+type LineResult struct {
+	Line   int    // Номер строки в сообщении, с единицы
+	Status string // LineAdded, LineDuplicate или LineInvalid
+	Word   *repository.Word
+	Err    error // Причина, если строка не разобрана
+}
+
+// ParseWordLine разбирает строку «слово - перевод - контекст»; контекст необязателен
+func ParseWordLine(line string) (word, translation, context string, err error) {
+	parts := strings.Split(line, " - ")
+	if len(parts) < 2 {
+		return "", "", "", ErrWordLineFormat
+	}
+
+	word = strings.TrimSpace(parts[0])
+	translation = strings.TrimSpace(parts[1])
+	if len(parts) > 2 {
+		context = strings.TrimSpace(strings.Join(parts[2:], " - "))
+	}
+	if word == "" || translation == "" {
+		return "", "", "", ErrWordLineFormat
+	}
+
+	return word, translation, context, nil
+}
+
+// AddWords добавляет слова из многострочного текста, по одному на строку;
+// теги #tag в строке достаются слову этой строки (см. ExtractTags). Пустые
+// строки пропускаются, а повторы обрабатываются так же, как в addWordLines.
+func (s *WordService) AddWords(userID int64, text string) ([]LineResult, error) {
+	var results []LineResult
+	for i, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		result := LineResult{Line: i + 1}
+		line, tags := ExtractTags(line)
+		word, translation, context, err := ParseWordLine(line)
+		if err != nil {
+			result.Status, result.Err = LineInvalid, err
+		} else {
+			result.Word = &repository.Word{UserID: userID, Word: word, Translation: translation, Context: context,
+				Tags: tags}
+		}
+		results = append(results, result)
+	}
+
+	return s.addWordLines(userID, results)
+}
+
+// addWordLines добавляет слова строк results, которые разобраны без ошибок.
+// Слова, которые уже есть в словаре или выше в тех же строках, получают статус
+// LineDuplicate и не добавляются повторно. Новые слова попадают в активную
+// колоду и сохраняются одной транзакцией: при ошибке хранилища не добавляется ни одно.
+func (s *WordService) addWordLines(userID int64, results []LineResult) ([]LineResult, error) {
+	existing, err := s.wordRepo.GetUserWords(userID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, word := range existing {
+		seen[textutil.NormalizeWord(word.Word)] = true
+	}
+
+	var words []*repository.Word
+	for i := range results {
+		result := &results[i]
+		switch {
+		case result.Status == LineInvalid:
+		case seen[textutil.NormalizeWord(result.Word.Word)]:
+			result.Status = LineDuplicate
+		default:
+			result.Status = LineAdded
+			words = append(words, result.Word)
+			seen[textutil.NormalizeWord(result.Word.Word)] = true
+		}
+	}
+
+	if len(words) > 0 {
+		deck, err := s.ensureActiveDeck(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, word := range words {
+			word.DeckID = deck.ID
+		}
+		if err := s.wordRepo.SaveWords(words); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseWordLine(t *testing.T) {
+	tests := []struct {
+		line                       string
+		word, translation, context string
+		wantErr                    bool
+	}{
+		{line: "apple - яблоко", word: "apple", translation: "яблоко"},
+		{line: " apple - яблоко - a red - sweet apple ", word: "apple", translation: "яблоко",
+			context: "a red - sweet apple"},
+		{line: "apple", wantErr: true},
+		{line: "apple -яблоко", wantErr: true},
+		{line: " - яблоко", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		word, translation, context, err := ParseWordLine(tt.line)
+		if tt.wantErr {
+			if !errors.Is(err, ErrWordLineFormat) {
+				t.Errorf("ParseWordLine(%q): expected format error, got %v", tt.line, err)
+			}
+			continue
+		}
+		if err != nil || word != tt.word || translation != tt.translation || context != tt.context {
+			t.Errorf("ParseWordLine(%q) = %q, %q, %q, %v", tt.line, word, translation, context, err)
+		}
+	}
+}
+
+func TestWordService_AddWords(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+
+	text := "Apple - яблоко\npear - груша - ripe pear\n\nplum\nPEAR - груша\nlemon - лимон"
+	results, err := wordService.AddWords(testUserID, text)
+	if err != nil {
+		t.Fatalf("Failed to add words: %v", err)
+	}
+
+	want := []struct {
+		line   int
+		status string
+	}{
+		{1, LineDuplicate},
+		{2, LineAdded},
+		{4, LineInvalid},
+		{5, LineDuplicate}, // Повтор строки выше
+		{6, LineAdded},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %+v", len(want), results)
+	}
+	for i, w := range want {
+		if results[i].Line != w.line || results[i].Status != w.status {
+			t.Errorf("Result %d: expected line %d %s, got line %d %s", i, w.line, w.status, results[i].Line, results[i].Status)
+		}
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 3 {
+		t.Errorf("Expected 3 words in total, got %d", len(words))
+	}
+	if results[1].Word.ID == 0 || results[1].Word.Context != "ripe pear" {
+		t.Errorf("Expected the added word to be saved with context, got %+v", results[1].Word)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/textutil"
+)
+
+// DeckSharePayload начинает параметр /start в ссылке на колоду:
+// t.me/<бот>?start=deck_<код>
+const DeckSharePayload = "deck_"
+
+// Код ссылки на колоду: Telegram пропускает в параметре start только
+// латиницу, цифры, _ и -, а _ разделяет части callback данных
+const (
+	shareCodeLength   = 10
+	shareCodeAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	shareCodeAttempts = 5
+)
+
+// Ошибки работы со ссылками на колоды
+var (
+	ErrShareNotFound = errors.New("shared deck not found")
+	ErrOwnDeck       = errors.New("cannot import own deck")
+)
+
+// DeckImport — результат импорта колоды по ссылке
+type DeckImport struct {
+	Deck    *repository.Deck // Новая колода пользователя; она становится активной
+	Skipped int              // Слова, которые уже были в словаре и не скопированы
+}
+
+// DeckShareLink возвращает ссылку на колоду для бота botUsername
+func DeckShareLink(botUsername, code string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", botUsername, DeckSharePayload, code)
+}
+
+// ParseDeckSharePayload выделяет код колоды из параметра /start;
+// ok = false, если параметр не ссылка на колоду
+func ParseDeckSharePayload(payload string) (code string, ok bool) {
+	code, found := strings.CutPrefix(strings.TrimSpace(payload), DeckSharePayload)
+	if !found || code == "" {
+		return "", false
+	}
+	return code, true
+}
+
+// ShareDeck публикует колоду пользователя и возвращает ее с кодом ссылки.
+// У уже опубликованной колоды код не меняется.
+func (s *WordService) ShareDeck(userID int64, deckID int) (*repository.Deck, error) {
+	deck, err := s.GetDeck(userID, deckID)
+	if err != nil || deck.ShareCode != "" {
+		return deck, err
+	}
+
+	for attempt := 0; attempt < shareCodeAttempts; attempt++ {
+		code, err := newShareCode()
+		if err != nil {
+			return nil, err
+		}
+		taken, err := s.wordRepo.GetDeckByShareCode(code)
+		if err != nil {
+			return nil, err
+		}
+		if taken != nil {
+			continue
+		}
+
+		if err := s.wordRepo.SetDeckShareCode(userID, deck.ID, code); err != nil {
+			return nil, err
+		}
+		deck.ShareCode = code
+		return deck, nil
+	}
+	return nil, fmt.Errorf("failed to generate a unique share code")
+}
+
+// RevokeDeckShare отзывает ссылку на колоду: по старой ссылке колоду больше не импортировать
+func (s *WordService) RevokeDeckShare(userID int64, deckID int) (*repository.Deck, error) {
+	deck, err := s.GetDeck(userID, deckID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.wordRepo.SetDeckShareCode(userID, deck.ID, ""); err != nil {
+		return nil, err
+	}
+	deck.ShareCode = ""
+	return deck, nil
+}
+
+// SharedDeck возвращает опубликованную колоду по коду ссылки
+func (s *WordService) SharedDeck(code string) (*repository.Deck, error) {
+	deck, err := s.wordRepo.GetDeckByShareCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if deck == nil {
+		return nil, ErrShareNotFound
+	}
+	return deck, nil
+}
+
+// ImportSharedDeck копирует опубликованную колоду пользователю userID и делает
+// копию активной. Слова копируются с тегами, но без истории: расписание
+// повторений начинается заново. Слова, которые у пользователя уже есть,
+// не копируются, а если название колоды занято, к нему добавляется номер.
+func (s *WordService) ImportSharedDeck(userID int64, code string) (*DeckImport, error) {
+	source, err := s.SharedDeck(code)
+	if err != nil {
+		return nil, err
+	}
+	if source.UserID == userID {
+		return nil, ErrOwnDeck
+	}
+
+	// Слова без колоды должны остаться в основной, а не попасть в импортированную
+	if _, err := s.ensureActiveDeck(userID); err != nil {
+		return nil, err
+	}
+
+	sourceWords, err := s.wordRepo.ListWords(repository.WordListQuery{
+		UserID: source.UserID, Sort: repository.WordSortRecent, DeckID: source.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.LoadTags(source.UserID, sourceWords); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.wordRepo.GetUserWords(userID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, word := range existing {
+		seen[textutil.NormalizeWord(word.Word)] = true
+	}
+
+	result := &DeckImport{}
+	var words []*repository.Word
+	// Копируем от старых слов к новым, чтобы порядок в /words совпал с исходной колодой
+	for i := len(sourceWords) - 1; i >= 0; i-- {
+		word := sourceWords[i]
+		if seen[textutil.NormalizeWord(word.Word)] {
+			result.Skipped++
+			continue
+		}
+		seen[textutil.NormalizeWord(word.Word)] = true
+		words = append(words, &repository.Word{
+			Word: word.Word, Translation: word.Translation, Context: word.Context, Tags: word.Tags,
+		})
+	}
+
+	name, err := s.freeDeckName(userID, source.Name)
+	if err != nil {
+		return nil, err
+	}
+	deck := &repository.Deck{
+		UserID:      userID,
+		Name:        name,
+		Description: source.Description,
+		SourceLang:  source.SourceLang,
+		TargetLang:  source.TargetLang,
+		NewPerDay:   source.NewPerDay,
+	}
+	if err := s.wordRepo.ImportDeck(deck, words, source.ID); err != nil {
+		return nil, err
+	}
+	if err := s.wordRepo.SetActiveDeck(userID, deck.ID); err != nil {
+		return nil, err
+	}
+	deck.Active = true
+
+	result.Deck = deck
+	return result, nil
+}
+
+// freeDeckName возвращает name, а если у пользователя уже есть такая колода —
+// name с первым свободным номером: «Travel (2)»
+func (s *WordService) freeDeckName(userID int64, name string) (string, error) {
+	for n := 1; ; n++ {
+		candidate := name
+		if n > 1 {
+			suffix := fmt.Sprintf(" (%d)", n)
+			runes := []rune(name)
+			if len(runes)+len([]rune(suffix)) > MaxDeckNameLength {
+				runes = runes[:MaxDeckNameLength-len([]rune(suffix))]
+			}
+			candidate = string(runes) + suffix
+		}
+
+		_, err := s.FindDeck(userID, candidate)
+		if errors.Is(err, ErrDeckNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// newShareCode возвращает случайный код ссылки на колоду
+func newShareCode() (string, error) {
+	var sb strings.Builder
+	limit := big.NewInt(int64(len(shareCodeAlphabet)))
+	for i := 0; i < shareCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate share code: %w", err)
+		}
+		sb.WriteByte(shareCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestParseDeckSharePayload(t *testing.T) {
+	tests := []struct {
+		payload string
+		code    string
+		ok      bool
+	}{
+		{"deck_Ab3xYz9KmP", "Ab3xYz9KmP", true},
+		{" deck_abc ", "abc", true},
+		{"deck_", "", false},
+		{"", "", false},
+		{"ref_123", "", false},
+	}
+
+	for _, tt := range tests {
+		code, ok := ParseDeckSharePayload(tt.payload)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("ParseDeckSharePayload(%q) = %q, %v; want %q, %v", tt.payload, code, ok, tt.code, tt.ok)
+		}
+	}
+
+	if link := DeckShareLink("english_bot", "abc"); link != "https://t.me/english_bot?start=deck_abc" {
+		t.Errorf("Unexpected share link %q", link)
+	}
+}
+
+func TestWordService_ShareAndImportDeck(t *testing.T) {
+	userService, wordService := newTestServices(t)
+	student := testUserID + 1
+	if err := userService.RegisterUser(student, "student", "Student", ""); err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	addTestWords(t, wordService, "apple", "яблоко", "train", "поезд")
+	words, _ := wordService.GetUserWords(testUserID)
+	if _, err := wordService.AddTags(testUserID, words[0].ID, []string{"travel"}); err != nil {
+		t.Fatalf("Failed to tag word: %v", err)
+	}
+	_, err := wordService.ReviewWord(repository.SchedulerSM2, testUserID, words[0].ID, ResultFromQuality(QualityPerfect))
+	if err != nil {
+		t.Fatalf("Failed to review word: %v", err)
+	}
+	deck, _ := wordService.ActiveDeck(testUserID)
+
+	shared, err := wordService.ShareDeck(testUserID, deck.ID)
+	if err != nil || len(shared.ShareCode) != shareCodeLength {
+		t.Fatalf("Expected a share code, got %+v, %v", shared, err)
+	}
+	if again, _ := wordService.ShareDeck(testUserID, deck.ID); again.ShareCode != shared.ShareCode {
+		t.Errorf("Expected the code to stay, got %q and %q", shared.ShareCode, again.ShareCode)
+	}
+	if _, err := wordService.ShareDeck(student, deck.ID); !errors.Is(err, ErrDeckNotFound) {
+		t.Errorf("Expected ErrDeckNotFound for someone else's deck, got %v", err)
+	}
+	if _, err := wordService.ImportSharedDeck(testUserID, shared.ShareCode); !errors.Is(err, ErrOwnDeck) {
+		t.Errorf("Expected ErrOwnDeck, got %v", err)
+	}
+
+	// У ученика уже есть apple и своя колода с тем же названием
+	if err := wordService.AddWord(student, "Apple", "яблоко", ""); err != nil {
+		t.Fatalf("Failed to add word: %v", err)
+	}
+	result, err := wordService.ImportSharedDeck(student, shared.ShareCode)
+	if err != nil {
+		t.Fatalf("Failed to import deck: %v", err)
+	}
+	if result.Deck.Name != DefaultDeckName+" (2)" || !result.Deck.Active || result.Deck.Words != 1 || result.Skipped != 1 {
+		t.Fatalf("Expected a renamed active copy with train only, got %+v, skipped %d", result.Deck, result.Skipped)
+	}
+
+	copied, _ := wordService.GetDeckWords(student)
+	if len(copied) != 1 || copied[0].Word != "train" || !copied[0].IsNew() {
+		t.Fatalf("Expected a fresh copy of train, got %v", copied)
+	}
+	if tags, _ := wordService.wordTags(student, copied[0].ID); len(tags) != 1 || tags[0] != "travel" {
+		t.Errorf("Expected the copy to keep the travel tag, got %v", tags)
+	}
+	if deck, _ = wordService.GetDeck(testUserID, deck.ID); deck.Imports != 1 {
+		t.Errorf("Expected one import, got %d", deck.Imports)
+	}
+
+	if _, err := wordService.RevokeDeckShare(testUserID, deck.ID); err != nil {
+		t.Fatalf("Failed to revoke link: %v", err)
+	}
+	if _, err := wordService.ImportSharedDeck(student, shared.ShareCode); !errors.Is(err, ErrShareNotFound) {
+		t.Errorf("Expected ErrShareNotFound after revoking, got %v", err)
+	}
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// Значения новой колоды. Основная колода создается для каждого пользователя
+// автоматически (для старых пользователей — миграцией 0012_decks).
+const (
+	DefaultDeckName   = "Основная"
+	DefaultSourceLang = "en"
+	DefaultTargetLang = "ru"
+	DefaultNewPerDay  = 20
+)
+
+// Ограничения колоды
+const (
+	MaxDeckNameLength        = 40
+	MaxDeckDescriptionLength = 200
+	MaxNewPerDay             = 500
+)
+
+// NewPerDayOptions перечисляет лимиты новых слов в день на кнопках /deck
+var NewPerDayOptions = []int{5, 10, 20, 50}
+
+// Поля колоды, которые вводятся текстом в /deck
+const (
+	DeckFieldName        = "name"
+	DeckFieldDescription = "about"
+	DeckFieldLanguages   = "lang"
+)
+
+// Ошибки работы с колодами
+var (
+	ErrDeckNotFound         = errors.New("deck not found")
+	ErrDeckExists           = errors.New("deck with this name already exists")
+	ErrInvalidDeckName      = errors.New("invalid deck name")
+	ErrInvalidLanguages     = errors.New("invalid language pair")
+	ErrDeckDescription      = errors.New("deck description is too long")
+	ErrInvalidNewPerDay     = errors.New("invalid new cards per day")
+	ErrLastDeck             = errors.New("cannot delete the last deck")
+	ErrUnknownDeckField     = errors.New("unknown deck field")
+	ErrNoDeckEdit           = errors.New("deck edit is not active")
+	ErrUnknownDeckScheduler = errors.New("unknown scheduler")
+)
+
+// languagePairPattern разбирает языковую пару «en-ru», «en → ru» или «en ru»
+var languagePairPattern = regexp.MustCompile(`^([a-z]{2,3})\s*(?:-|→|->|>|\s)\s*([a-z]{2,3})$`)
+
+// ParseLanguagePair разбирает языковую пару из двух кодов языков, например «en-ru»
+func ParseLanguagePair(text string) (source, target string, err error) {
+	match := languagePairPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(text)))
+	if match == nil {
+		return "", "", ErrInvalidLanguages
+	}
+	return match[1], match[2], nil
+}
+
+// GetDecks возвращает колоды пользователя в порядке создания
+func (s *WordService) GetDecks(userID int64) ([]*repository.Deck, error) {
+	return s.wordRepo.GetUserDecks(userID)
+}
+
+// ActiveDeck возвращает активную колоду пользователя или nil, если колод у него
+// еще нет: тогда слова выбираются из всего словаря
+func (s *WordService) ActiveDeck(userID int64) (*repository.Deck, error) {
+	decks, err := s.wordRepo.GetUserDecks(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, deck := range decks {
+		if deck.Active {
+			return deck, nil
+		}
+	}
+	if len(decks) > 0 {
+		return decks[0], nil
+	}
+	return nil, nil
+}
+
+// ensureActiveDeck возвращает активную колоду, а если колод еще нет — создает
+// основную и переносит в нее все слова пользователя без колоды
+func (s *WordService) ensureActiveDeck(userID int64) (*repository.Deck, error) {
+	deck, err := s.ActiveDeck(userID)
+	if err != nil || deck != nil {
+		return deck, err
+	}
+
+	deck = &repository.Deck{
+		UserID:     userID,
+		Name:       DefaultDeckName,
+		SourceLang: DefaultSourceLang,
+		TargetLang: DefaultTargetLang,
+		NewPerDay:  DefaultNewPerDay,
+		Active:     true,
+	}
+	if err := s.wordRepo.CreateDeck(deck); err != nil {
+		return nil, err
+	}
+
+	words, err := s.wordRepo.GetUserWords(userID)
+	if err != nil {
+		return nil, err
+	}
+	var loose []int
+	for _, word := range words {
+		if word.DeckID == 0 {
+			loose = append(loose, word.ID)
+		}
+	}
+	if len(loose) > 0 {
+		if err := s.wordRepo.MoveWords(userID, loose, deck.ID); err != nil {
+			return nil, err
+		}
+		deck.Words = len(loose)
+	}
+	return deck, nil
+}
+
+// activeDeckID возвращает ID активной колоды или 0, если колод у пользователя нет
+func (s *WordService) activeDeckID(userID int64) (int, error) {
+	deck, err := s.ActiveDeck(userID)
+	if err != nil || deck == nil {
+		return 0, err
+	}
+	return deck.ID, nil
+}
+
+// GetDeck получает колоду пользователя по ID; чужая колода считается ненайденной
+func (s *WordService) GetDeck(userID int64, deckID int) (*repository.Deck, error) {
+	deck, err := s.wordRepo.GetDeck(deckID)
+	if err != nil {
+		return nil, err
+	}
+	if deck == nil || deck.UserID != userID {
+		return nil, ErrDeckNotFound
+	}
+	return deck, nil
+}
+
+// FindDeck ищет колоду пользователя по названию без учета регистра
+func (s *WordService) FindDeck(userID int64, name string) (*repository.Deck, error) {
+	decks, err := s.wordRepo.GetUserDecks(userID)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	for _, deck := range decks {
+		if strings.EqualFold(deck.Name, name) {
+			return deck, nil
+		}
+	}
+	return nil, ErrDeckNotFound
+}
+
+// CreateDeck создает колоду с названием name и делает ее активной
+func (s *WordService) CreateDeck(userID int64, name string) (*repository.Deck, error) {
+	name, err := s.checkDeckName(userID, 0, name)
+	if err != nil {
+		return nil, err
+	}
+	// Слова без колоды должны остаться в основной, а не попасть в новую
+	if _, err := s.ensureActiveDeck(userID); err != nil {
+		return nil, err
+	}
+
+	deck := &repository.Deck{
+		UserID:     userID,
+		Name:       name,
+		SourceLang: DefaultSourceLang,
+		TargetLang: DefaultTargetLang,
+		NewPerDay:  DefaultNewPerDay,
+	}
+	if err := s.wordRepo.CreateDeck(deck); err != nil {
+		return nil, err
+	}
+	if err := s.wordRepo.SetActiveDeck(userID, deck.ID); err != nil {
+		return nil, err
+	}
+	deck.Active = true
+	return deck, nil
+}
+
+// UseDeck делает колоду пользователя активной
+func (s *WordService) UseDeck(userID int64, deckID int) (*repository.Deck, error) {
+	deck, err := s.GetDeck(userID, deckID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.wordRepo.SetActiveDeck(userID, deck.ID); err != nil {
+		return nil, err
+	}
+	deck.Active = true
+	return deck, nil
+}
+
+// EditDeck заменяет название, описание или языковую пару колоды значением value.
+// Описание удаляется вводом ClearContextInput.
+func (s *WordService) EditDeck(userID int64, deckID int, field, value string) (*repository.Deck, error) {
+	deck, err := s.GetDeck(userID, deckID)
+	if err != nil {
+		return nil, err
+	}
+
+	value = strings.TrimSpace(value)
+	switch field {
+	case DeckFieldName:
+		if deck.Name, err = s.checkDeckName(userID, deck.ID, value); err != nil {
+			return nil, err
+		}
+	case DeckFieldDescription:
+		if value == ClearContextInput {
+			value = ""
+		}
+		if utf8.RuneCountInString(value) > MaxDeckDescriptionLength {
+			return nil, ErrDeckDescription
+		}
+		deck.Description = value
+	case DeckFieldLanguages:
+		if deck.SourceLang, deck.TargetLang, err = ParseLanguagePair(value); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnknownDeckField
+	}
+
+	if err := s.wordRepo.UpdateDeck(deck); err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+// SetDeckNewPerDay задает, сколько новых слов колоды показывать в /review за день
+func (s *WordService) SetDeckNewPerDay(userID int64, deckID, newPerDay int) (*repository.Deck, error) {
+	if newPerDay < 0 || newPerDay > MaxNewPerDay {
+		return nil, ErrInvalidNewPerDay
+	}
+	deck, err := s.GetDeck(userID, deckID)
+	if err != nil {
+		return nil, err
+	}
+
+	deck.NewPerDay = newPerDay
+	if err := s.wordRepo.UpdateDeck(deck); err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+// SetDeckScheduler задает алгоритм повторения колоды; пустая строка означает
+// алгоритм пользователя userScheduler из /settings. Если алгоритм слов колоды
+// меняется, их состояние переводится в параметры нового алгоритма в той же
+// транзакции, что и сохранение колоды.
+func (s *WordService) SetDeckScheduler(userID int64, deckID int,
+	scheduler, userScheduler string) (*repository.Deck, error) {
+	if scheduler != "" && !IsKnownScheduler(scheduler) {
+		return nil, ErrUnknownDeckScheduler
+	}
+	deck, err := s.GetDeck(userID, deckID)
+	if err != nil {
+		return nil, err
+	}
+
+	var states, reverse map[int]repository.ReviewState
+	before, after := effectiveScheduler(deck.Scheduler, userScheduler), effectiveScheduler(scheduler, userScheduler)
+	if before != after {
+		inDeck := func(word *repository.Word) bool { return word.DeckID == deck.ID }
+		states, reverse, err = s.convertedStates(userID, after, inDeck)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deck.Scheduler = scheduler
+	if err := s.wordRepo.UpdateDeckScheduler(deck, states, reverse); err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+// effectiveScheduler возвращает алгоритм колоды, а если он не задан — алгоритм пользователя
+func effectiveScheduler(deckScheduler, userScheduler string) string {
+	if deckScheduler != "" {
+		return deckScheduler
+	}
+	return userScheduler
+}
+
+// MoveWords переносит слова пользователя в его колоду deckID
+func (s *WordService) MoveWords(userID int64, wordIDs []int, deckID int) (*repository.Deck, error) {
+	deck, err := s.GetDeck(userID, deckID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.wordRepo.MoveWords(userID, wordIDs, deck.ID); err != nil {
+		return nil, err
+	}
+	deck.Words += len(wordIDs)
+	return deck, nil
+}
+
+// DeleteDeck удаляет колоду вместе с ее словами и возвращает активную колоду
+// после удаления. Последнюю колоду удалить нельзя.
+func (s *WordService) DeleteDeck(userID int64, deckID int) (*repository.Deck, error) {
+	deck, err := s.GetDeck(userID, deckID)
+	if err != nil {
+		return nil, err
+	}
+	decks, err := s.wordRepo.GetUserDecks(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(decks) <= 1 {
+		return nil, ErrLastDeck
+	}
+
+	if err := s.wordRepo.DeleteDeck(userID, deck.ID); err != nil {
+		return nil, err
+	}
+
+	active, err := s.ActiveDeck(userID)
+	if err != nil {
+		return nil, err
+	}
+	if deck.Active && active != nil {
+		if err := s.wordRepo.SetActiveDeck(userID, active.ID); err != nil {
+			return nil, err
+		}
+		active.Active = true
+	}
+	return active, nil
+}
+
+// GetDeckWords возвращает слова активной колоды, начиная с недавних, — в том же
+// порядке и с теми же номерами, что и в /words
+func (s *WordService) GetDeckWords(userID int64) ([]*repository.Word, error) {
+	deckID, err := s.activeDeckID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.wordRepo.ListWords(repository.WordListQuery{UserID: userID, Sort: repository.WordSortRecent, DeckID: deckID})
+}
+
+// newCardsLeft возвращает, сколько еще новых слов колоды можно показать сегодня
+func (s *WordService) newCardsLeft(userID int64, deck *repository.Deck, now time.Time) (int, error) {
+	year, month, day := now.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	started, err := s.wordRepo.CountNewWordsReviewed(userID, deck.ID, midnight)
+	if err != nil {
+		return 0, err
+	}
+	return deck.NewPerDay - started, nil
+}
+
+// checkDeckName проверяет название колоды и возвращает его без лишних пробелов.
+// Название должно быть уникальным у пользователя без учета регистра;
+// колода exceptID при проверке пропускается.
+func (s *WordService) checkDeckName(userID int64, exceptID int, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, "\r\n") || utf8.RuneCountInString(name) > MaxDeckNameLength {
+		return "", ErrInvalidDeckName
+	}
+
+	existing, err := s.FindDeck(userID, name)
+	if err != nil && !errors.Is(err, ErrDeckNotFound) {
+		return "", err
+	}
+	if existing != nil && existing.ID != exceptID {
+		return "", ErrDeckExists
+	}
+	return name, nil
+}
+
+// DeckEditService ведет ввод названия, описания и языков колоды: пользователь
+// выбирает поле кнопкой в /deck, а следующее сообщение без команды становится
+// новым значением
+type DeckEditService struct {
+	wordService *WordService
+	userService *UserService
+}
+
+// NewDeckEditService создает сервис редактирования колод
+func NewDeckEditService(wordService *WordService, userService *UserService) *DeckEditService {
+	return &DeckEditService{wordService: wordService, userService: userService}
+}
+
+// deckEditStatePrefix начинает users.state, пока бот ждет новое значение поля
+// колоды. Полное состояние: deck:<поле>:<ID колоды>.
+const deckEditStatePrefix = "deck:"
+
+// IsDeckEditState сообщает, что пользователь сейчас вводит новое значение поля колоды
+func IsDeckEditState(state string) bool {
+	return strings.HasPrefix(state, deckEditStatePrefix)
+}
+
+// Start запоминает, какое поле какой колоды пользователь будет вводить, и возвращает колоду
+func (s *DeckEditService) Start(userID int64, deckID int, field string) (*repository.Deck, error) {
+	if field != DeckFieldName && field != DeckFieldDescription && field != DeckFieldLanguages {
+		return nil, ErrUnknownDeckField
+	}
+
+	deck, err := s.wordService.GetDeck(userID, deckID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := fmt.Sprintf("%s%s:%d", deckEditStatePrefix, field, deckID)
+	if err := s.userService.UpdateUserState(userID, state); err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+// Input сохраняет введенное значение поля и завершает ввод. При ошибке
+// проверки значения ввод продолжается, чтобы пользователь мог исправить текст.
+func (s *DeckEditService) Input(userID int64, text string) (*repository.Deck, error) {
+	user, err := s.userService.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrNoDeckEdit
+	}
+	field, deckID, ok := parseDeckEditState(user.State)
+	if !ok {
+		return nil, ErrNoDeckEdit
+	}
+
+	deck, err := s.wordService.EditDeck(userID, deckID, field, text)
+	if errors.Is(err, ErrDeckNotFound) {
+		// Колоду удалили, пока пользователь вводил текст: ждать больше нечего
+		if err := s.userService.UpdateUserState(userID, StateIdle); err != nil {
+			return nil, err
+		}
+		return nil, ErrDeckNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.UpdateUserState(userID, StateIdle); err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+// Cancel прекращает ввод значения поля колоды, если бот его ждал
+func (s *DeckEditService) Cancel(userID int64) error {
+	user, err := s.userService.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !IsDeckEditState(user.State) {
+		return nil
+	}
+	return s.userService.UpdateUserState(userID, StateIdle)
+}
+
+// parseDeckEditState разбирает состояние deck:<поле>:<ID колоды>
+func parseDeckEditState(state string) (field string, deckID int, ok bool) {
+	rest, found := strings.CutPrefix(state, deckEditStatePrefix)
+	if !found {
+		return "", 0, false
+	}
+	field, id, found := strings.Cut(rest, ":")
+	if !found {
+		return "", 0, false
+	}
+	deckID, err := strconv.Atoi(id)
+	if err != nil {
+		return "", 0, false
+	}
+	return field, deckID, true
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestWordService_Decks_AddToActiveDeck(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+
+	mainDeck, err := wordService.ActiveDeck(testUserID)
+	if err != nil || mainDeck == nil || mainDeck.Name != DefaultDeckName || mainDeck.NewPerDay != DefaultNewPerDay {
+		t.Fatalf("Expected the default deck after the first word, got %+v, %v", mainDeck, err)
+	}
+
+	travel, err := wordService.CreateDeck(testUserID, " Travel ")
+	if err != nil || travel.Name != "Travel" || !travel.Active {
+		t.Fatalf("Expected an active Travel deck, got %+v, %v", travel, err)
+	}
+	addTestWords(t, wordService, "train", "поезд", "ticket", "билет")
+
+	if count, _ := wordService.CountWords(testUserID, 0); count != 2 {
+		t.Errorf("Expected two words in Travel, got %d", count)
+	}
+	page, err := wordService.ListWords(testUserID, repository.WordSortAlpha, 0, WordsPageSize, 0)
+	if err != nil || page.Deck == nil || page.Deck.ID != travel.ID ||
+		len(page.Words) != 2 || page.Words[0].Word != "ticket" {
+		t.Fatalf("Expected the Travel page, got %+v, %v", page, err)
+	}
+
+	if _, err := wordService.CreateDeck(testUserID, "travel"); !errors.Is(err, ErrDeckExists) {
+		t.Errorf("Expected ErrDeckExists, got %v", err)
+	}
+	if _, err := wordService.CreateDeck(testUserID, " "); !errors.Is(err, ErrInvalidDeckName) {
+		t.Errorf("Expected ErrInvalidDeckName, got %v", err)
+	}
+	var duplicate *DuplicateWordError
+	if err := wordService.AddWord(testUserID, "Apple", "яблоко", ""); !errors.As(err, &duplicate) {
+		t.Errorf("Expected a duplicate from another deck, got %v", err)
+	}
+
+	if _, err := wordService.UseDeck(testUserID, mainDeck.ID); err != nil {
+		t.Fatalf("Failed to switch deck: %v", err)
+	}
+	words, _ := wordService.GetDeckWords(testUserID)
+	if len(words) != 1 || words[0].Word != "apple" {
+		t.Fatalf("Expected only apple in the default deck, got %v", words)
+	}
+	if _, err := wordService.UseDeck(testUserID+1, mainDeck.ID); !errors.Is(err, ErrDeckNotFound) {
+		t.Errorf("Expected ErrDeckNotFound for someone else's deck, got %v", err)
+	}
+
+	if _, err := wordService.MoveWords(testUserID, []int{words[0].ID}, travel.ID); err != nil {
+		t.Fatalf("Failed to move word: %v", err)
+	}
+	if count, _ := wordService.CountWords(testUserID, 0); count != 0 {
+		t.Errorf("Expected the default deck to be empty, got %d", count)
+	}
+}
+
+func TestWordService_DeleteDeck(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+	mainDeck, _ := wordService.ActiveDeck(testUserID)
+
+	if _, err := wordService.DeleteDeck(testUserID, mainDeck.ID); !errors.Is(err, ErrLastDeck) {
+		t.Fatalf("Expected ErrLastDeck, got %v", err)
+	}
+
+	travel, _ := wordService.CreateDeck(testUserID, "Travel")
+	addTestWords(t, wordService, "train", "поезд")
+
+	active, err := wordService.DeleteDeck(testUserID, travel.ID)
+	if err != nil || active == nil || active.ID != mainDeck.ID || !active.Active {
+		t.Fatalf("Expected the default deck to become active, got %+v, %v", active, err)
+	}
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 1 || words[0].Word != "apple" {
+		t.Errorf("Expected the deck words to be deleted, got %v", words)
+	}
+}
+
+func TestWordService_NextCard_NewPerDay(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко", "pear", "груша")
+	deck, _ := wordService.ActiveDeck(testUserID)
+	if _, err := wordService.SetDeckNewPerDay(testUserID, deck.ID, 1); err != nil {
+		t.Fatalf("Failed to set new cards per day: %v", err)
+	}
+	if _, err := wordService.SetDeckNewPerDay(testUserID, deck.ID, -1); !errors.Is(err, ErrInvalidNewPerDay) {
+		t.Errorf("Expected ErrInvalidNewPerDay, got %v", err)
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	for _, word := range words {
+		state := word.ReviewState
+		state.NextReview = time.Now().Add(-time.Hour)
+		if err := wordService.wordRepo.SaveReviewState(word.ID, state); err != nil {
+			t.Fatalf("Failed to save review state: %v", err)
+		}
+	}
+
+	card, err := wordService.NextCard(testUserID, 0)
+	if err != nil || card == nil {
+		t.Fatalf("Expected a new card, got %v, %v", card, err)
+	}
+	if _, err := wordService.GradeCard(repository.SchedulerSM2, testUserID, card.ID, RatingGood, 0); err != nil {
+		t.Fatalf("Failed to grade card: %v", err)
+	}
+
+	if card, err := wordService.NextCard(testUserID, 0); err != nil || card != nil {
+		t.Errorf("Expected the daily limit of new cards to be reached, got %v, %v", card, err)
+	}
+}
+
+func TestWordService_SetDeckScheduler(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+	deck, _ := wordService.ActiveDeck(testUserID)
+	words, _ := wordService.GetUserWords(testUserID)
+	for i := 0; i < 2; i++ {
+		_, err := wordService.ReviewWord(repository.SchedulerSM2, testUserID, words[0].ID, ResultFromQuality(QualityGood))
+		if err != nil {
+			t.Fatalf("Failed to review word: %v", err)
+		}
+	}
+
+	deck, err := wordService.SetDeckScheduler(testUserID, deck.ID, repository.SchedulerFSRS, repository.SchedulerSM2)
+	if err != nil || deck.Scheduler != repository.SchedulerFSRS {
+		t.Fatalf("Expected the deck to use FSRS, got %+v, %v", deck, err)
+	}
+	converted, _ := wordService.GetWord(testUserID, words[0].ID)
+	if converted.Stability == 0 {
+		t.Errorf("Expected the deck words to get FSRS state, got %+v", converted.ReviewState)
+	}
+
+	// Смена алгоритма в /settings не трогает колоду со своим алгоритмом
+	if err := wordService.ConvertWordsToScheduler(testUserID, repository.SchedulerSM2); err != nil {
+		t.Fatalf("Failed to convert words: %v", err)
+	}
+	kept, _ := wordService.GetWord(testUserID, words[0].ID)
+	if kept.Stability != converted.Stability {
+		t.Errorf("Expected the FSRS state to stay, got %+v", kept.ReviewState)
+	}
+
+	_, err = wordService.SetDeckScheduler(testUserID, deck.ID, "leitner", "")
+	if !errors.Is(err, ErrUnknownDeckScheduler) {
+		t.Errorf("Expected unknown scheduler error, got %v", err)
+	}
+}
+
+func TestDeckEditService(t *testing.T) {
+	userService, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+	deck, _ := wordService.ActiveDeck(testUserID)
+	edit := NewDeckEditService(wordService, userService)
+
+	if _, err := edit.Start(testUserID, deck.ID, DeckFieldLanguages); err != nil {
+		t.Fatalf("Failed to start deck edit: %v", err)
+	}
+	if user, _ := userService.GetUser(testUserID); !IsDeckEditState(user.State) {
+		t.Fatalf("Expected the deck edit state, got %q", user.State)
+	}
+
+	if _, err := edit.Input(testUserID, "english"); !errors.Is(err, ErrInvalidLanguages) {
+		t.Fatalf("Expected ErrInvalidLanguages, got %v", err)
+	}
+	deck, err := edit.Input(testUserID, "de → ru")
+	if err != nil || deck.SourceLang != "de" || deck.TargetLang != "ru" {
+		t.Fatalf("Expected the languages to change, got %+v, %v", deck, err)
+	}
+	if user, _ := userService.GetUser(testUserID); user.State != StateIdle {
+		t.Errorf("Expected the edit to finish, got %q", user.State)
+	}
+	if _, err := edit.Input(testUserID, "x"); !errors.Is(err, ErrNoDeckEdit) {
+		t.Errorf("Expected ErrNoDeckEdit after the edit, got %v", err)
+	}
+
+	if _, err := edit.Start(testUserID, deck.ID, "color"); !errors.Is(err, ErrUnknownDeckField) {
+		t.Errorf("Expected ErrUnknownDeckField, got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	_ "embed"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/textutil"
+)
+
+// Части речи, которые учитываются при подборе вариантов
+const (
+	posNoun      = "noun"
+	posVerb      = "verb"
+	posAdjective = "adj"
+	posAdverb    = "adv"
+)
+
+// distractor — кандидат в неправильные варианты ответа или сам правильный ответ
+type distractor struct {
+	text   string
+	pos    string   // Часть речи; пустая, если неизвестна
+	deckID int      // Колода слова; 0 у слов встроенного списка
+	tags   []string // Теги слова
+}
+
+//go:embed frequency_words.tsv
+var frequencyWordsTSV string
+
+// frequencyWord — слово из встроенного частотного списка
+type frequencyWord struct {
+	word         repository.Word
+	partOfSpeech string
+}
+
+// frequencyWords — встроенный список частотных слов, которым дополняются варианты,
+// если у пользователя мало своих слов
+var frequencyWords = parseFrequencyWords(frequencyWordsTSV)
+
+// parseFrequencyWords разбирает список частотных слов; строки с # — комментарии
+func parseFrequencyWords(data string) []frequencyWord {
+	var words []frequencyWord
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) != 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		words = append(words, frequencyWord{
+			word:         repository.Word{Word: fields[0], Translation: fields[1]},
+			partOfSpeech: fields[2],
+		})
+	}
+	return words
+}
+
+// frequencyDistractors возвращает варианты из встроенного списка в направлении direction
+func frequencyDistractors(direction string) []distractor {
+	candidates := make([]distractor, 0, len(frequencyWords))
+	for i := range frequencyWords {
+		candidates = append(candidates, distractor{
+			text: quizOption(&frequencyWords[i].word, direction),
+			pos:  frequencyWords[i].partOfSpeech,
+		})
+	}
+	return candidates
+}
+
+// wordDistractor описывает слово пользователя как вариант ответа в направлении direction
+func wordDistractor(word *repository.Word, direction string) distractor {
+	text := quizOption(word, direction)
+	return distractor{text: text, pos: guessPartOfSpeech(text), deckID: word.DeckID, tags: word.Tags}
+}
+
+// wordDistractors возвращает варианты из слов пользователя в направлении direction
+func wordDistractors(words []*repository.Word, direction string) []distractor {
+	candidates := make([]distractor, 0, len(words))
+	for _, word := range words {
+		candidates = append(candidates, wordDistractor(word, direction))
+	}
+	return candidates
+}
+
+// pickDistractors выбирает count неправильных вариантов, больше всего похожих на
+// правильный ответ. Сначала берутся слова пользователя, а если их не хватает,
+// недостающие добавляются из встроенного списка частотных слов. Варианты не
+// повторяются и не совпадают с правильным ответом после нормализации.
+func pickDistractors(r *rand.Rand, correct distractor, own, fallback []distractor, count int) []string {
+	used := map[string]bool{NormalizeAnswer(correct.text): true}
+	picked := make([]string, 0, count)
+
+	for _, candidates := range [][]distractor{own, fallback} {
+		for _, candidate := range rankDistractors(r, correct, candidates) {
+			if len(picked) == count {
+				return picked
+			}
+			key := NormalizeAnswer(candidate.text)
+			if key == "" || used[key] {
+				continue
+			}
+			used[key] = true
+			picked = append(picked, candidate.text)
+		}
+	}
+	return picked
+}
+
+// rankDistractors сортирует кандидатов по убыванию сходства с правильным ответом.
+// Небольшая случайная добавка к оценке не дает вариантам повторяться из раза в раз.
+func rankDistractors(r *rand.Rand, correct distractor, candidates []distractor) []distractor {
+	scores := make(map[int]float64, len(candidates))
+	ranked := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		scores[i] = distractorScore(correct, candidate) + r.Float64()
+		ranked[i] = i
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] > scores[ranked[j]]
+	})
+
+	result := make([]distractor, len(ranked))
+	for i, idx := range ranked {
+		result[i] = candidates[idx]
+	}
+	return result
+}
+
+// distractorScore оценивает, насколько вариант похож на правильный ответ:
+// та же часть речи, общий тег или та же колода, похожая длина и число слов,
+// общее начало и малое расстояние Левенштейна. Чем выше оценка, тем труднее
+// отличить вариант.
+func distractorScore(correct, candidate distractor) float64 {
+	a, b := NormalizeAnswer(correct.text), NormalizeAnswer(candidate.text)
+	la, lb := len([]rune(a)), len([]rune(b))
+	longest := max(la, lb, 1)
+
+	score := 0.0
+	if correct.pos != "" && correct.pos == candidate.pos {
+		score += 3
+	}
+	// Слова одной темы труднее различить, чем случайные слова словаря
+	if sharesTag(correct.tags, candidate.tags) {
+		score += 2
+	}
+	if correct.deckID != 0 && correct.deckID == candidate.deckID {
+		score += 1
+	}
+	if len(strings.Fields(a)) == len(strings.Fields(b)) {
+		score += 2
+	}
+	score += 2 * (1 - float64(abs(la-lb))/float64(longest))
+	score += 0.5 * float64(min(commonPrefix(a, b), 3))
+	score += 1 - float64(textutil.Levenshtein(a, b))/float64(longest)
+	return score
+}
+
+// guessPartOfSpeech угадывает часть речи по окончанию слова. Для английских
+// и русских слов распознаются только надежные окончания, иначе возвращается "".
+func guessPartOfSpeech(text string) string {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if strings.HasPrefix(text, "to ") {
+		return posVerb
+	}
+	if strings.Contains(text, " ") {
+		return "" // Фразы не классифицируем
+	}
+
+	suffixes := []struct {
+		suffix string
+		pos    string
+	}{
+		// Русские окончания; «ость» раньше «ть», иначе существительное сочтется глаголом
+		{"ость", posNoun}, {"ение", posNoun}, {"ание", posNoun},
+		{"ться", posVerb}, {"ть", posVerb}, {"ти", posVerb},
+		{"ый", posAdjective}, {"ий", posAdjective}, {"ой", posAdjective}, {"ая", posAdjective}, {"яя", posAdjective},
+		// Английские окончания
+		{"tion", posNoun}, {"sion", posNoun}, {"ness", posNoun}, {"ment", posNoun}, {"ity", posNoun},
+		{"ship", posNoun},
+		{"ful", posAdjective}, {"ous", posAdjective}, {"ive", posAdjective}, {"able", posAdjective},
+		{"ible", posAdjective}, {"less", posAdjective},
+		{"ly", posAdverb},
+	}
+	for _, s := range suffixes {
+		if strings.HasSuffix(text, s.suffix) {
+			return s.pos
+		}
+	}
+	return ""
+}
+
+// sharesTag сообщает, есть ли у слов общий тег
+func sharesTag(a, b []string) bool {
+	for _, tag := range a {
+		for _, other := range b {
+			if tag == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// commonPrefix возвращает длину общего начала строк в символах
+func commonPrefix(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	n := 0
+	for n < len(ra) && n < len(rb) && ra[n] == rb[n] {
+		n++
+	}
+	return n
+}
+
+// abs возвращает модуль числа
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestGuessPartOfSpeech(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"to run", posVerb},
+		{"бегать", posVerb},
+		{"смеяться", posVerb},
+		{"красивый", posAdjective},
+		{"careful", posAdjective},
+		{"quickly", posAdverb},
+		{"радость", posNoun},
+		{"information", posNoun},
+		{"apple", ""},
+		{"ice cream", ""},
+	}
+
+	for _, tt := range tests {
+		if got := guessPartOfSpeech(tt.text); got != tt.want {
+			t.Errorf("guessPartOfSpeech(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDistractorScore_PrefersSimilar(t *testing.T) {
+	correct := distractor{text: "бегать", pos: guessPartOfSpeech("бегать")}
+
+	verb := distractorScore(correct, distractor{text: "бросать", pos: posVerb})
+	noun := distractor{text: "стол", pos: posNoun}
+	if verb <= distractorScore(correct, noun) {
+		t.Error("Expected a verb to score higher than a noun for a verb answer")
+	}
+
+	oneWord := distractorScore(correct, distractor{text: "кошка"})
+	phrase := distractorScore(correct, distractor{text: "большая белая кошка"})
+	if oneWord <= phrase {
+		t.Error("Expected a single word to score higher than a long phrase")
+	}
+}
+
+func TestDistractorScore_PrefersSameTagOrDeck(t *testing.T) {
+	correct := distractor{text: "поезд", deckID: 1, tags: []string{"travel"}}
+	plain := distractorScore(correct, distractor{text: "посуда", deckID: 2, tags: []string{"home"}})
+
+	sameTag := distractorScore(correct, distractor{text: "посуда", deckID: 2, tags: []string{"food", "travel"}})
+	if sameTag <= plain {
+		t.Errorf("Expected a word with a shared tag to outrank an equal candidate: %v <= %v", sameTag, plain)
+	}
+	sameDeck := distractorScore(correct, distractor{text: "посуда", deckID: 1})
+	if sameDeck <= plain {
+		t.Errorf("Expected a word from the same deck to outrank an equal candidate: %v <= %v", sameDeck, plain)
+	}
+
+	// Общий тег перевешивает небольшую случайную добавку при ранжировании
+	r := rand.New(rand.NewSource(1))
+	own := []distractor{{text: "посуда", deckID: 2}, {text: "погода", deckID: 2, tags: []string{"travel"}}}
+	if picked := pickDistractors(r, correct, own, nil, 1); picked[0] != "погода" {
+		t.Errorf("Expected the word with the shared tag first, got %v", picked)
+	}
+}
+
+func TestPickDistractors(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	own := []distractor{{text: "Яблоко"}, {text: "груша"}, {text: "груша"}}
+
+	picked := pickDistractors(r, distractor{text: "яблоко"}, own, frequencyDistractors(repository.DirectionForward), 3)
+	if len(picked) != 3 {
+		t.Fatalf("Expected 3 distractors, got %v", picked)
+	}
+	if picked[0] != "груша" {
+		t.Errorf("Expected user words first, got %v", picked)
+	}
+
+	seen := map[string]bool{"яблоко": true}
+	for _, option := range picked {
+		if seen[NormalizeAnswer(option)] {
+			t.Errorf("Duplicate or correct answer among distractors: %v", picked)
+		}
+		seen[NormalizeAnswer(option)] = true
+	}
+}
+
+func TestFrequencyWords(t *testing.T) {
+	if len(frequencyWords) < 100 {
+		t.Fatalf("Expected the built-in list to load, got %d words", len(frequencyWords))
+	}
+	for _, word := range frequencyWords {
+		switch word.partOfSpeech {
+		case posNoun, posVerb, posAdjective, posAdverb:
+		default:
+			t.Errorf("Unknown part of speech %q for %q", word.partOfSpeech, word.word.Word)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+	"github.com/AndrePim/telegram_english_learn_bot/internal/textutil"
+)
+
+// Способы поступить с новым словом, которое уже есть в словаре
+const (
+	DuplicateKeep        = "keep"    // Оставить прежнюю карточку без изменений
+	DuplicateAlternative = "alt"     // Добавить новый перевод как еще один вариант
+	DuplicateReplace     = "replace" // Заменить перевод и контекст прежней карточки
+)
+
+// ErrUnknownDuplicateChoice возвращается для неизвестного способа разрешить дубликат
+var ErrUnknownDuplicateChoice = errors.New("unknown duplicate choice")
+
+// DuplicateWordError возвращается AddWord, если у пользователя уже есть такое слово
+type DuplicateWordError struct {
+	Existing *repository.Word
+}
+
+func (e *DuplicateWordError) Error() string {
+	return fmt.Sprintf("word %q already exists", e.Existing.Word)
+}
+
+// FindDuplicate возвращает слово пользователя с тем же нормализованным видом или nil.
+// Поиск идет по индексу words_user_word_norm_idx, а не перебором словаря.
+func (s *WordService) FindDuplicate(userID int64, word string) (*repository.Word, error) {
+	return s.wordRepo.GetWordByNorm(userID, textutil.NormalizeWord(word))
+}
+
+// ResolveDuplicate применяет выбор пользователя к слову wordID, которое совпало
+// с добавляемым, и возвращает слово после изменения. Расписание повторений
+// прежней карточки сохраняется при любом выборе.
+func (s *WordService) ResolveDuplicate(userID int64, wordID int, choice, translation,
+	context string) (*repository.Word, error) {
+	word, err := s.GetWord(userID, wordID)
+	if err != nil {
+		return nil, err
+	}
+	if word == nil {
+		return nil, ErrWordNotFound
+	}
+
+	translation, context = strings.TrimSpace(translation), strings.TrimSpace(context)
+	switch choice {
+	case DuplicateKeep:
+		return word, nil
+	case DuplicateAlternative:
+		word.Translation = mergeTranslations(word.Translation, translation)
+		if word.Context == "" {
+			word.Context = context
+		}
+	case DuplicateReplace:
+		if translation == "" {
+			return nil, fmt.Errorf("word and translation cannot be empty")
+		}
+		word.Translation, word.Context = translation, context
+	default:
+		return nil, ErrUnknownDuplicateChoice
+	}
+
+	if err := s.wordRepo.UpdateWord(word); err != nil {
+		return nil, err
+	}
+	return word, nil
+}
+
+// DuplicateGroup — слова пользователя с одинаковым нормализованным видом.
+// Keep остается после объединения, Duplicates удаляются.
+type DuplicateGroup struct {
+	Keep       *repository.Word
+	Duplicates []*repository.Word
+}
+
+// FindDuplicates находит группы слов-дубликатов, упорядоченные по слову.
+// В каждой группе остается слово с лучшей историей повторений.
+func (s *WordService) FindDuplicates(userID int64) ([]DuplicateGroup, error) {
+	words, err := s.wordRepo.GetUserWords(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string][]*repository.Word)
+	var keys []string
+	for _, word := range words {
+		key := textutil.NormalizeWord(word.Word)
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], word)
+	}
+	sort.Strings(keys)
+
+	var groups []DuplicateGroup
+	for _, key := range keys {
+		group := byKey[key]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return betterHistory(group[i], group[j]) })
+		groups = append(groups, DuplicateGroup{Keep: group[0], Duplicates: group[1:]})
+	}
+	return groups, nil
+}
+
+// MergeDuplicates объединяет все группы дубликатов пользователя и возвращает,
+// сколько групп объединено и сколько лишних карточек удалено. Переводы
+// дубликатов добавляются к оставшемуся слову как варианты, а журнал ответов
+// переносится на него.
+func (s *WordService) MergeDuplicates(userID int64) (groups, removed int, err error) {
+	found, err := s.FindDuplicates(userID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, group := range found {
+		keep := *group.Keep
+		ids := make([]int, 0, len(group.Duplicates))
+		for _, duplicate := range group.Duplicates {
+			keep.Translation = mergeTranslations(keep.Translation, duplicate.Translation)
+			if keep.Context == "" {
+				keep.Context = duplicate.Context
+			}
+			ids = append(ids, duplicate.ID)
+		}
+
+		if err := s.wordRepo.MergeWords(&keep, ids); err != nil {
+			return groups, removed, err
+		}
+		groups++
+		removed += len(ids)
+	}
+	return groups, removed, nil
+}
+
+// betterHistory сообщает, что у слова a история повторений лучше, чем у b:
+// больше успешных повторений подряд, затем длиннее интервал, меньше забываний,
+// а при равенстве остается более раннее слово
+func betterHistory(a, b *repository.Word) bool {
+	if a.Repetitions != b.Repetitions {
+		return a.Repetitions > b.Repetitions
+	}
+	if a.Interval != b.Interval {
+		return a.Interval > b.Interval
+	}
+	if a.Lapses != b.Lapses {
+		return a.Lapses < b.Lapses
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// mergeTranslations добавляет к переводу варианты из extra, которых в нем еще нет
+func mergeTranslations(translation, extra string) string {
+	alternatives := AnswerAlternatives(translation)
+	seen := make(map[string]bool, len(alternatives))
+	for _, alternative := range alternatives {
+		seen[NormalizeAnswer(alternative)] = true
+	}
+
+	for _, alternative := range AnswerAlternatives(extra) {
+		if key := NormalizeAnswer(alternative); !seen[key] {
+			seen[key] = true
+			alternatives = append(alternatives, alternative)
+		}
+	}
+	return strings.Join(alternatives, ", ")
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// newTestWordStore создает сервис слов и хранилище, в которое можно записать
+// дубликаты в обход AddWord, как будто они остались с прежних версий
+func newTestWordStore(t *testing.T) (*WordService, repository.WordStore, repository.ReviewLogStore) {
+	t.Helper()
+
+	db := repository.NewMemoryDatabase()
+	if err := repository.NewMemoryUserRepository(db).CreateOrUpdateUser(&repository.User{ID: testUserID}); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	words, logs := repository.NewMemoryWordRepository(db), repository.NewMemoryReviewLogRepository(db)
+	return NewWordService(words, logs), words, logs
+}
+
+func TestWordService_AddWord_Duplicate(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+
+	for _, word := range []string{"Apple", "apple ", "  APPLE"} {
+		err := wordService.AddWord(testUserID, word, "яблоня", "")
+		var duplicate *DuplicateWordError
+		if !errors.As(err, &duplicate) || duplicate.Existing.Word != "apple" {
+			t.Errorf("AddWord(%q): expected duplicate of apple, got %v", word, err)
+		}
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 1 {
+		t.Errorf("Expected duplicates not to be saved, got %d words", len(words))
+	}
+}
+
+func TestWordService_ResolveDuplicate(t *testing.T) {
+	_, wordService := newTestServices(t)
+	if err := wordService.AddWord(testUserID, "apple", "яблоко", "red apple"); err != nil {
+		t.Fatalf("Failed to add word: %v", err)
+	}
+	words, _ := wordService.GetUserWords(testUserID)
+	id := words[0].ID
+
+	word, err := wordService.ResolveDuplicate(testUserID, id, DuplicateKeep, "яблоня", "")
+	if err != nil || word.Translation != "яблоко" {
+		t.Errorf("Expected keep to change nothing, got %+v, %v", word, err)
+	}
+
+	word, err = wordService.ResolveDuplicate(testUserID, id, DuplicateAlternative, "Яблоко; яблоня", "tree")
+	if err != nil || word.Translation != "яблоко, яблоня" || word.Context != "red apple" {
+		t.Errorf("Expected the new alternative only, got %+v, %v", word, err)
+	}
+
+	word, err = wordService.ResolveDuplicate(testUserID, id, DuplicateReplace, "яблоня", "")
+	if err != nil || word.Translation != "яблоня" || word.Context != "" {
+		t.Errorf("Expected translation and context to be replaced, got %+v, %v", word, err)
+	}
+
+	_, err = wordService.ResolveDuplicate(testUserID, id, "merge", "x", "")
+	if !errors.Is(err, ErrUnknownDuplicateChoice) {
+		t.Errorf("Expected ErrUnknownDuplicateChoice, got %v", err)
+	}
+	if _, err := wordService.ResolveDuplicate(testUserID+1, id, DuplicateReplace, "x", ""); err == nil {
+		t.Error("Expected error for someone else's word, got nil")
+	}
+
+	stored, _ := wordService.GetWord(testUserID, id)
+	if stored.Translation != "яблоня" {
+		t.Errorf("Expected the replaced translation to be stored, got %q", stored.Translation)
+	}
+}
+
+func TestWordService_MergeDuplicates(t *testing.T) {
+	wordService, words, logs := newTestWordStore(t)
+
+	legacy := []*repository.Word{
+		{UserID: testUserID, Word: "apple", Translation: "яблоко"},
+		{UserID: testUserID, Word: "Apple ", Translation: "яблоня", Context: "apple tree"},
+		{UserID: testUserID, Word: "APPLE", Translation: "Яблоко"},
+		{UserID: testUserID, Word: "pear", Translation: "груша"},
+	}
+	if err := words.SaveWords(legacy); err != nil {
+		t.Fatalf("Failed to save words: %v", err)
+	}
+	// У второй карточки лучшая история: она и должна остаться
+	better := repository.ReviewState{Interval: 6, Repetitions: 2, EaseFactor: 2.5}
+	if err := words.SaveReviewState(legacy[1].ID, better); err != nil {
+		t.Fatalf("Failed to save review state: %v", err)
+	}
+	if err := logs.SaveQuiz(&repository.Quiz{UserID: testUserID, WordID: legacy[0].ID, Correct: true}); err != nil {
+		t.Fatalf("Failed to save quiz: %v", err)
+	}
+
+	groups, err := wordService.FindDuplicates(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to find duplicates: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Keep.ID != legacy[1].ID || len(groups[0].Duplicates) != 2 {
+		t.Fatalf("Expected one group kept by the reviewed card, got %+v", groups)
+	}
+
+	merged, removed, err := wordService.MergeDuplicates(testUserID)
+	if err != nil || merged != 1 || removed != 2 {
+		t.Fatalf("Expected 1 group and 2 removed cards, got %d, %d, %v", merged, removed, err)
+	}
+
+	list, _ := wordService.GetUserWords(testUserID)
+	if len(list) != 2 {
+		t.Fatalf("Expected apple and pear to remain, got %d words", len(list))
+	}
+	kept, _ := wordService.GetWord(testUserID, legacy[1].ID)
+	if kept.Translation != "яблоня, яблоко" || kept.Context != "apple tree" || kept.Repetitions != 2 {
+		t.Errorf("Unexpected kept word: %+v", kept)
+	}
+	history, _ := logs.GetWordQuizzes(kept.ID)
+	if len(history) != 1 {
+		t.Errorf("Expected the review log to move to the kept word, got %d answers", len(history))
+	}
+
+	if groups, _ := wordService.FindDuplicates(testUserID); len(groups) != 0 {
+		t.Errorf("Expected no duplicates after merge, got %+v", groups)
+	}
+}
+
+func TestAddWizardService_Duplicate(t *testing.T) {
+	wizard, userService, wordService := newTestAddWizard(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+
+	_ = wizard.Start(testUserID)
+	_, _ = wizard.Input(testUserID, "Apple")
+	_, _ = wizard.Input(testUserID, "яблоня")
+	step, err := wizard.Skip(testUserID)
+	if err != nil || step.Step != AddStepDuplicate || step.Existing == nil || step.Existing.Word != "apple" {
+		t.Fatalf("Expected duplicate step, got %+v, %v", step, err)
+	}
+	if _, err := wizard.Input(testUserID, "whatever"); !errors.Is(err, ErrAddChoiceRequired) {
+		t.Errorf("Expected ErrAddChoiceRequired for text input, got %v", err)
+	}
+
+	word, err := wizard.ResolveDuplicate(testUserID, DuplicateAlternative)
+	if err != nil || word.Translation != "яблоко, яблоня" {
+		t.Fatalf("Expected alternative translation, got %+v, %v", word, err)
+	}
+	if _, err := wizard.ResolveDuplicate(testUserID, DuplicateKeep); !errors.Is(err, ErrNoAddWizard) {
+		t.Errorf("Expected ErrNoAddWizard after resolving, got %v", err)
+	}
+
+	user, _ := userService.GetUser(testUserID)
+	if user.State != StateIdle {
+		t.Errorf("Expected idle state, got %q", user.State)
+	}
+}
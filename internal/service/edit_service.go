@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// Поля слова, которые можно изменить в /edit
+const (
+	EditFieldWord        = "word"
+	EditFieldTranslation = "translation"
+	EditFieldContext     = "context"
+)
+
+// ClearContextInput — ввод, которым контекст слова удаляется
+const ClearContextInput = "-"
+
+// Ошибки редактирования слова
+var (
+	ErrNoWordEdit       = errors.New("word edit is not active")
+	ErrUnknownEditField = errors.New("unknown word field")
+	ErrWordNotFound     = errors.New("word not found")
+)
+
+// editStatePrefix начинает users.state, пока бот ждет новое значение поля.
+// Полное состояние: edit:<поле>:<ID слова>.
+const editStatePrefix = "edit:"
+
+// IsEditState сообщает, что пользователь сейчас вводит новое значение поля слова
+func IsEditState(state string) bool {
+	return strings.HasPrefix(state, editStatePrefix)
+}
+
+// EditWord заменяет поле field слова пользователя значением value. Расписание
+// повторений не меняется. Слово и перевод не могут быть пустыми, а новое
+// написание не должно совпадать с другим словом словаря.
+func (s *WordService) EditWord(userID int64, wordID int, field, value string) (*repository.Word, error) {
+	word, err := s.GetWord(userID, wordID)
+	if err != nil {
+		return nil, err
+	}
+	if word == nil {
+		return nil, ErrWordNotFound
+	}
+
+	value = strings.TrimSpace(value)
+	switch field {
+	case EditFieldWord:
+		if value == "" {
+			return nil, ErrAddFieldRequired
+		}
+		existing, err := s.FindDuplicate(userID, value)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != word.ID {
+			return nil, &DuplicateWordError{Existing: existing}
+		}
+		word.Word = value
+	case EditFieldTranslation:
+		if value == "" {
+			return nil, ErrAddFieldRequired
+		}
+		word.Translation = value
+	case EditFieldContext:
+		if value == ClearContextInput {
+			value = ""
+		}
+		word.Context = value
+	default:
+		return nil, ErrUnknownEditField
+	}
+
+	if err := s.wordRepo.UpdateWord(word); err != nil {
+		return nil, err
+	}
+	return word, nil
+}
+
+// ResetProgress начинает изучение слова заново в обоих направлениях:
+// слово снова считается новым и сразу попадает в повторение
+func (s *WordService) ResetProgress(userID int64, wordID int) (*repository.Word, error) {
+	word, err := s.GetWord(userID, wordID)
+	if err != nil {
+		return nil, err
+	}
+	if word == nil {
+		return nil, ErrWordNotFound
+	}
+
+	state := newReviewState(word.CreatedAt)
+	state.NextReview = time.Now()
+	if err := s.wordRepo.ResetReviewState(word.ID, state); err != nil {
+		return nil, err
+	}
+
+	word.ReviewState = state
+	return word, nil
+}
+
+// WordEditService ведет диалог /edit: пользователь выбирает поле кнопкой,
+// а следующее сообщение без команды становится новым значением
+type WordEditService struct {
+	wordService *WordService
+	userService *UserService
+}
+
+// NewWordEditService создает сервис редактирования слов
+func NewWordEditService(wordService *WordService, userService *UserService) *WordEditService {
+	return &WordEditService{wordService: wordService, userService: userService}
+}
+
+// Start запоминает, какое поле какого слова пользователь будет вводить,
+// и возвращает слово
+func (s *WordEditService) Start(userID int64, wordID int, field string) (*repository.Word, error) {
+	if field != EditFieldWord && field != EditFieldTranslation && field != EditFieldContext {
+		return nil, ErrUnknownEditField
+	}
+
+	word, err := s.wordService.GetWord(userID, wordID)
+	if err != nil {
+		return nil, err
+	}
+	if word == nil {
+		return nil, ErrWordNotFound
+	}
+
+	state := fmt.Sprintf("%s%s:%d", editStatePrefix, field, wordID)
+	if err := s.userService.UpdateUserState(userID, state); err != nil {
+		return nil, err
+	}
+	return word, nil
+}
+
+// Input сохраняет введенное значение поля и завершает ввод. При ошибке
+// проверки значения ввод продолжается, чтобы пользователь мог исправить текст.
+func (s *WordEditService) Input(userID int64, text string) (*repository.Word, error) {
+	user, err := s.userService.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrNoWordEdit
+	}
+	field, wordID, ok := parseEditState(user.State)
+	if !ok {
+		return nil, ErrNoWordEdit
+	}
+
+	word, err := s.wordService.EditWord(userID, wordID, field, text)
+	if errors.Is(err, ErrWordNotFound) {
+		// Слово удалили, пока пользователь вводил текст: ждать больше нечего
+		if err := s.userService.UpdateUserState(userID, StateIdle); err != nil {
+			return nil, err
+		}
+		return nil, ErrWordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.UpdateUserState(userID, StateIdle); err != nil {
+		return nil, err
+	}
+	return word, nil
+}
+
+// Cancel прекращает ввод значения, если бот его ждал
+func (s *WordEditService) Cancel(userID int64) error {
+	user, err := s.userService.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !IsEditState(user.State) {
+		return nil
+	}
+	return s.userService.UpdateUserState(userID, StateIdle)
+}
+
+// parseEditState разбирает состояние edit:<поле>:<ID слова>
+func parseEditState(state string) (field string, wordID int, ok bool) {
+	rest, found := strings.CutPrefix(state, editStatePrefix)
+	if !found {
+		return "", 0, false
+	}
+	field, id, found := strings.Cut(rest, ":")
+	if !found {
+		return "", 0, false
+	}
+	wordID, err := strconv.Atoi(id)
+	if err != nil {
+		return "", 0, false
+	}
+	return field, wordID, true
+}
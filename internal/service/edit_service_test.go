@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestWordService_EditWord_KeepsSchedule(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "aple", "яблоко", "pear", "груша")
+	words, _ := wordService.GetUserWords(testUserID)
+	aple, pear := words[1], words[0]
+
+	_, err := wordService.ReviewWord(repository.SchedulerSM2, testUserID, aple.ID, ResultFromQuality(QualityPerfect))
+	if err != nil {
+		t.Fatalf("Failed to review word: %v", err)
+	}
+	before, _ := wordService.GetWord(testUserID, aple.ID)
+
+	word, err := wordService.EditWord(testUserID, aple.ID, EditFieldWord, " apple ")
+	if err != nil || word.Word != "apple" {
+		t.Fatalf("Expected the word to be fixed, got %+v, %v", word, err)
+	}
+	if _, err := wordService.EditWord(testUserID, aple.ID, EditFieldContext, "red apple"); err != nil {
+		t.Fatalf("Failed to edit context: %v", err)
+	}
+
+	after, _ := wordService.GetWord(testUserID, aple.ID)
+	if after.Context != "red apple" || after.Interval != before.Interval || !after.NextReview.Equal(before.NextReview) {
+		t.Errorf("Expected fields to change and schedule to stay, before %+v, after %+v", before, after)
+	}
+
+	if word, _ := wordService.EditWord(testUserID, aple.ID, EditFieldContext, ClearContextInput); word.Context != "" {
+		t.Errorf("Expected context to be cleared, got %q", word.Context)
+	}
+	_, err = wordService.EditWord(testUserID, aple.ID, EditFieldTranslation, " ")
+	if !errors.Is(err, ErrAddFieldRequired) {
+		t.Errorf("Expected ErrAddFieldRequired for empty translation, got %v", err)
+	}
+	var duplicate *DuplicateWordError
+	_, err = wordService.EditWord(testUserID, aple.ID, EditFieldWord, "Pear")
+	if !errors.As(err, &duplicate) || duplicate.Existing.ID != pear.ID {
+		t.Errorf("Expected duplicate of pear, got %v", err)
+	}
+	if _, err := wordService.EditWord(testUserID, aple.ID, "level", "x"); !errors.Is(err, ErrUnknownEditField) {
+		t.Errorf("Expected ErrUnknownEditField, got %v", err)
+	}
+	if _, err := wordService.EditWord(testUserID+1, aple.ID, EditFieldWord, "x"); !errors.Is(err, ErrWordNotFound) {
+		t.Errorf("Expected ErrWordNotFound for someone else's word, got %v", err)
+	}
+}
+
+func TestWordService_ResetProgress(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+	words, _ := wordService.GetUserWords(testUserID)
+	id := words[0].ID
+
+	for i := 0; i < 3; i++ {
+		_, err := wordService.ReviewWord(repository.SchedulerSM2, testUserID, id, ResultFromQuality(QualityPerfect))
+		if err != nil {
+			t.Fatalf("Failed to review word: %v", err)
+		}
+	}
+
+	word, err := wordService.ResetProgress(testUserID, id)
+	if err != nil {
+		t.Fatalf("Failed to reset progress: %v", err)
+	}
+	stored, _ := wordService.GetWord(testUserID, id)
+	if !stored.IsNew() || stored.Repetitions != 0 || stored.Interval != word.Interval {
+		t.Errorf("Expected a new word, got %+v", stored.ReviewState)
+	}
+	if stored.NextReview.After(time.Now()) {
+		t.Errorf("Expected the word to be due right away, got %v", stored.NextReview)
+	}
+}
+
+func TestWordEditService_Flow(t *testing.T) {
+	userService, wordService := newTestServices(t)
+	edits := NewWordEditService(wordService, userService)
+	addTestWords(t, wordService, "apple", "яблоко")
+	words, _ := wordService.GetUserWords(testUserID)
+	id := words[0].ID
+
+	if _, err := edits.Input(testUserID, "x"); !errors.Is(err, ErrNoWordEdit) {
+		t.Errorf("Expected ErrNoWordEdit before start, got %v", err)
+	}
+	if _, err := edits.Start(testUserID, id, "level"); !errors.Is(err, ErrUnknownEditField) {
+		t.Errorf("Expected ErrUnknownEditField, got %v", err)
+	}
+
+	if _, err := edits.Start(testUserID, id, EditFieldTranslation); err != nil {
+		t.Fatalf("Failed to start edit: %v", err)
+	}
+	user, _ := userService.GetUser(testUserID)
+	if !IsEditState(user.State) {
+		t.Fatalf("Expected edit state, got %q", user.State)
+	}
+
+	// Пустое значение не принимается, но ввод продолжается
+	if _, err := edits.Input(testUserID, ""); !errors.Is(err, ErrAddFieldRequired) {
+		t.Errorf("Expected ErrAddFieldRequired, got %v", err)
+	}
+	word, err := edits.Input(testUserID, "яблоко, яблоня")
+	if err != nil || word.Translation != "яблоко, яблоня" {
+		t.Fatalf("Expected the translation to be saved, got %+v, %v", word, err)
+	}
+
+	user, _ = userService.GetUser(testUserID)
+	if user.State != StateIdle {
+		t.Errorf("Expected idle state after input, got %q", user.State)
+	}
+}
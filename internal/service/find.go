@@ -0,0 +1,24 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// FindResultLimit — сколько лучших совпадений показывает /find
+const FindResultLimit = 10
+
+// ErrEmptySearch возвращается для пустого поискового запроса
+var ErrEmptySearch = errors.New("search query is empty")
+
+// FindWords ищет слова пользователя по слову, переводу и контексту.
+// Небольшие опечатки в запросе допускаются; лучшие совпадения идут первыми.
+func (s *WordService) FindWords(userID int64, query string) ([]*repository.Word, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return nil, ErrEmptySearch
+	}
+	return s.wordRepo.SearchWords(userID, query, FindResultLimit)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWordService_FindWords(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко", "pear", "груша", "pineapple", "ананас")
+
+	words, err := wordService.FindWords(testUserID, "  aplle ")
+	if err != nil {
+		t.Fatalf("Failed to find words: %v", err)
+	}
+	if len(words) == 0 || words[0].Word != "apple" {
+		t.Errorf("Expected apple despite the typo, got %+v", words)
+	}
+
+	if words, _ := wordService.FindWords(testUserID, "груш"); len(words) != 1 || words[0].Word != "pear" {
+		t.Errorf("Expected pear by its translation, got %+v", words)
+	}
+
+	if _, err := wordService.FindWords(testUserID, "   "); !errors.Is(err, ErrEmptySearch) {
+		t.Errorf("Expected ErrEmptySearch, got %v", err)
+	}
+}
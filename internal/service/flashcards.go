@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// Ошибки оценки карточки в /review
+var (
+	ErrCardNotFound = errors.New("card not found")
+	ErrCardNotDue   = errors.New("card is not due for review")
+)
+
+// NextCard возвращает следующую карточку для повторения или nil, если
+// на сегодня все повторено. Карточки показываются в прямом направлении:
+// на лицевой стороне слово, на обороте перевод и контекст. Если задан tagID,
+// повторяются слова с этим тегом из всех колод, иначе — слова активной колоды,
+// причем новых слов за день показывается не больше лимита колоды.
+func (s *WordService) NextCard(userID int64, tagID int) (*repository.Word, error) {
+	now := time.Now()
+	query := repository.WordListQuery{UserID: userID, Sort: repository.WordSortDue, DueBy: now, TagID: tagID}
+	if tagID != 0 {
+		query.Limit = 1
+		words, err := s.wordRepo.ListWords(query)
+		if err != nil || len(words) == 0 {
+			return nil, err
+		}
+		return words[0], nil
+	}
+
+	deck, err := s.ActiveDeck(userID)
+	if err != nil {
+		return nil, err
+	}
+	if deck == nil {
+		words, err := s.wordRepo.GetWordsForReview(userID)
+		if err != nil || len(words) == 0 {
+			return nil, err
+		}
+		return words[0], nil
+	}
+
+	query.DeckID = deck.ID
+	words, err := s.wordRepo.ListWords(query)
+	if err != nil {
+		return nil, err
+	}
+	newLeft, err := s.newCardsLeft(userID, deck, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range words {
+		if !word.IsNew() || newLeft > 0 {
+			return word, nil
+		}
+	}
+	return nil, nil
+}
+
+// GradeCard обновляет расписание карточки по самооценке пользователя
+// (RatingAgain…RatingEasy) и записывает ответ в журнал вместе с latency — временем,
+// за которое пользователь вспомнил перевод. Оценить можно только карточку, которую
+// пора повторять: проверка и запись расписания атомарны, поэтому повторное нажатие
+// ничего не меняет. Если ответ не записан в журнал, прежнее расписание
+// восстанавливается и карточку можно оценить снова.
+func (s *WordService) GradeCard(scheduler string, userID int64, wordID, rating int,
+	latency time.Duration) (*ReviewChange, error) {
+	result := ResultFromRating(rating)
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+
+	word, err := s.GetWord(userID, wordID)
+	if err != nil {
+		return nil, err
+	}
+	if word == nil {
+		return nil, ErrCardNotFound
+	}
+
+	before, after, err := s.planReview(scheduler, userID, wordID, repository.DirectionForward, result)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.wordRepo.SaveDueReviewState(wordID, *after, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrCardNotDue
+	}
+
+	err = s.reviewLog.SaveQuiz(&repository.Quiz{
+		UserID:         userID,
+		WordID:         wordID,
+		Direction:      repository.DirectionForward,
+		Correct:        rating != RatingAgain,
+		IntervalBefore: before.Interval,
+		IntervalAfter:  after.Interval,
+		LatencyMs:      latency.Milliseconds(),
+	})
+	if err != nil {
+		if restoreErr := s.wordRepo.SaveReviewState(wordID, *before); restoreErr != nil {
+			log.Printf("Failed to restore review state: %v", restoreErr)
+		}
+		return nil, err
+	}
+
+	return &ReviewChange{Before: *before, After: *after}, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestWordService_GradeCard(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+
+	card, err := wordService.NextCard(testUserID, 0)
+	if err != nil || card != nil {
+		t.Fatalf("Expected no due cards right after adding, got %v, %v", card, err)
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	state := words[0].ReviewState
+	state.NextReview = time.Now().Add(-time.Hour)
+	if err := wordService.wordRepo.SaveReviewState(words[0].ID, state); err != nil {
+		t.Fatalf("Failed to save review state: %v", err)
+	}
+
+	card, err = wordService.NextCard(testUserID, 0)
+	if err != nil || card == nil || card.ID != words[0].ID {
+		t.Fatalf("Expected the due card, got %v, %v", card, err)
+	}
+
+	if _, err := wordService.GradeCard(repository.SchedulerSM2, testUserID, card.ID, 5, 0); err == nil {
+		t.Error("Expected error for unknown rating, got nil")
+	}
+	_, err = wordService.GradeCard(repository.SchedulerSM2, testUserID+1, card.ID, RatingGood, 0)
+	if !errors.Is(err, ErrCardNotFound) {
+		t.Errorf("Expected ErrCardNotFound for another user, got %v", err)
+	}
+
+	change, err := wordService.GradeCard(repository.SchedulerSM2, testUserID, card.ID, RatingGood, 0)
+	if err != nil {
+		t.Fatalf("Failed to grade card: %v", err)
+	}
+	if !change.After.NextReview.After(time.Now()) {
+		t.Errorf("Expected the card to be scheduled in the future, got %v", change.After.NextReview)
+	}
+
+	_, err = wordService.GradeCard(repository.SchedulerSM2, testUserID, card.ID, RatingEasy, 0)
+	if !errors.Is(err, ErrCardNotDue) {
+		t.Errorf("Expected ErrCardNotDue for a graded card, got %v", err)
+	}
+
+	history, _ := wordService.GetAnswerHistory(testUserID, 10)
+	if len(history) != 1 || !history[0].Correct {
+		t.Errorf("Expected one correct answer in the log, got %+v", history)
+	}
+}
+
+func TestWordService_GradeCard_RetriesAfterFailedRecord(t *testing.T) {
+	db := repository.NewMemoryDatabase()
+	reviewLog := &failingReviewLog{ReviewLogStore: repository.NewMemoryReviewLogRepository(db)}
+	wordService := NewWordService(repository.NewMemoryWordRepository(db), reviewLog)
+	userService := NewUserService(repository.NewMemoryUserRepository(db))
+	if err := userService.RegisterUser(testUserID, "tester", "Test", ""); err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	addTestWords(t, wordService, "apple", "яблоко")
+
+	words, _ := wordService.GetUserWords(testUserID)
+	due := words[0].ReviewState
+	due.NextReview = time.Now().Add(-time.Hour)
+	if err := wordService.wordRepo.SaveReviewState(words[0].ID, due); err != nil {
+		t.Fatalf("Failed to save review state: %v", err)
+	}
+
+	reviewLog.fail = true
+	_, err := wordService.GradeCard(repository.SchedulerSM2, testUserID, words[0].ID, RatingGood, time.Second)
+	if err == nil {
+		t.Fatal("Expected the failed log write to fail the grade, got nil")
+	}
+	if word, _ := wordService.GetWord(testUserID, words[0].ID); word.NextReview.After(time.Now()) {
+		t.Fatalf("Expected the schedule to be restored, got next review %v", word.NextReview)
+	}
+
+	reviewLog.fail = false
+	_, err = wordService.GradeCard(repository.SchedulerSM2, testUserID, words[0].ID, RatingGood, 3*time.Second)
+	if err != nil {
+		t.Fatalf("Expected the retry to grade the card, got %v", err)
+	}
+	history, _ := wordService.GetAnswerHistory(testUserID, 10)
+	if len(history) != 1 || history[0].LatencyMs != 3000 {
+		t.Errorf("Expected one answer with its latency in the log, got %+v", history)
+	}
+}
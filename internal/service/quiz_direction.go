@@ -0,0 +1,96 @@
+package service
+
+import (
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// QuizModes перечисляет режимы /quiz в порядке показа в /settings
+var QuizModes = []string{repository.DirectionForward, repository.DirectionReverse, repository.QuizModeMixed}
+
+// IsKnownQuizMode сообщает, есть ли режим /quiz с таким именем
+func IsKnownQuizMode(mode string) bool {
+	for _, known := range QuizModes {
+		if mode == known {
+			return true
+		}
+	}
+	return false
+}
+
+// QuizDirection возвращает направление вопроса с номером position (с нуля)
+// в раунде режима mode. Смешанный режим начинает с прямого направления и чередует их.
+func QuizDirection(mode string, position int) string {
+	switch mode {
+	case repository.DirectionReverse:
+		return repository.DirectionReverse
+	case repository.QuizModeMixed:
+		if position%2 == 1 {
+			return repository.DirectionReverse
+		}
+	}
+	return repository.DirectionForward
+}
+
+// newReviewState возвращает состояние слова, которое еще не повторялось:
+// такое же, какое получает только что добавленное слово
+func newReviewState(createdAt time.Time) repository.ReviewState {
+	return repository.ReviewState{
+		LastReview: createdAt,
+		NextReview: createdAt.AddDate(0, 0, 1),
+		Interval:   1,
+		EaseFactor: 2.5,
+	}
+}
+
+// directionState возвращает состояние слова в направлении direction.
+// В обратном направлении слово без истории начинает как новое.
+func (s *WordService) directionState(word *repository.Word, direction string) (repository.ReviewState, error) {
+	if direction != repository.DirectionReverse {
+		return word.ReviewState, nil
+	}
+
+	state, err := s.wordRepo.GetReverseReviewState(word.ID)
+	if err != nil {
+		return repository.ReviewState{}, err
+	}
+	if state == nil {
+		return newReviewState(word.CreatedAt), nil
+	}
+	return *state, nil
+}
+
+// saveDirectionState сохраняет состояние слова в направлении direction
+func (s *WordService) saveDirectionState(wordID int, direction string, state repository.ReviewState) error {
+	if direction == repository.DirectionReverse {
+		return s.wordRepo.SaveReverseReviewState(wordID, state)
+	}
+	return s.wordRepo.SaveReviewState(wordID, state)
+}
+
+// wordsInDirection возвращает копии слов, в которых ReviewState заменено
+// состоянием в направлении direction, чтобы выбирать слова по нужному расписанию
+func (s *WordService) wordsInDirection(userID int64, words []*repository.Word,
+	direction string) ([]*repository.Word, error) {
+	if direction != repository.DirectionReverse {
+		return words, nil
+	}
+
+	states, err := s.wordRepo.GetReverseReviewStates(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*repository.Word, 0, len(words))
+	for _, word := range words {
+		copied := *word
+		if state, ok := states[word.ID]; ok {
+			copied.ReviewState = state
+		} else {
+			copied.ReviewState = newReviewState(word.CreatedAt)
+		}
+		result = append(result, &copied)
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestQuizDirection(t *testing.T) {
+	tests := []struct {
+		mode     string
+		position int
+		want     string
+	}{
+		{repository.DirectionForward, 1, repository.DirectionForward},
+		{repository.DirectionReverse, 0, repository.DirectionReverse},
+		{repository.QuizModeMixed, 0, repository.DirectionForward},
+		{repository.QuizModeMixed, 1, repository.DirectionReverse},
+		{repository.QuizModeMixed, 2, repository.DirectionForward},
+		{"", 0, repository.DirectionForward},
+	}
+
+	for _, tt := range tests {
+		if got := QuizDirection(tt.mode, tt.position); got != tt.want {
+			t.Errorf("QuizDirection(%q, %d) = %q, want %q", tt.mode, tt.position, got, tt.want)
+		}
+	}
+}
+
+func TestWordService_GenerateQuiz_Reverse(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко", "pear", "груша", "plum", "слива", "lemon", "лимон")
+
+	quiz, err := wordService.GenerateQuiz(testUserID, nil, repository.DirectionReverse, 0)
+	if err != nil {
+		t.Fatalf("Failed to generate quiz: %v", err)
+	}
+	target, err := wordService.GetWord(testUserID, quiz.WordID)
+	if err != nil || target == nil {
+		t.Fatalf("Quiz refers to unknown word %d: %v", quiz.WordID, err)
+	}
+
+	if quiz.Direction != repository.DirectionReverse {
+		t.Errorf("Expected reverse direction, got %q", quiz.Direction)
+	}
+	if quiz.Question != "Как по-английски: "+target.Translation+"?" {
+		t.Errorf("Expected question about the translation, got %q", quiz.Question)
+	}
+	if quiz.Options[quiz.CorrectIdx] != target.Word {
+		t.Errorf("Expected correct option %q, got %q", target.Word, quiz.Options[quiz.CorrectIdx])
+	}
+	for _, option := range quiz.Options {
+		if strings.ContainsAny(option, "аеилоуя") {
+			t.Errorf("Expected English words as options, got %v", quiz.Options)
+		}
+	}
+}
+
+func TestWordService_RecordAnswer_Reverse(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+	words, _ := wordService.GetUserWords(testUserID)
+	word := words[0]
+
+	change, err := wordService.RecordAnswer(repository.SchedulerSM2, QuizAnswer{
+		UserID: testUserID, WordID: word.ID, ChosenOption: "apple", Direction: repository.DirectionReverse, Correct: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to record answer: %v", err)
+	}
+	if change.Before.Repetitions != 0 || change.After.Repetitions != 1 {
+		t.Errorf("Expected reverse direction to start as new, got %+v", change)
+	}
+
+	reverse, err := wordService.wordRepo.GetReverseReviewState(word.ID)
+	if err != nil || reverse == nil || reverse.Repetitions != 1 {
+		t.Fatalf("Expected reverse state to be saved, got %+v, %v", reverse, err)
+	}
+	forward, _ := wordService.GetWord(testUserID, word.ID)
+	if forward.Repetitions != 0 || !forward.NextReview.Equal(word.NextReview) {
+		t.Errorf("Expected forward state to be untouched, got %+v", forward.ReviewState)
+	}
+
+	history, _ := wordService.GetAnswerHistory(testUserID, 1)
+	if len(history) != 1 || history[0].Direction != repository.DirectionReverse {
+		t.Errorf("Expected reverse answer in the log, got %+v", history)
+	}
+
+	// При смене алгоритма переводится и обратное направление
+	if err := wordService.ConvertWordsToScheduler(testUserID, repository.SchedulerFSRS); err != nil {
+		t.Fatalf("Failed to convert words: %v", err)
+	}
+	reverse, _ = wordService.wordRepo.GetReverseReviewState(word.ID)
+	if reverse.Stability == 0 {
+		t.Errorf("Expected reverse state to be converted to FSRS, got %+v", reverse)
+	}
+}
+
+func TestQuizService_MixedRound(t *testing.T) {
+	quizService, _, _ := newTestQuizService(t)
+
+	round, session, err := quizService.StartRound(testUserID, testUserID, 3, repository.QuizModeMixed, 0)
+	if err != nil {
+		t.Fatalf("Failed to start round: %v", err)
+	}
+
+	var directions []string
+	for i := 0; i < round.Size; i++ {
+		directions = append(directions, session.Direction)
+		result, err := quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, session.ID, session.CorrectIdx)
+		if err != nil {
+			t.Fatalf("Failed to answer question %d: %v", i+1, err)
+		}
+		session = result.Next
+	}
+
+	want := "forward reverse forward"
+	if got := strings.Join(directions, " "); got != want {
+		t.Errorf("Expected directions %q, got %q", want, got)
+	}
+
+	if _, _, err := quizService.StartRound(testUserID, testUserID, 3, "sideways", 0); err == nil {
+		t.Error("Expected error for unknown mode, got nil")
+	}
+}
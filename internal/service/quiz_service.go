@@ -0,0 +1,422 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// quizSessionTTL — сколько времени можно отвечать на вопросы раунда
+const quizSessionTTL = 24 * time.Hour
+
+// Размер раунда теста
+const (
+	DefaultQuizRoundSize = 5
+	MaxQuizRoundSize     = 20
+)
+
+// Ошибки ответа на тест, которые бот показывает пользователю
+var (
+	ErrQuizSessionNotFound  = errors.New("quiz session not found")
+	ErrQuizSessionForbidden = errors.New("quiz session belongs to another user")
+	ErrQuizSessionExpired   = errors.New("quiz session expired")
+	ErrQuizRoundFinished    = errors.New("quiz round already finished")
+)
+
+// QuizService выдает вопросы теста и проверяет ответы по сессиям,
+// сохраненным на сервере
+type QuizService struct {
+	wordService *WordService
+	sessions    repository.QuizSessionStore
+	now         func() time.Time
+}
+
+// NewQuizService создает сервис тестов
+func NewQuizService(wordService *WordService, sessions repository.QuizSessionStore) *QuizService {
+	return &QuizService{
+		wordService: wordService,
+		sessions:    sessions,
+		now:         time.Now,
+	}
+}
+
+// QuizResult описывает результат ответа на вопрос теста
+type QuizResult struct {
+	Correct         bool
+	ChosenOption    string
+	CorrectOption   string
+	AlreadyAnswered bool // Ответ уже был засчитан раньше, повторное нажатие ничего не меняет
+
+	Round   *repository.QuizRound
+	Next    *repository.QuizSession // Следующий вопрос раунда
+	Summary *RoundSummary           // Итог, если вопрос был последним
+}
+
+// RoundSummary — итог раунда теста
+type RoundSummary struct {
+	Round    *repository.QuizRound
+	Answered int
+	Correct  int
+	Items    []RoundItem // Отвеченные вопросы в порядке показа
+}
+
+// RoundItem — результат одного вопроса раунда
+type RoundItem struct {
+	Word             string
+	Translation      string
+	Correct          bool
+	NextReviewBefore time.Time
+	NextReviewAfter  time.Time
+}
+
+// Missed возвращает вопросы, на которые пользователь ответил неправильно
+func (s *RoundSummary) Missed() []RoundItem {
+	var missed []RoundItem
+	for _, item := range s.Items {
+		if !item.Correct {
+			missed = append(missed, item)
+		}
+	}
+	return missed
+}
+
+// StartRound начинает раунд из size вопросов в режиме mode и возвращает первый вопрос.
+// Если слов меньше, чем size, раунд укорачивается: слова в раунде не повторяются.
+// Если задан tagID, вопросы раунда только по словам с этим тегом. chatID — чат,
+// куда отправлять следующие вопросы раунда.
+func (s *QuizService) StartRound(userID, chatID int64, size int, mode string, tagID int) (*repository.QuizRound,
+	*repository.QuizSession, error) {
+	if size < 1 || size > MaxQuizRoundSize {
+		return nil, nil, fmt.Errorf("round size must be between 1 and %d", MaxQuizRoundSize)
+	}
+	if !IsKnownQuizMode(mode) {
+		return nil, nil, fmt.Errorf("unknown quiz mode %q", mode)
+	}
+
+	available, err := s.wordService.CountWords(userID, tagID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if available < size {
+		size = available
+	}
+
+	// Проверяем, что из слов можно составить вопрос, до создания раунда
+	quiz, err := s.wordService.GenerateQuiz(userID, nil, QuizDirection(mode, 0), tagID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := s.now()
+	round := &repository.QuizRound{
+		ID:        id,
+		UserID:    userID,
+		Size:      size,
+		CreatedAt: now,
+		ExpiresAt: now.Add(quizSessionTTL),
+		Mode:      mode,
+		TagID:     tagID,
+		ChatID:    chatID,
+	}
+	if err := s.sessions.CreateQuizRound(round); err != nil {
+		return nil, nil, err
+	}
+
+	session, err := s.createSession(round, 0, quiz)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Заодно убираем сессии, на которые уже нельзя ответить
+	if err := s.sessions.DeleteExpiredQuizSessions(now); err != nil {
+		log.Printf("Failed to delete expired quiz sessions: %v", err)
+	}
+
+	return round, session, nil
+}
+
+// AnswerQuiz проверяет ответ пользователя, обновляет расписание слова и переходит
+// к следующему вопросу раунда. Повторный ответ на ту же сессию возвращает первый
+// результат и ничего не меняет.
+func (s *QuizService) AnswerQuiz(scheduler string, userID int64, sessionID string, chosenIdx int) (*QuizResult, error) {
+	session, err := s.sessions.GetQuizSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrQuizSessionNotFound
+	}
+	if session.UserID != userID {
+		return nil, ErrQuizSessionForbidden
+	}
+	if session.Answered() {
+		return sessionResult(session, session.ChosenIdx, true), nil
+	}
+	if chosenIdx < 0 || chosenIdx >= len(session.Options) {
+		return nil, fmt.Errorf("option %d is out of range", chosenIdx)
+	}
+
+	var round *repository.QuizRound
+	if session.RoundID != "" {
+		round, err = s.sessions.GetQuizRound(session.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		if round == nil {
+			return nil, ErrQuizSessionNotFound
+		}
+		if round.Finished() {
+			return nil, ErrQuizRoundFinished
+		}
+	}
+
+	now := s.now()
+	if now.After(session.ExpiresAt) {
+		return nil, ErrQuizSessionExpired
+	}
+
+	// Отметка ответа атомарна: из двух одновременных нажатий засчитывается одно
+	marked, err := s.sessions.MarkQuizSessionAnswered(sessionID, chosenIdx, now)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		session, err = s.sessions.GetQuizSession(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if session == nil {
+			return nil, ErrQuizSessionNotFound
+		}
+		return sessionResult(session, session.ChosenIdx, true), nil
+	}
+
+	result := sessionResult(session, chosenIdx, false)
+	change, err := s.wordService.RecordAnswer(scheduler, QuizAnswer{
+		UserID:       userID,
+		WordID:       session.WordID,
+		ChosenOption: result.ChosenOption,
+		Direction:    session.Direction,
+		Correct:      result.Correct,
+		Latency:      now.Sub(session.CreatedAt),
+	})
+	if err != nil {
+		// Ответ не засчитан: снимаем отметку, чтобы пользователь мог ответить еще раз
+		if clearErr := s.sessions.ClearQuizSessionAnswer(sessionID); clearErr != nil {
+			log.Printf("Failed to clear quiz session answer: %v", clearErr)
+		}
+		return nil, err
+	}
+
+	err = s.sessions.SaveQuizSessionReview(sessionID, change.Before.NextReview, change.After.NextReview)
+	if err != nil {
+		// Без этого в итоге раунда не будет сдвига даты, но сам ответ уже засчитан
+		log.Printf("Failed to save quiz session review: %v", err)
+	}
+
+	if round != nil {
+		result.Round = round
+		result.Next, result.Summary, err = s.advanceRound(round, session.Position+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// AttachPoll запоминает опрос Telegram, в котором задан вопрос сессии,
+// чтобы ответ на опрос можно было сопоставить с сессией
+func (s *QuizService) AttachPoll(sessionID, pollID string) error {
+	return s.sessions.SetQuizSessionPoll(sessionID, pollID)
+}
+
+// AnswerPoll проверяет ответ на опрос Telegram так же, как AnswerQuiz.
+// В обновлении poll_answer есть только ID опроса, поэтому сессия ищется по нему.
+func (s *QuizService) AnswerPoll(scheduler string, userID int64, pollID string, chosenIdx int) (*QuizResult, error) {
+	session, err := s.sessions.GetQuizSessionByPoll(pollID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrQuizSessionNotFound
+	}
+
+	return s.AnswerQuiz(scheduler, userID, session.ID, chosenIdx)
+}
+
+// PollRound возвращает раунд, вопрос которого задан опросом pollID, или nil,
+// если это не опрос теста
+func (s *QuizService) PollRound(pollID string) (*repository.QuizRound, error) {
+	session, err := s.sessions.GetQuizSessionByPoll(pollID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.RoundID == "" {
+		return nil, nil
+	}
+	return s.sessions.GetQuizRound(session.RoundID)
+}
+
+// AbortRound досрочно завершает раунд и возвращает итог по отвеченным вопросам
+func (s *QuizService) AbortRound(userID int64, roundID string) (*RoundSummary, error) {
+	round, err := s.sessions.GetQuizRound(roundID)
+	if err != nil {
+		return nil, err
+	}
+	if round == nil {
+		return nil, ErrQuizSessionNotFound
+	}
+	if round.UserID != userID {
+		return nil, ErrQuizSessionForbidden
+	}
+
+	return s.finishRound(round, true)
+}
+
+// advanceRound создает следующий вопрос раунда или завершает раунд
+func (s *QuizService) advanceRound(round *repository.QuizRound,
+	position int) (*repository.QuizSession, *RoundSummary, error) {
+	if position >= round.Size {
+		summary, err := s.finishRound(round, false)
+		return nil, summary, err
+	}
+
+	sessions, err := s.sessions.GetRoundSessions(round.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	asked := make(map[int]bool, len(sessions))
+	for _, session := range sessions {
+		asked[session.WordID] = true
+	}
+
+	quiz, err := s.wordService.GenerateQuiz(round.UserID, asked, QuizDirection(round.Mode, position), round.TagID)
+	if err != nil {
+		// Слова могли удалить во время раунда — тогда завершаем его раньше
+		log.Printf("Failed to generate next quiz question: %v", err)
+		summary, err := s.finishRound(round, false)
+		return nil, summary, err
+	}
+
+	session, err := s.createSession(round, position, quiz)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return session, nil, nil
+}
+
+// finishRound отмечает раунд завершенным и подводит итог
+func (s *QuizService) finishRound(round *repository.QuizRound, aborted bool) (*RoundSummary, error) {
+	now := s.now()
+	finished, err := s.sessions.FinishQuizRound(round.ID, aborted, now)
+	if err != nil {
+		return nil, err
+	}
+	if !finished {
+		return nil, ErrQuizRoundFinished
+	}
+	round.FinishedAt = now
+	round.Aborted = aborted
+
+	sessions, err := s.sessions.GetRoundSessions(round.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &RoundSummary{Round: round}
+	for _, session := range sessions {
+		if !session.Answered() {
+			continue
+		}
+
+		item := RoundItem{
+			Correct:          session.ChosenIdx == session.CorrectIdx,
+			NextReviewBefore: session.NextReviewBefore,
+			NextReviewAfter:  session.NextReviewAfter,
+		}
+		word, err := s.wordService.GetWord(round.UserID, session.WordID)
+		if err != nil {
+			return nil, err
+		}
+		if word != nil {
+			item.Word, item.Translation = word.Word, word.Translation
+		} else if session.Direction == repository.DirectionReverse {
+			// Слово удалили во время раунда — известен только правильный вариант
+			item.Word = session.Options[session.CorrectIdx]
+		} else {
+			item.Translation = session.Options[session.CorrectIdx]
+		}
+
+		summary.Answered++
+		if item.Correct {
+			summary.Correct++
+		}
+		summary.Items = append(summary.Items, item)
+	}
+
+	return summary, nil
+}
+
+// createSession сохраняет вопрос раунда
+func (s *QuizService) createSession(round *repository.QuizRound, position int,
+	quiz *QuizQuestion) (*repository.QuizSession, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &repository.QuizSession{
+		ID:         id,
+		UserID:     round.UserID,
+		WordID:     quiz.WordID,
+		Question:   quiz.Question,
+		Options:    quiz.Options,
+		CorrectIdx: quiz.CorrectIdx,
+		CreatedAt:  s.now(),
+		ExpiresAt:  round.ExpiresAt,
+		RoundID:    round.ID,
+		Position:   position,
+		Direction:  quiz.Direction,
+	}
+	if err := s.sessions.CreateQuizSession(session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// sessionResult формирует результат по выбранному варианту
+func sessionResult(session *repository.QuizSession, chosenIdx int, alreadyAnswered bool) *QuizResult {
+	result := &QuizResult{
+		Correct:         chosenIdx == session.CorrectIdx,
+		AlreadyAnswered: alreadyAnswered,
+	}
+	if chosenIdx >= 0 && chosenIdx < len(session.Options) {
+		result.ChosenOption = session.Options[chosenIdx]
+	}
+	if session.CorrectIdx >= 0 && session.CorrectIdx < len(session.Options) {
+		result.CorrectOption = session.Options[session.CorrectIdx]
+	}
+	return result
+}
+
+// newSessionID возвращает случайный идентификатор, который нельзя угадать
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
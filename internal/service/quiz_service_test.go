@@ -0,0 +1,254 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// newTestQuizService создает сервис тестов с управляемыми часами и четырьмя словами
+func newTestQuizService(t *testing.T) (*QuizService, *WordService, *time.Time) {
+	t.Helper()
+
+	db := repository.NewMemoryDatabase()
+	userService := NewUserService(repository.NewMemoryUserRepository(db))
+	wordService := NewWordService(repository.NewMemoryWordRepository(db), repository.NewMemoryReviewLogRepository(db))
+	if err := userService.RegisterUser(testUserID, "tester", "Test", ""); err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	addTestWords(t, wordService, "apple", "яблоко", "pear", "груша", "plum", "слива", "lemon", "лимон")
+
+	now := time.Now()
+	quizService := NewQuizService(wordService, repository.NewMemoryQuizSessionRepository(db))
+	quizService.now = func() time.Time { return now }
+
+	return quizService, wordService, &now
+}
+
+func TestQuizService_AnswerQuiz(t *testing.T) {
+	quizService, wordService, now := newTestQuizService(t)
+
+	_, session, err := quizService.StartRound(testUserID, testUserID, 1, repository.DirectionForward, 0)
+	if err != nil {
+		t.Fatalf("Failed to start quiz: %v", err)
+	}
+	if len(session.ID) != 32 {
+		t.Errorf("Expected 32-character session ID, got %q", session.ID)
+	}
+
+	*now = now.Add(1500 * time.Millisecond)
+	result, err := quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, session.ID, session.CorrectIdx)
+	if err != nil {
+		t.Fatalf("Failed to answer quiz: %v", err)
+	}
+	if !result.Correct || result.AlreadyAnswered || result.ChosenOption != session.Options[session.CorrectIdx] {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	history, err := wordService.GetAnswerHistory(testUserID, 10)
+	if err != nil {
+		t.Fatalf("Failed to get answer history: %v", err)
+	}
+	if len(history) != 1 || history[0].LatencyMs != 1500 {
+		t.Errorf("Expected one answer with latency 1500ms, got %+v", history)
+	}
+
+	// Повторный ответ другим вариантом возвращает первый результат
+	again, err := quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, session.ID, (session.CorrectIdx+1)%4)
+	if err != nil {
+		t.Fatalf("Failed to answer quiz again: %v", err)
+	}
+	if !again.Correct || !again.AlreadyAnswered {
+		t.Errorf("Expected the first answer to be returned, got %+v", again)
+	}
+}
+
+// failingReviewLog — журнал ответов, запись в который можно сломать
+type failingReviewLog struct {
+	repository.ReviewLogStore
+	fail bool
+}
+
+func (l *failingReviewLog) SaveQuiz(quiz *repository.Quiz) error {
+	if l.fail {
+		return errors.New("review log is unavailable")
+	}
+	return l.ReviewLogStore.SaveQuiz(quiz)
+}
+
+func TestQuizService_AnswerQuiz_RetriesAfterFailedRecord(t *testing.T) {
+	db := repository.NewMemoryDatabase()
+	reviewLog := &failingReviewLog{ReviewLogStore: repository.NewMemoryReviewLogRepository(db)}
+	wordService := NewWordService(repository.NewMemoryWordRepository(db), reviewLog)
+	userService := NewUserService(repository.NewMemoryUserRepository(db))
+	if err := userService.RegisterUser(testUserID, "tester", "Test", ""); err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	addTestWords(t, wordService, "apple", "яблоко", "pear", "груша", "plum", "слива", "lemon", "лимон")
+	quizService := NewQuizService(wordService, repository.NewMemoryQuizSessionRepository(db))
+
+	_, session, err := quizService.StartRound(testUserID, testUserID, 1, repository.DirectionForward, 0)
+	if err != nil {
+		t.Fatalf("Failed to start quiz: %v", err)
+	}
+
+	reviewLog.fail = true
+	if _, err := quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, session.ID, session.CorrectIdx); err == nil {
+		t.Fatal("Expected the answer to fail while the review log is unavailable")
+	}
+	if word, _ := wordService.GetWord(testUserID, session.WordID); word.Repetitions != 0 {
+		t.Errorf("Expected the schedule to stay unchanged, got %d repetitions", word.Repetitions)
+	}
+
+	// После сбоя ответ можно повторить, и он засчитывается один раз
+	reviewLog.fail = false
+	result, err := quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, session.ID, session.CorrectIdx)
+	if err != nil {
+		t.Fatalf("Failed to retry answer: %v", err)
+	}
+	if result.AlreadyAnswered || !result.Correct {
+		t.Errorf("Expected the retry to be counted as a fresh correct answer, got %+v", result)
+	}
+	if word, _ := wordService.GetWord(testUserID, session.WordID); word.Repetitions != 1 {
+		t.Errorf("Expected one review after retry, got %d repetitions", word.Repetitions)
+	}
+	if history, _ := wordService.GetAnswerHistory(testUserID, 10); len(history) != 1 {
+		t.Errorf("Expected one logged answer, got %d", len(history))
+	}
+}
+
+func TestQuizService_AnswerQuiz_Errors(t *testing.T) {
+	quizService, _, now := newTestQuizService(t)
+
+	_, session, err := quizService.StartRound(testUserID, testUserID, 1, repository.DirectionForward, 0)
+	if err != nil {
+		t.Fatalf("Failed to start quiz: %v", err)
+	}
+
+	_, err = quizService.AnswerQuiz(repository.SchedulerSM2, testUserID+1, session.ID, 0)
+	if !errors.Is(err, ErrQuizSessionForbidden) {
+		t.Errorf("Expected ErrQuizSessionForbidden, got %v", err)
+	}
+	_, err = quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, "unknown", 0)
+	if !errors.Is(err, ErrQuizSessionNotFound) {
+		t.Errorf("Expected ErrQuizSessionNotFound, got %v", err)
+	}
+	if _, err := quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, session.ID, 4); err == nil {
+		t.Error("Expected error for option out of range, got nil")
+	}
+
+	*now = now.Add(quizSessionTTL + time.Second)
+	_, err = quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, session.ID, 0)
+	if !errors.Is(err, ErrQuizSessionExpired) {
+		t.Errorf("Expected ErrQuizSessionExpired, got %v", err)
+	}
+}
+
+func TestQuizService_AnswerPoll(t *testing.T) {
+	quizService, _, _ := newTestQuizService(t)
+
+	round, session, err := quizService.StartRound(testUserID, testUserID, 2, repository.DirectionForward, 0)
+	if err != nil {
+		t.Fatalf("Failed to start quiz: %v", err)
+	}
+	if err := quizService.AttachPoll(session.ID, "poll-1"); err != nil {
+		t.Fatalf("Failed to attach poll: %v", err)
+	}
+
+	_, err = quizService.AnswerPoll(repository.SchedulerSM2, testUserID, "unknown", 0)
+	if !errors.Is(err, ErrQuizSessionNotFound) {
+		t.Errorf("Expected ErrQuizSessionNotFound, got %v", err)
+	}
+
+	result, err := quizService.AnswerPoll(repository.SchedulerSM2, testUserID, "poll-1", session.CorrectIdx)
+	if err != nil {
+		t.Fatalf("Failed to answer poll: %v", err)
+	}
+	if !result.Correct || result.Round == nil || result.Round.ID != round.ID || result.Next == nil {
+		t.Errorf("Expected a correct answer with the next question, got %+v", result)
+	}
+}
+
+func TestQuizService_Round(t *testing.T) {
+	quizService, _, _ := newTestQuizService(t)
+
+	round, session, err := quizService.StartRound(testUserID, testUserID, 10, repository.DirectionForward, 0)
+	if err != nil {
+		t.Fatalf("Failed to start round: %v", err)
+	}
+	if round.Size != 4 {
+		t.Errorf("Expected round size to be limited by 4 words, got %d", round.Size)
+	}
+
+	asked := map[int]bool{}
+	for i := 0; i < round.Size; i++ {
+		if asked[session.WordID] {
+			t.Fatalf("Word %d was asked twice in one round", session.WordID)
+		}
+		asked[session.WordID] = true
+
+		// Ошибаемся только в первом вопросе
+		chosen := session.CorrectIdx
+		if i == 0 {
+			chosen = (session.CorrectIdx + 1) % 4
+		}
+		result, err := quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, session.ID, chosen)
+		if err != nil {
+			t.Fatalf("Failed to answer question %d: %v", i+1, err)
+		}
+
+		if i < round.Size-1 {
+			if result.Next == nil || result.Next.Position != i+1 || result.Summary != nil {
+				t.Fatalf("Expected question %d to follow, got %+v", i+2, result)
+			}
+			session = result.Next
+			continue
+		}
+
+		summary := result.Summary
+		if result.Next != nil || summary == nil {
+			t.Fatalf("Expected summary after the last question, got %+v", result)
+		}
+		if summary.Answered != 4 || summary.Correct != 3 || len(summary.Missed()) != 1 {
+			t.Errorf("Expected 3 of 4 correct with one miss, got %+v", summary)
+		}
+		if item := summary.Items[0]; item.Word == "" || !item.NextReviewAfter.After(item.NextReviewBefore) {
+			t.Errorf("Expected review dates to be recorded, got %+v", item)
+		}
+	}
+}
+
+func TestQuizService_AbortRound(t *testing.T) {
+	quizService, _, _ := newTestQuizService(t)
+
+	round, session, err := quizService.StartRound(testUserID, testUserID, 3, repository.DirectionForward, 0)
+	if err != nil {
+		t.Fatalf("Failed to start round: %v", err)
+	}
+	result, err := quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, session.ID, session.CorrectIdx)
+	if err != nil {
+		t.Fatalf("Failed to answer quiz: %v", err)
+	}
+
+	if _, err := quizService.AbortRound(testUserID+1, round.ID); !errors.Is(err, ErrQuizSessionForbidden) {
+		t.Errorf("Expected ErrQuizSessionForbidden, got %v", err)
+	}
+
+	summary, err := quizService.AbortRound(testUserID, round.ID)
+	if err != nil {
+		t.Fatalf("Failed to abort round: %v", err)
+	}
+	if !summary.Round.Aborted || summary.Answered != 1 || summary.Correct != 1 {
+		t.Errorf("Expected aborted round with one correct answer, got %+v", summary)
+	}
+
+	if _, err := quizService.AbortRound(testUserID, round.ID); !errors.Is(err, ErrQuizRoundFinished) {
+		t.Errorf("Expected ErrQuizRoundFinished on second abort, got %v", err)
+	}
+	_, err = quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, result.Next.ID, 0)
+	if !errors.Is(err, ErrQuizRoundFinished) {
+		t.Errorf("Expected ErrQuizRoundFinished for a question of an aborted round, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// DefaultNewWordRatio — доля вопросов теста по новым словам, если она не настроена
+const DefaultNewWordRatio = 0.2
+
+// maxOverdueDays ограничивает вклад просрочки в вес слова, чтобы давно
+// заброшенные слова не вытесняли остальные просроченные
+const maxOverdueDays = 30
+
+// SetNewWordRatio задает долю вопросов теста по новым словам (от 0 до 1)
+func (s *WordService) SetNewWordRatio(ratio float64) error {
+	if math.IsNaN(ratio) || ratio < 0 || ratio > 1 {
+		return fmt.Errorf("new word ratio must be between 0 and 1, got %v", ratio)
+	}
+	s.newWordRatio = ratio
+	return nil
+}
+
+// pickQuizTarget выбирает слово, которое загадывается в тесте. С вероятностью
+// newRatio берется новое слово, иначе — повторенное раньше: сначала просроченные,
+// а если их нет, то остальные с перевесом в сторону сложных. Если одной из групп
+// нет, слово берется из другой.
+func pickQuizTarget(r *rand.Rand, words []*repository.Word, newRatio float64, now time.Time) *repository.Word {
+	var fresh, overdue, scheduled []*repository.Word
+	for _, word := range words {
+		switch {
+		case word.IsNew():
+			fresh = append(fresh, word)
+		case !word.NextReview.After(now):
+			overdue = append(overdue, word)
+		default:
+			scheduled = append(scheduled, word)
+		}
+	}
+
+	reviewed := len(overdue) + len(scheduled)
+	if len(fresh) > 0 && (reviewed == 0 || r.Float64() < newRatio) {
+		return fresh[r.Intn(len(fresh))]
+	}
+	if len(overdue) > 0 {
+		return pickWeighted(r, overdue, func(word *repository.Word) float64 {
+			days := math.Min(now.Sub(word.NextReview).Hours()/24, maxOverdueDays)
+			return 1 + days + float64(word.Difficulty)
+		})
+	}
+	if len(scheduled) > 0 {
+		return pickWeighted(r, scheduled, func(word *repository.Word) float64 {
+			return 1 + float64(word.Difficulty*word.Difficulty)
+		})
+	}
+	return nil
+}
+
+// pickWeighted выбирает слово с вероятностью, пропорциональной его весу
+func pickWeighted(r *rand.Rand, words []*repository.Word, weight func(*repository.Word) float64) *repository.Word {
+	weights := make([]float64, len(words))
+	total := 0.0
+	for i, word := range words {
+		weights[i] = weight(word)
+		total += weights[i]
+	}
+
+	point := r.Float64() * total
+	for i, w := range weights {
+		if point < w {
+			return words[i]
+		}
+		point -= w
+	}
+	return words[len(words)-1]
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// targetWord создает слово для проверки выбора: новое, если lastReview нулевое
+func targetWord(id int, lastReview, nextReview time.Time, difficulty int) *repository.Word {
+	created := testNow.AddDate(0, -1, 0)
+	if lastReview.IsZero() {
+		lastReview = created
+	}
+	return &repository.Word{
+		ID:        id,
+		CreatedAt: created,
+		ReviewState: repository.ReviewState{
+			LastReview: lastReview,
+			NextReview: nextReview,
+			Difficulty: difficulty,
+		},
+	}
+}
+
+// pickCounts возвращает, сколько раз было выбрано каждое слово за n попыток
+func pickCounts(words []*repository.Word, newRatio float64, n int) map[int]int {
+	r := rand.New(rand.NewSource(1))
+	counts := make(map[int]int)
+	for i := 0; i < n; i++ {
+		counts[pickQuizTarget(r, words, newRatio, testNow).ID]++
+	}
+	return counts
+}
+
+func TestPickQuizTarget(t *testing.T) {
+	reviewed := testNow.AddDate(0, 0, -5)
+	fresh := targetWord(1, time.Time{}, testNow.AddDate(0, 0, 1), 0)
+	overdue := targetWord(2, reviewed, testNow.AddDate(0, 0, -2), 0)
+	easy := targetWord(3, reviewed, testNow.AddDate(0, 0, 3), 0)
+	hard := targetWord(4, reviewed, testNow.AddDate(0, 0, 3), 5)
+
+	t.Run("overdue words come first", func(t *testing.T) {
+		counts := pickCounts([]*repository.Word{overdue, easy, hard}, 0, 100)
+		if counts[2] != 100 {
+			t.Errorf("Expected only the overdue word to be picked, got %v", counts)
+		}
+	})
+
+	t.Run("hard words are preferred when nothing is due", func(t *testing.T) {
+		counts := pickCounts([]*repository.Word{easy, hard}, 0, 1000)
+		if counts[4] < 900 {
+			t.Errorf("Expected the hard word to be picked most of the time, got %v", counts)
+		}
+	})
+
+	t.Run("new words are mixed in at the ratio", func(t *testing.T) {
+		counts := pickCounts([]*repository.Word{fresh, overdue}, 0.3, 1000)
+		if counts[1] < 250 || counts[1] > 350 {
+			t.Errorf("Expected about 30%% new words, got %v", counts)
+		}
+
+		if counts := pickCounts([]*repository.Word{fresh, overdue}, 0, 100); counts[1] != 0 {
+			t.Errorf("Expected no new words with ratio 0, got %v", counts)
+		}
+	})
+
+	t.Run("new words are used when nothing else is left", func(t *testing.T) {
+		counts := pickCounts([]*repository.Word{fresh}, 0, 10)
+		if counts[1] != 10 {
+			t.Errorf("Expected the only word to be picked, got %v", counts)
+		}
+	})
+}
+
+func TestWordService_SetNewWordRatio(t *testing.T) {
+	_, wordService := newTestServices(t)
+
+	for _, ratio := range []float64{-0.1, 1.5} {
+		if err := wordService.SetNewWordRatio(ratio); err == nil {
+			t.Errorf("Expected error for ratio %v", ratio)
+		}
+	}
+	if err := wordService.SetNewWordRatio(0.5); err != nil || wordService.newWordRatio != 0.5 {
+		t.Errorf("Expected ratio 0.5 to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// Оценки качества ответа по шкале SM-2 (0–5)
+const (
+	QualityBlackout  = 0 // Не вспомнил совсем
+	QualityWrong     = 1 // Ошибся, но узнал правильный ответ
+	QualityHardWrong = 2 // Ошибся, хотя ответ казался знакомым
+	QualityHard      = 3 // Вспомнил с большим трудом
+	QualityGood      = 4 // Вспомнил после раздумий
+	QualityPerfect   = 5 // Вспомнил сразу
+)
+
+// Оценки ответа для FSRS
+const (
+	RatingAgain = 1 // Забыл
+	RatingHard  = 2 // Вспомнил с трудом
+	RatingGood  = 3 // Вспомнил
+	RatingEasy  = 4 // Вспомнил легко
+)
+
+// ReviewResult описывает результат одного повторения слова.
+// Оценка хранится в обеих шкалах, чтобы каждый алгоритм читал свою.
+type ReviewResult struct {
+	Quality int // Оценка по шкале SM-2 (0–5)
+	Rating  int // Оценка FSRS Again/Hard/Good/Easy (1–4)
+}
+
+// ResultFromQuality создает результат по оценке SM-2
+func ResultFromQuality(quality int) ReviewResult {
+	rating := RatingAgain
+	switch {
+	case quality >= QualityPerfect:
+		rating = RatingEasy
+	case quality == QualityGood:
+		rating = RatingGood
+	case quality == QualityHard:
+		rating = RatingHard
+	}
+	return ReviewResult{Quality: quality, Rating: rating}
+}
+
+// ResultFromRating создает результат по оценке FSRS
+func ResultFromRating(rating int) ReviewResult {
+	quality := QualityWrong
+	switch rating {
+	case RatingHard:
+		quality = QualityHard
+	case RatingGood:
+		quality = QualityGood
+	case RatingEasy:
+		quality = QualityPerfect
+	}
+	return ReviewResult{Quality: quality, Rating: rating}
+}
+
+// ResultFromAnswer переводит результат теста с вариантами ответа в оценку
+func ResultFromAnswer(correct bool) ReviewResult {
+	if correct {
+		return ResultFromQuality(QualityGood)
+	}
+	return ResultFromQuality(QualityWrong)
+}
+
+// Validate проверяет, что оценки находятся в допустимых диапазонах
+func (r ReviewResult) Validate() error {
+	if r.Quality < QualityBlackout || r.Quality > QualityPerfect {
+		return fmt.Errorf("quality must be between %d and %d", QualityBlackout, QualityPerfect)
+	}
+	if r.Rating < RatingAgain || r.Rating > RatingEasy {
+		return fmt.Errorf("rating must be between %d and %d", RatingAgain, RatingEasy)
+	}
+	return nil
+}
+
+// Scheduler — алгоритм интервального повторения
+type Scheduler interface {
+	// Schedule возвращает новое состояние слова и время следующего повторения
+	Schedule(state repository.ReviewState, result ReviewResult, now time.Time) (repository.ReviewState, time.Time)
+	// Convert переносит состояние, накопленное другим алгоритмом, в параметры этого.
+	// Интервал и дата следующего повторения общие для всех алгоритмов и не меняются.
+	Convert(state repository.ReviewState, now time.Time) repository.ReviewState
+}
+
+// schedulers содержит доступные алгоритмы по имени, которое хранится в users.scheduler
+var schedulers = map[string]Scheduler{
+	repository.SchedulerSM2:  SM2Scheduler{},
+	repository.SchedulerFSRS: NewFSRSScheduler(),
+}
+
+// SchedulerByName возвращает алгоритм по имени; для неизвестного имени — SM-2
+func SchedulerByName(name string) Scheduler {
+	if scheduler, ok := schedulers[name]; ok {
+		return scheduler
+	}
+	return schedulers[repository.SchedulerSM2]
+}
+
+// IsKnownScheduler сообщает, есть ли алгоритм с таким именем
+func IsKnownScheduler(name string) bool {
+	_, ok := schedulers[name]
+	return ok
+}
+
+// updateDifficultyCounter ведет общий для всех алгоритмов счетчик сложности 0–5:
+// он растет при ошибках и падает при уверенных ответах
+func updateDifficultyCounter(state *repository.ReviewState, result ReviewResult) {
+	if result.Quality < QualityHard && state.Difficulty < 5 {
+		state.Difficulty++
+	} else if result.Quality >= QualityGood && state.Difficulty > 0 {
+		state.Difficulty--
+	}
+}
+
+func clamp(value, low, high float64) float64 {
+	return math.Max(low, math.Min(high, value))
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// defaultFSRSWeights — параметры FSRS-4.5 по умолчанию
+var defaultFSRSWeights = [17]float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031,
+	1.6474, 0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+const (
+	// fsrsDecay и fsrsFactor задают кривую забывания FSRS-4.5
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+	// fsrsMaxInterval ограничивает интервал сотней лет
+	fsrsMaxInterval = 36500
+)
+
+// FSRSScheduler реализует алгоритм FSRS (Free Spaced Repetition Scheduler).
+// Бот планирует повторения в днях, поэтому краткосрочные шаги обучения не используются,
+// а интервал при целевой удерживаемости 90% равен стабильности.
+type FSRSScheduler struct {
+	w [17]float64
+}
+
+// NewFSRSScheduler создает FSRS с параметрами по умолчанию
+func NewFSRSScheduler() *FSRSScheduler {
+	return &FSRSScheduler{w: defaultFSRSWeights}
+}
+
+// Schedule пересчитывает состояние по оценке Again/Hard/Good/Easy
+func (f *FSRSScheduler) Schedule(state repository.ReviewState, result ReviewResult,
+	now time.Time) (repository.ReviewState, time.Time) {
+	rating := result.Rating
+
+	if state.Stability <= 0 {
+		// Первое повторение по FSRS
+		state.Stability = f.w[rating-1]
+		state.FSRSDifficulty = f.initDifficulty(rating)
+		state.Retrievability = 0
+	} else {
+		elapsed := math.Max(now.Sub(state.LastReview).Hours()/24, 0)
+		r := fsrsRetrievability(elapsed, state.Stability)
+		d := state.FSRSDifficulty
+
+		if rating == RatingAgain {
+			state.Stability = f.forgetStability(d, state.Stability, r)
+		} else {
+			state.Stability = f.recallStability(d, state.Stability, r, rating)
+		}
+		state.FSRSDifficulty = f.nextDifficulty(d, rating)
+		state.Retrievability = r
+	}
+
+	// Общие счетчики ведем так же, как в SM-2, чтобы алгоритмы можно было переключать
+	if rating == RatingAgain {
+		if state.Repetitions > 0 {
+			state.Lapses++
+		}
+		state.Repetitions = 0
+	} else {
+		state.Repetitions++
+	}
+	updateDifficultyCounter(&state, result)
+
+	interval := int(math.Round(state.Stability))
+	if rating == RatingAgain || interval < 1 {
+		interval = 1
+	}
+	if interval > fsrsMaxInterval {
+		interval = fsrsMaxInterval
+	}
+
+	state.Interval = interval
+	state.LastReview = now
+	state.NextReview = now.AddDate(0, 0, interval)
+
+	return state, state.NextReview
+}
+
+// Convert оценивает стабильность и сложность FSRS по состоянию SM-2
+func (f *FSRSScheduler) Convert(state repository.ReviewState, now time.Time) repository.ReviewState {
+	if state.Repetitions == 0 && state.Interval <= 1 {
+		// Слово еще не выучено — FSRS начнет с первого повторения
+		state.Stability = 0
+		state.FSRSDifficulty = 0
+		state.Retrievability = 0
+		return state
+	}
+
+	// При удерживаемости 90% интервал FSRS равен стабильности
+	state.Stability = float64(state.Interval)
+	// Коэффициент легкости 2.5 соответствует средней сложности 5, минимальный 1.3 — максимальной 10
+	state.FSRSDifficulty = clamp(5+(2.5-state.EaseFactor)*25/6, 1, 10)
+	elapsed := math.Max(now.Sub(state.LastReview).Hours()/24, 0)
+	state.Retrievability = fsrsRetrievability(elapsed, state.Stability)
+
+	return state
+}
+
+// fsrsRetrievability возвращает вероятность вспомнить слово спустя elapsed дней
+func fsrsRetrievability(elapsed, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsed/stability, fsrsDecay)
+}
+
+func (f *FSRSScheduler) initDifficulty(rating int) float64 {
+	return clamp(f.w[4]-float64(rating-3)*f.w[5], 1, 10)
+}
+
+func (f *FSRSScheduler) nextDifficulty(d float64, rating int) float64 {
+	next := d - f.w[6]*float64(rating-3)
+	// Возврат к среднему не дает сложности застрять на краях шкалы
+	next = f.w[7]*f.initDifficulty(RatingGood) + (1-f.w[7])*next
+	return clamp(next, 1, 10)
+}
+
+func (f *FSRSScheduler) recallStability(d, s, r float64, rating int) float64 {
+	hardPenalty := 1.0
+	if rating == RatingHard {
+		hardPenalty = f.w[15]
+	}
+	easyBonus := 1.0
+	if rating == RatingEasy {
+		easyBonus = f.w[16]
+	}
+
+	return s * (1 + math.Exp(f.w[8])*
+		(11-d)*
+		math.Pow(s, -f.w[9])*
+		(math.Exp(f.w[10]*(1-r))-1)*
+		hardPenalty*
+		easyBonus)
+}
+
+func (f *FSRSScheduler) forgetStability(d, s, r float64) float64 {
+	next := f.w[11] *
+		math.Pow(d, -f.w[12]) *
+		(math.Pow(s+1, f.w[13]) - 1) *
+		math.Exp(f.w[14]*(1-r))
+	return math.Min(next, s)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// Минимальный коэффициент легкости в SM-2
+const minEaseFactor = 1.3
+
+// SM2Scheduler реализует классический алгоритм SM-2
+type SM2Scheduler struct{}
+
+// Schedule пересчитывает состояние по оценке качества ответа (0–5)
+func (SM2Scheduler) Schedule(state repository.ReviewState, result ReviewResult,
+	now time.Time) (repository.ReviewState, time.Time) {
+	quality := result.Quality
+
+	if quality >= QualityHard {
+		switch state.Repetitions {
+		case 0:
+			state.Interval = 1
+		case 1:
+			state.Interval = 6
+		default:
+			state.Interval = int(math.Round(float64(state.Interval) * state.EaseFactor))
+		}
+		state.Repetitions++
+	} else {
+		// Забытое слово начинает цикл повторений заново
+		if state.Repetitions > 0 {
+			state.Lapses++
+		}
+		state.Repetitions = 0
+		state.Interval = 1
+	}
+
+	q := float64(QualityPerfect - quality)
+	state.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if state.EaseFactor < minEaseFactor {
+		state.EaseFactor = minEaseFactor
+	}
+
+	updateDifficultyCounter(&state, result)
+
+	state.LastReview = now
+	state.NextReview = now.AddDate(0, 0, state.Interval)
+
+	return state, state.NextReview
+}
+
+// Convert восстанавливает коэффициент легкости из сложности FSRS
+func (SM2Scheduler) Convert(state repository.ReviewState, _ time.Time) repository.ReviewState {
+	if state.Stability <= 0 {
+		// FSRS не использовался — состояние SM-2 актуально
+		return state
+	}
+
+	state.EaseFactor = math.Max(minEaseFactor, 2.5-(state.FSRSDifficulty-5)*6/25)
+	if state.Interval > 1 && state.Repetitions < 2 {
+		// Для SM-2 интервал растет умножением только после двух успешных повторений
+		state.Repetitions = 2
+	}
+
+	return state
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+var testNow = time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+// approx сравнивает дробные параметры алгоритмов с точностью до четырех знаков
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
+
+func TestSchedulers_Schedule(t *testing.T) {
+	newCard := repository.ReviewState{Interval: 1, EaseFactor: 2.5}
+	learning := repository.ReviewState{
+		LastReview: testNow.AddDate(0, 0, -1), Interval: 1, EaseFactor: 2.5, Repetitions: 1,
+	}
+	review := repository.ReviewState{
+		LastReview: testNow.AddDate(0, 0, -6), Interval: 6, EaseFactor: 2.5, Repetitions: 2, Difficulty: 2,
+	}
+	fsrsReview := repository.ReviewState{
+		LastReview: testNow.AddDate(0, 0, -4), Interval: 4, EaseFactor: 2.5, Repetitions: 1,
+		Stability: 3.7145, FSRSDifficulty: 5.1618,
+	}
+
+	tests := []struct {
+		name      string
+		scheduler Scheduler
+		state     repository.ReviewState
+		result    ReviewResult
+
+		interval       int
+		repetitions    int
+		lapses         int
+		difficulty     int
+		easeFactor     float64
+		stability      float64
+		fsrsDifficulty float64
+	}{
+		{"SM-2 new word good", SM2Scheduler{}, newCard, ResultFromQuality(QualityGood),
+			1, 1, 0, 0, 2.5, 0, 0},
+		{"SM-2 second review good", SM2Scheduler{}, learning, ResultFromQuality(QualityGood),
+			6, 2, 0, 0, 2.5, 0, 0},
+		{"SM-2 third review perfect", SM2Scheduler{}, review, ResultFromQuality(QualityPerfect),
+			15, 3, 0, 1, 2.6, 0, 0},
+		{"SM-2 third review hard", SM2Scheduler{}, review, ResultFromQuality(QualityHard),
+			15, 3, 0, 2, 2.36, 0, 0},
+		{"SM-2 lapse", SM2Scheduler{}, review, ResultFromQuality(QualityWrong),
+			1, 0, 1, 3, 1.96, 0, 0},
+		{"SM-2 ease factor floor", SM2Scheduler{},
+			repository.ReviewState{Interval: 1, EaseFactor: 1.4}, ResultFromQuality(QualityBlackout),
+			1, 0, 0, 1, 1.3, 0, 0},
+
+		{"FSRS new word again", NewFSRSScheduler(), newCard, ResultFromRating(RatingAgain),
+			1, 0, 0, 1, 2.5, 0.4872, 7.6214},
+		{"FSRS new word hard", NewFSRSScheduler(), newCard, ResultFromRating(RatingHard),
+			1, 1, 0, 0, 2.5, 1.4003, 6.3916},
+		{"FSRS new word good", NewFSRSScheduler(), newCard, ResultFromRating(RatingGood),
+			4, 1, 0, 0, 2.5, 3.7145, 5.1618},
+		{"FSRS new word easy", NewFSRSScheduler(), newCard, ResultFromRating(RatingEasy),
+			14, 1, 0, 0, 2.5, 13.8206, 3.9320},
+		{"FSRS review again", NewFSRSScheduler(), fsrsReview, ResultFromRating(RatingAgain),
+			1, 0, 1, 1, 2.5, 1.4332, 6.9012},
+		{"FSRS review hard", NewFSRSScheduler(), fsrsReview, ResultFromRating(RatingHard),
+			6, 2, 0, 0, 2.5, 6.2350, 6.0315},
+		{"FSRS review good", NewFSRSScheduler(), fsrsReview, ResultFromRating(RatingGood),
+			15, 2, 0, 0, 2.5, 14.8081, 5.1618},
+		{"FSRS review easy", NewFSRSScheduler(), fsrsReview, ResultFromRating(RatingEasy),
+			36, 2, 0, 0, 2.5, 35.6141, 4.2921},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, next := tt.scheduler.Schedule(tt.state, tt.result, testNow)
+
+			if got.Interval != tt.interval {
+				t.Errorf("Expected interval %d, got %d", tt.interval, got.Interval)
+			}
+			if got.Repetitions != tt.repetitions {
+				t.Errorf("Expected repetitions %d, got %d", tt.repetitions, got.Repetitions)
+			}
+			if got.Lapses != tt.lapses {
+				t.Errorf("Expected lapses %d, got %d", tt.lapses, got.Lapses)
+			}
+			if got.Difficulty != tt.difficulty {
+				t.Errorf("Expected difficulty %d, got %d", tt.difficulty, got.Difficulty)
+			}
+			if !approx(got.EaseFactor, tt.easeFactor) {
+				t.Errorf("Expected ease factor %.4f, got %.4f", tt.easeFactor, got.EaseFactor)
+			}
+			if !approx(got.Stability, tt.stability) {
+				t.Errorf("Expected stability %.4f, got %.4f", tt.stability, got.Stability)
+			}
+			if !approx(got.FSRSDifficulty, tt.fsrsDifficulty) {
+				t.Errorf("Expected FSRS difficulty %.4f, got %.4f", tt.fsrsDifficulty, got.FSRSDifficulty)
+			}
+
+			wantNext := testNow.AddDate(0, 0, tt.interval)
+			if !next.Equal(wantNext) || !got.NextReview.Equal(wantNext) {
+				t.Errorf("Expected next review %v, got %v (state %v)", wantNext, next, got.NextReview)
+			}
+			if !got.LastReview.Equal(testNow) {
+				t.Errorf("Expected last review %v, got %v", testNow, got.LastReview)
+			}
+		})
+	}
+}
+
+func TestSchedulers_Convert(t *testing.T) {
+	next := testNow.AddDate(0, 0, 10)
+
+	tests := []struct {
+		name      string
+		scheduler Scheduler
+		state     repository.ReviewState
+
+		repetitions    int
+		easeFactor     float64
+		stability      float64
+		fsrsDifficulty float64
+		retrievability float64
+	}{
+		{"to FSRS learned word", NewFSRSScheduler(),
+			repository.ReviewState{LastReview: testNow, NextReview: next, Interval: 10, EaseFactor: 2.5, Repetitions: 3},
+			3, 2.5, 10, 5, 1},
+		{"to FSRS hard word", NewFSRSScheduler(),
+			repository.ReviewState{LastReview: testNow, NextReview: next, Interval: 10, EaseFactor: 1.3, Repetitions: 3},
+			3, 1.3, 10, 10, 1},
+		{"to FSRS overdue word", NewFSRSScheduler(),
+			repository.ReviewState{LastReview: testNow.AddDate(0, 0, -10), NextReview: next, Interval: 10,
+				EaseFactor: 2.5, Repetitions: 3},
+			3, 2.5, 10, 5, 0.9},
+		{"to FSRS word after lapse", NewFSRSScheduler(),
+			repository.ReviewState{LastReview: testNow, NextReview: next, Interval: 10, EaseFactor: 2.5, Stability: 7},
+			0, 2.5, 10, 5, 1},
+		{"to FSRS unlearned word", NewFSRSScheduler(),
+			repository.ReviewState{NextReview: next, Interval: 1, EaseFactor: 2.5, Stability: 7, FSRSDifficulty: 3},
+			0, 2.5, 0, 0, 0},
+		{"to SM-2 learned word", SM2Scheduler{},
+			repository.ReviewState{NextReview: next, Interval: 10, EaseFactor: 2.5, Repetitions: 1,
+				Stability: 10, FSRSDifficulty: 7.5},
+			2, 1.9, 10, 7.5, 0},
+		{"to SM-2 ease factor floor", SM2Scheduler{},
+			repository.ReviewState{NextReview: next, Interval: 10, EaseFactor: 2.5, Repetitions: 4,
+				Stability: 10, FSRSDifficulty: 10},
+			4, 1.3, 10, 10, 0},
+		{"to SM-2 without FSRS state", SM2Scheduler{},
+			repository.ReviewState{NextReview: next, Interval: 10, EaseFactor: 2.2, Repetitions: 1},
+			1, 2.2, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.scheduler.Convert(tt.state, testNow)
+
+			if got.Interval != tt.state.Interval || !got.NextReview.Equal(tt.state.NextReview) {
+				t.Errorf("Expected schedule to be kept, got interval %d next %v", got.Interval, got.NextReview)
+			}
+			if got.Repetitions != tt.repetitions {
+				t.Errorf("Expected repetitions %d, got %d", tt.repetitions, got.Repetitions)
+			}
+			if !approx(got.EaseFactor, tt.easeFactor) {
+				t.Errorf("Expected ease factor %.4f, got %.4f", tt.easeFactor, got.EaseFactor)
+			}
+			if !approx(got.Stability, tt.stability) {
+				t.Errorf("Expected stability %.4f, got %.4f", tt.stability, got.Stability)
+			}
+			if !approx(got.FSRSDifficulty, tt.fsrsDifficulty) {
+				t.Errorf("Expected FSRS difficulty %.4f, got %.4f", tt.fsrsDifficulty, got.FSRSDifficulty)
+			}
+			if !approx(got.Retrievability, tt.retrievability) {
+				t.Errorf("Expected retrievability %.4f, got %.4f", tt.retrievability, got.Retrievability)
+			}
+		})
+	}
+}
+
+func TestReviewResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  ReviewResult
+		want    ReviewResult
+		wantErr bool
+	}{
+		{"correct answer", ResultFromAnswer(true), ReviewResult{QualityGood, RatingGood}, false},
+		{"wrong answer", ResultFromAnswer(false), ReviewResult{QualityWrong, RatingAgain}, false},
+		{"perfect quality", ResultFromQuality(QualityPerfect), ReviewResult{QualityPerfect, RatingEasy}, false},
+		{"hard quality", ResultFromQuality(QualityHard), ReviewResult{QualityHard, RatingHard}, false},
+		{"blackout quality", ResultFromQuality(QualityBlackout), ReviewResult{QualityBlackout, RatingAgain}, false},
+		{"easy rating", ResultFromRating(RatingEasy), ReviewResult{QualityPerfect, RatingEasy}, false},
+		{"quality out of range", ResultFromQuality(6), ReviewResult{6, RatingEasy}, true},
+		{"rating out of range", ResultFromRating(5), ReviewResult{QualityWrong, 5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.result != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, tt.result)
+			}
+			if err := tt.result.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSchedulerByName(t *testing.T) {
+	if _, ok := SchedulerByName(repository.SchedulerFSRS).(*FSRSScheduler); !ok {
+		t.Error("Expected FSRS scheduler for \"fsrs\"")
+	}
+	if _, ok := SchedulerByName("unknown").(SM2Scheduler); !ok {
+		t.Error("Expected SM-2 fallback for unknown scheduler")
+	}
+	if IsKnownScheduler("unknown") {
+		t.Error("Expected unknown scheduler to be rejected")
+	}
+}
@@ -11,11 +11,11 @@ import (
 
 type SchedulerService struct {
 	bot         *bot.Bot
-	userRepo    *repository.UserRepository
+	userRepo    repository.UserStore
 	wordService *WordService
 }
 
-func NewSchedulerService(bot *bot.Bot, userRepo *repository.UserRepository, wordService *WordService) *SchedulerService {
+func NewSchedulerService(bot *bot.Bot, userRepo repository.UserStore, wordService *WordService) *SchedulerService {
 	return &SchedulerService{
 		bot:         bot,
 		userRepo:    userRepo,
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// MaxTagLength — наибольшая длина имени тега в символах
+const MaxTagLength = 32
+
+// Ошибки работы с тегами
+var (
+	ErrInvalidTag  = errors.New("tag must start with a letter and contain only letters, digits, _ and -")
+	ErrNoTags      = errors.New("no tags given")
+	ErrTagNotFound = errors.New("tag not found")
+)
+
+// NormalizeTag приводит тег к виду, в котором он хранится: без «#» в начале
+// и в нижнем регистре. Тег начинается с буквы и состоит из букв, цифр, «_» и «-»,
+// поэтому «#Travel» и «travel» — один тег, а «irregular verbs» пишется как
+// «irregular_verbs».
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+		return "", ErrInvalidTag
+	}
+
+	for i, r := range tag {
+		if i == 0 && !unicode.IsLetter(r) {
+			return "", ErrInvalidTag
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return "", ErrInvalidTag
+		}
+	}
+	return tag, nil
+}
+
+// ExtractTags вынимает из строки теги вида #tag и возвращает строку без них
+// и нормализованные теги без повторов. Слова с «#», которые не являются тегом
+// (например, «#1»), остаются в строке.
+func ExtractTags(text string) (string, []string) {
+	var rest, tags []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Fields(text) {
+		if !strings.HasPrefix(field, "#") {
+			rest = append(rest, field)
+			continue
+		}
+		tag, err := NormalizeTag(field)
+		if err != nil {
+			rest = append(rest, field)
+			continue
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return strings.Join(rest, " "), tags
+}
+
+// normalizeTags нормализует теги, введенные пользователем, и убирает повторы
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, ErrNoTags
+	}
+
+	var normalized []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		name, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
+
+// AddTags добавляет слову пользователя теги и возвращает все теги слова
+func (s *WordService) AddTags(userID int64, wordID int, tags []string) ([]string, error) {
+	names, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWord(userID, wordID); err != nil {
+		return nil, err
+	}
+
+	if err := s.wordRepo.AddWordTags(userID, wordID, names); err != nil {
+		return nil, err
+	}
+	return s.wordTags(userID, wordID)
+}
+
+// RemoveTags снимает со слова пользователя теги и возвращает оставшиеся теги слова
+func (s *WordService) RemoveTags(userID int64, wordID int, tags []string) ([]string, error) {
+	names, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWord(userID, wordID); err != nil {
+		return nil, err
+	}
+
+	if err := s.wordRepo.RemoveWordTags(userID, wordID, names); err != nil {
+		return nil, err
+	}
+	return s.wordTags(userID, wordID)
+}
+
+// GetTags возвращает теги пользователя, у которых есть слова, с числом слов
+func (s *WordService) GetTags(userID int64) ([]*repository.Tag, error) {
+	return s.wordRepo.GetUserTags(userID)
+}
+
+// FindTag возвращает тег пользователя по имени с «#» или без него.
+// Если такого тега нет или у него не осталось слов, возвращается ErrTagNotFound.
+func (s *WordService) FindTag(userID int64, name string) (*repository.Tag, error) {
+	normalized, err := NormalizeTag(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.wordRepo.GetTagByName(userID, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil || tag.Words == 0 {
+		return nil, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+// GetTag возвращает тег пользователя по ID; для чужого или неизвестного тега
+// возвращает ErrTagNotFound
+func (s *WordService) GetTag(userID int64, tagID int) (*repository.Tag, error) {
+	tag, err := s.wordRepo.GetTag(tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil || tag.UserID != userID {
+		return nil, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+// LoadTags заполняет теги слов пользователя
+func (s *WordService) LoadTags(userID int64, words []*repository.Word) error {
+	tags, err := s.wordRepo.GetWordTags(userID)
+	if err != nil {
+		return err
+	}
+	for _, word := range words {
+		word.Tags = tags[word.ID]
+	}
+	return nil
+}
+
+// checkWord возвращает ErrWordNotFound, если у пользователя нет слова wordID
+func (s *WordService) checkWord(userID int64, wordID int) error {
+	word, err := s.GetWord(userID, wordID)
+	if err != nil {
+		return err
+	}
+	if word == nil {
+		return ErrWordNotFound
+	}
+	return nil
+}
+
+// wordTags возвращает теги одного слова пользователя
+func (s *WordService) wordTags(userID int64, wordID int) ([]string, error) {
+	tags, err := s.wordRepo.GetWordTags(userID)
+	if err != nil {
+		return nil, err
+	}
+	return tags[wordID], nil
+}
+
+// taggedWords возвращает ID слов пользователя с тегом tagID
+func (s *WordService) taggedWords(userID int64, tagID int) (map[int]bool, error) {
+	words, err := s.wordRepo.ListWords(repository.WordListQuery{
+		UserID: userID, Sort: repository.WordSortRecent, TagID: tagID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[int]bool, len(words))
+	for _, word := range words {
+		ids[word.ID] = true
+	}
+	return ids, nil
+}
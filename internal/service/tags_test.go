@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	valid := map[string]string{
+		"#Travel":         "travel",
+		"irregular_verbs": "irregular_verbs",
+		" #Путешествия ":  "путешествия",
+		"#phrasal-verbs2": "phrasal-verbs2",
+	}
+	for input, want := range valid {
+		if got, err := NormalizeTag(input); err != nil || got != want {
+			t.Errorf("NormalizeTag(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "#", "#1st", "#two words", "#a.b", "#" + strings.Repeat("x", MaxTagLength+1)} {
+		if _, err := NormalizeTag(input); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("NormalizeTag(%q): expected ErrInvalidTag, got %v", input, err)
+		}
+	}
+}
+
+func TestExtractTags(t *testing.T) {
+	rest, tags := ExtractTags("apple - яблоко - red apple #Food #fruit #food #1")
+	if rest != "apple - яблоко - red apple #1" {
+		t.Errorf("Unexpected rest: %q", rest)
+	}
+	if strings.Join(tags, ",") != "food,fruit" {
+		t.Errorf("Expected food and fruit, got %v", tags)
+	}
+}
+
+func TestWordService_Tags(t *testing.T) {
+	_, wordService := newTestServices(t)
+	if err := wordService.AddWord(testUserID, "apple", "яблоко", "", "#Fruit"); err != nil {
+		t.Fatalf("Failed to add word: %v", err)
+	}
+	addTestWords(t, wordService, "train", "поезд")
+	words, _ := wordService.GetUserWords(testUserID)
+	train, apple := words[0], words[1]
+
+	tags, err := wordService.AddTags(testUserID, train.ID, []string{"#travel", "work"})
+	if err != nil || strings.Join(tags, ",") != "travel,work" {
+		t.Fatalf("Expected travel and work, got %v, %v", tags, err)
+	}
+	if tags, _ := wordService.RemoveTags(testUserID, train.ID, []string{"work"}); strings.Join(tags, ",") != "travel" {
+		t.Errorf("Expected only travel to remain, got %v", tags)
+	}
+	if _, err := wordService.AddTags(testUserID, train.ID, nil); !errors.Is(err, ErrNoTags) {
+		t.Errorf("Expected ErrNoTags, got %v", err)
+	}
+	if _, err := wordService.AddTags(testUserID, train.ID, []string{"#1"}); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("Expected ErrInvalidTag, got %v", err)
+	}
+	if _, err := wordService.AddTags(testUserID+1, train.ID, []string{"travel"}); !errors.Is(err, ErrWordNotFound) {
+		t.Errorf("Expected ErrWordNotFound for someone else's word, got %v", err)
+	}
+
+	// Тег без слов не находится
+	if _, err := wordService.FindTag(testUserID, "work"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("Expected ErrTagNotFound for an unused tag, got %v", err)
+	}
+	fruit, err := wordService.FindTag(testUserID, "#FRUIT")
+	if err != nil || fruit.Words != 1 {
+		t.Fatalf("Expected fruit tag with one word, got %+v, %v", fruit, err)
+	}
+	if _, err := wordService.GetTag(testUserID+1, fruit.ID); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("Expected ErrTagNotFound for someone else's tag, got %v", err)
+	}
+
+	page, err := wordService.ListWords(testUserID, repository.WordSortRecent, 0, WordsPageSize, fruit.ID)
+	if err != nil || page.Total != 1 || page.Words[0].ID != apple.ID || page.Tag.Name != "fruit" {
+		t.Fatalf("Expected only apple in the fruit list, got %+v, %v", page, err)
+	}
+	if strings.Join(page.Words[0].Tags, ",") != "fruit" {
+		t.Errorf("Expected the page to carry word tags, got %v", page.Words[0].Tags)
+	}
+}
+
+func TestWordService_NextCardByTag(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко", "train", "поезд")
+	words, _ := wordService.GetUserWords(testUserID)
+	for _, word := range words {
+		state := word.ReviewState
+		state.NextReview = time.Now().Add(-time.Hour)
+		if err := wordService.wordRepo.SaveReviewState(word.ID, state); err != nil {
+			t.Fatalf("Failed to save review state: %v", err)
+		}
+	}
+	train := words[0]
+	if _, err := wordService.AddTags(testUserID, train.ID, []string{"travel"}); err != nil {
+		t.Fatalf("Failed to tag word: %v", err)
+	}
+	travel, _ := wordService.FindTag(testUserID, "travel")
+
+	card, err := wordService.NextCard(testUserID, travel.ID)
+	if err != nil || card == nil || card.ID != train.ID {
+		t.Fatalf("Expected the travel card, got %+v, %v", card, err)
+	}
+	if _, err := wordService.GradeCard(repository.SchedulerSM2, testUserID, card.ID, RatingGood, 0); err != nil {
+		t.Fatalf("Failed to grade card: %v", err)
+	}
+	if card, _ := wordService.NextCard(testUserID, travel.ID); card != nil {
+		t.Errorf("Expected no more travel cards, got %+v", card)
+	}
+}
+
+func TestQuizService_StartRoundByTag(t *testing.T) {
+	quizService, wordService, _ := newTestQuizService(t)
+	words, _ := wordService.GetUserWords(testUserID)
+	tagged := map[int]bool{words[0].ID: true, words[1].ID: true}
+	for id := range tagged {
+		if _, err := wordService.AddTags(testUserID, id, []string{"exam"}); err != nil {
+			t.Fatalf("Failed to tag word: %v", err)
+		}
+	}
+	exam, _ := wordService.FindTag(testUserID, "exam")
+
+	round, session, err := quizService.StartRound(testUserID, testUserID, 5, repository.DirectionForward, exam.ID)
+	if err != nil {
+		t.Fatalf("Failed to start round: %v", err)
+	}
+	if round.Size != 2 || round.TagID != exam.ID {
+		t.Fatalf("Expected a round of the 2 tagged words, got %+v", round)
+	}
+
+	for session != nil {
+		if !tagged[session.WordID] {
+			t.Fatalf("Expected only tagged words, got word %d", session.WordID)
+		}
+		result, err := quizService.AnswerQuiz(repository.SchedulerSM2, testUserID, session.ID, session.CorrectIdx)
+		if err != nil {
+			t.Fatalf("Failed to answer: %v", err)
+		}
+		session = result.Next
+	}
+}
+
+func TestAddWizardService_KeepsTags(t *testing.T) {
+	wizard, _, wordService := newTestAddWizard(t)
+
+	if err := wizard.Start(testUserID); err != nil {
+		t.Fatalf("Failed to start wizard: %v", err)
+	}
+	for _, input := range []string{"apple #food", "яблоко", "red apple #fruit"} {
+		if _, err := wizard.Input(testUserID, input); err != nil {
+			t.Fatalf("Failed to input %q: %v", input, err)
+		}
+	}
+
+	words, _ := wordService.GetUserWords(testUserID)
+	if len(words) != 1 || words[0].Word != "apple" || words[0].Context != "red apple" {
+		t.Fatalf("Expected apple without tags in the fields, got %+v", words)
+	}
+	if err := wordService.LoadTags(testUserID, words); err != nil || strings.Join(words[0].Tags, ",") != "food,fruit" {
+		t.Errorf("Expected food and fruit tags, got %v, %v", words[0].Tags, err)
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// ErrNoTypedQuestion возвращается, если пользователь не ждет вопроса с вводом ответа
+var ErrNoTypedQuestion = errors.New("no typed question is pending")
+
+// typedStatePrefix начинает users.state, пока бот ждет введенный ответ.
+// Полное состояние: typing:<направление>:<ID слова>:<время вопроса в миллисекундах Unix>.
+const typedStatePrefix = "typing:"
+
+// TypedQuizService задает вопросы, на которые пользователь отвечает текстом.
+// Ожидаемый ответ хранится в состоянии пользователя, поэтому следующее
+// сообщение без команды считается ответом.
+type TypedQuizService struct {
+	wordService *WordService
+	userService *UserService
+	now         func() time.Time
+}
+
+// NewTypedQuizService создает сервис вопросов с вводом ответа
+func NewTypedQuizService(wordService *WordService, userService *UserService) *TypedQuizService {
+	return &TypedQuizService{
+		wordService: wordService,
+		userService: userService,
+		now:         time.Now,
+	}
+}
+
+// TypedQuestion — вопрос, на который пользователь отвечает текстом
+type TypedQuestion struct {
+	WordID    int
+	Direction string
+	Prompt    string // Что показать: слово в прямом направлении, перевод в обратном
+}
+
+// TypedResult — результат проверки введенного ответа
+type TypedResult struct {
+	AnswerMatch
+	Word      *repository.Word
+	Direction string
+	Expected  string // Все правильные варианты, как они записаны у слова
+	Change    *ReviewChange
+}
+
+// Start выбирает слово, запоминает вопрос в состоянии пользователя и возвращает его.
+// В смешанном режиме направление выбирается случайно.
+func (s *TypedQuizService) Start(userID int64, mode string) (*TypedQuestion, error) {
+	if !IsKnownQuizMode(mode) {
+		return nil, fmt.Errorf("unknown quiz mode %q", mode)
+	}
+
+	direction := QuizDirection(mode, rand.Intn(2))
+	word, err := s.wordService.PickWord(userID, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.UpdateUserState(userID, typedState(direction, word.ID, s.now())); err != nil {
+		return nil, err
+	}
+
+	return &TypedQuestion{
+		WordID:    word.ID,
+		Direction: direction,
+		Prompt:    typedPrompt(word, direction),
+	}, nil
+}
+
+// Pending сообщает, ждет ли бот от пользователя введенный ответ
+func (s *TypedQuizService) Pending(userID int64) (bool, error) {
+	user, err := s.userService.GetUser(userID)
+	if err != nil {
+		return false, err
+	}
+	return user != nil && IsTypedState(user.State), nil
+}
+
+// IsTypedState сообщает, что в состоянии пользователя записан вопрос с вводом ответа
+func IsTypedState(state string) bool {
+	return strings.HasPrefix(state, typedStatePrefix)
+}
+
+// Answer проверяет введенный ответ и обновляет расписание слова в направлении вопроса.
+// Ответ принимается один раз: состояние пользователя сбрасывается до проверки.
+func (s *TypedQuizService) Answer(scheduler string, userID int64, answer string) (*TypedResult, error) {
+	user, err := s.userService.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrNoTypedQuestion
+	}
+	direction, wordID, askedAt, ok := parseTypedState(user.State)
+	if !ok {
+		return nil, ErrNoTypedQuestion
+	}
+
+	if err := s.userService.UpdateUserState(userID, StateIdle); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if now.Sub(askedAt) > quizSessionTTL {
+		return nil, ErrQuizSessionExpired
+	}
+
+	word, err := s.wordService.GetWord(userID, wordID)
+	if err != nil {
+		return nil, err
+	}
+	if word == nil {
+		return nil, ErrQuizSessionNotFound // Слово удалили, пока пользователь думал
+	}
+
+	expected := word.Translation
+	if direction == repository.DirectionReverse {
+		expected = word.Word
+	}
+	match := MatchAnswer(answer, expected)
+
+	change, err := s.wordService.RecordAnswer(scheduler, QuizAnswer{
+		UserID:       userID,
+		WordID:       wordID,
+		ChosenOption: strings.TrimSpace(answer),
+		Direction:    direction,
+		Correct:      match.Grade != GradeWrong,
+		Almost:       match.Grade == GradeAlmost,
+		Latency:      now.Sub(askedAt),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedResult{
+		AnswerMatch: match,
+		Word:        word,
+		Direction:   direction,
+		Expected:    expected,
+		Change:      change,
+	}, nil
+}
+
+// GiveUp засчитывает вопрос как неотвеченный и возвращает правильный ответ
+func (s *TypedQuizService) GiveUp(scheduler string, userID int64) (*TypedResult, error) {
+	return s.Answer(scheduler, userID, "")
+}
+
+// typedPrompt возвращает то, что показывается в вопросе
+func typedPrompt(word *repository.Word, direction string) string {
+	if direction == repository.DirectionReverse {
+		return word.Translation
+	}
+	return word.Word
+}
+
+// typedState кодирует ожидаемый ответ в users.state
+func typedState(direction string, wordID int, askedAt time.Time) string {
+	return fmt.Sprintf("%s%s:%d:%d", typedStatePrefix, direction, wordID, askedAt.UnixMilli())
+}
+
+// parseTypedState разбирает состояние, записанное typedState
+func parseTypedState(state string) (direction string, wordID int, askedAt time.Time, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(state, typedStatePrefix), ":")
+	if !strings.HasPrefix(state, typedStatePrefix) || len(parts) != 3 {
+		return "", 0, time.Time{}, false
+	}
+
+	wordID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, time.Time{}, false
+	}
+	millis, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", 0, time.Time{}, false
+	}
+
+	return QuizDirection(parts[0], 0), wordID, time.UnixMilli(millis), true
+}
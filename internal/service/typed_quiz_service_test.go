@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// newTestTypedQuizService создает сервис вопросов с вводом ответа и одним словом
+func newTestTypedQuizService(t *testing.T) (*TypedQuizService, *WordService, *time.Time) {
+	t.Helper()
+
+	userService, wordService := newTestServices(t)
+	addTestWords(t, wordService, "beautiful", "красивый, прекрасный")
+
+	now := time.Now()
+	typedService := NewTypedQuizService(wordService, userService)
+	typedService.now = func() time.Time { return now }
+
+	return typedService, wordService, &now
+}
+
+func TestTypedQuizService_Answer(t *testing.T) {
+	typedService, wordService, now := newTestTypedQuizService(t)
+
+	question, err := typedService.Start(testUserID, repository.DirectionForward)
+	if err != nil {
+		t.Fatalf("Failed to start typed quiz: %v", err)
+	}
+	if question.Prompt != "beautiful" || question.Direction != repository.DirectionForward {
+		t.Errorf("Unexpected question: %+v", question)
+	}
+	if pending, _ := typedService.Pending(testUserID); !pending {
+		t.Error("Expected an answer to be pending")
+	}
+
+	*now = now.Add(3 * time.Second)
+	result, err := typedService.Answer(repository.SchedulerSM2, testUserID, "Прекрасный!")
+	if err != nil {
+		t.Fatalf("Failed to answer: %v", err)
+	}
+	if result.Grade != GradeCorrect || result.Closest != "прекрасный" {
+		t.Errorf("Expected the second alternative to match, got %+v", result.AnswerMatch)
+	}
+	if result.Change.After.Repetitions != 1 {
+		t.Errorf("Expected the word to be reviewed, got %+v", result.Change.After)
+	}
+
+	history, _ := wordService.GetAnswerHistory(testUserID, 10)
+	if len(history) != 1 || !history[0].Correct || history[0].ChosenOption != "Прекрасный!" ||
+		history[0].LatencyMs != 3000 {
+		t.Errorf("Expected typed answer in the log, got %+v", history)
+	}
+
+	// Ответ принимается только один раз
+	if pending, _ := typedService.Pending(testUserID); pending {
+		t.Error("Expected no pending answer after grading")
+	}
+	_, err = typedService.Answer(repository.SchedulerSM2, testUserID, "красивый")
+	if !errors.Is(err, ErrNoTypedQuestion) {
+		t.Errorf("Expected ErrNoTypedQuestion, got %v", err)
+	}
+}
+
+func TestTypedQuizService_AlmostAndReverse(t *testing.T) {
+	typedService, wordService, _ := newTestTypedQuizService(t)
+
+	question, err := typedService.Start(testUserID, repository.DirectionReverse)
+	if err != nil {
+		t.Fatalf("Failed to start typed quiz: %v", err)
+	}
+	if question.Prompt != "красивый, прекрасный" {
+		t.Errorf("Expected the translation as prompt, got %q", question.Prompt)
+	}
+
+	result, err := typedService.Answer(repository.SchedulerSM2, testUserID, "the beatiful")
+	if err != nil {
+		t.Fatalf("Failed to answer: %v", err)
+	}
+	if result.Grade != GradeAlmost || result.Expected != "beautiful" {
+		t.Errorf("Expected almost correct answer, got %+v", result)
+	}
+	// Ответ с опечаткой засчитывается как трудный
+	if result.Change.After.Repetitions != 1 || result.Change.After.EaseFactor >= 2.5 {
+		t.Errorf("Expected a hard review, got %+v", result.Change.After)
+	}
+
+	reverse, _ := wordService.wordRepo.GetReverseReviewState(question.WordID)
+	if reverse == nil {
+		t.Error("Expected the reverse schedule to be updated")
+	}
+}
+
+func TestTypedQuizService_Errors(t *testing.T) {
+	typedService, wordService, now := newTestTypedQuizService(t)
+
+	if _, err := typedService.GiveUp(repository.SchedulerSM2, testUserID); !errors.Is(err, ErrNoTypedQuestion) {
+		t.Errorf("Expected ErrNoTypedQuestion without a question, got %v", err)
+	}
+
+	if _, err := typedService.Start(testUserID, repository.DirectionForward); err != nil {
+		t.Fatalf("Failed to start typed quiz: %v", err)
+	}
+	*now = now.Add(quizSessionTTL + time.Minute)
+	_, err := typedService.Answer(repository.SchedulerSM2, testUserID, "красивый")
+	if !errors.Is(err, ErrQuizSessionExpired) {
+		t.Errorf("Expected ErrQuizSessionExpired, got %v", err)
+	}
+
+	question, err := typedService.Start(testUserID, repository.DirectionForward)
+	if err != nil {
+		t.Fatalf("Failed to start typed quiz: %v", err)
+	}
+	result, err := typedService.GiveUp(repository.SchedulerSM2, testUserID)
+	if err != nil || result.Grade != GradeWrong {
+		t.Errorf("Expected giving up to count as wrong, got %+v, %v", result, err)
+	}
+
+	if _, err := typedService.Start(testUserID, repository.DirectionForward); err != nil {
+		t.Fatalf("Failed to start typed quiz: %v", err)
+	}
+	if err := wordService.DeleteWord(question.WordID, testUserID); err != nil {
+		t.Fatalf("Failed to delete word: %v", err)
+	}
+	_, err = typedService.Answer(repository.SchedulerSM2, testUserID, "красивый")
+	if !errors.Is(err, ErrQuizSessionNotFound) {
+		t.Errorf("Expected ErrQuizSessionNotFound for a deleted word, got %v", err)
+	}
+}
+
+func TestTypedState(t *testing.T) {
+	askedAt := time.UnixMilli(1760000000123)
+	state := typedState(repository.DirectionReverse, 42, askedAt)
+
+	direction, wordID, got, ok := parseTypedState(state)
+	if !ok || direction != repository.DirectionReverse || wordID != 42 || !got.Equal(askedAt) {
+		t.Errorf("Failed to round-trip %q: %q %d %v %v", state, direction, wordID, got, ok)
+	}
+	if len(state) > 50 {
+		t.Errorf("State %q does not fit users.state", state)
+	}
+
+	for _, bad := range []string{StateIdle, "typing:", "typing:forward:x:1", "typing:forward:1"} {
+		if _, _, _, ok := parseTypedState(bad); ok {
+			t.Errorf("Expected %q not to parse", bad)
+		}
+	}
+}
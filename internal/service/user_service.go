@@ -1,14 +1,19 @@
 package service
 
 import (
+	"fmt"
+
 	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
 )
 
+// StateIdle — состояние пользователя, который ничего не вводит в ответ боту
+const StateIdle = "idle"
+
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo repository.UserStore
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+func NewUserService(userRepo repository.UserStore) *UserService {
 	return &UserService{userRepo: userRepo}
 }
 
@@ -19,7 +24,7 @@ func (s *UserService) RegisterUser(userID int64, username, firstName, lastName s
 		Username:  username,
 		FirstName: firstName,
 		LastName:  lastName,
-		State:     "idle",
+		State:     StateIdle,
 	}
 
 	return s.userRepo.CreateOrUpdateUser(user)
@@ -34,3 +39,77 @@ func (s *UserService) GetUser(userID int64) (*repository.User, error) {
 func (s *UserService) UpdateUserState(userID int64, state string) error {
 	return s.userRepo.UpdateUserState(userID, state)
 }
+
+// UpdateUserStateData обновляет состояние пользователя вместе с его данными
+func (s *UserService) UpdateUserStateData(userID int64, state, data string) error {
+	return s.userRepo.UpdateUserStateData(userID, state, data)
+}
+
+// SetScheduler сохраняет алгоритм интервального повторения пользователя
+func (s *UserService) SetScheduler(userID int64, scheduler string) error {
+	if !IsKnownScheduler(scheduler) {
+		return fmt.Errorf("unknown scheduler %q", scheduler)
+	}
+
+	return s.userRepo.UpdateUserScheduler(userID, scheduler)
+}
+
+// GetScheduler возвращает алгоритм повторения пользователя (SM-2 по умолчанию)
+func (s *UserService) GetScheduler(userID int64) (string, error) {
+	user, err := s.userRepo.GetUser(userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil || user.Scheduler == "" {
+		return repository.SchedulerSM2, nil
+	}
+
+	return user.Scheduler, nil
+}
+
+// SetQuizMode сохраняет режим /quiz пользователя
+func (s *UserService) SetQuizMode(userID int64, mode string) error {
+	if !IsKnownQuizMode(mode) {
+		return fmt.Errorf("unknown quiz mode %q", mode)
+	}
+
+	return s.userRepo.UpdateUserQuizMode(userID, mode)
+}
+
+// GetQuizMode возвращает режим /quiz пользователя (прямое направление по умолчанию)
+func (s *UserService) GetQuizMode(userID int64) (string, error) {
+	user, err := s.userRepo.GetUser(userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil || user.QuizMode == "" {
+		return repository.DirectionForward, nil
+	}
+
+	return user.QuizMode, nil
+}
+
+// QuizPresentations перечисляет способы показа вопросов /quiz в порядке показа в /settings
+var QuizPresentations = []string{repository.PresentationButtons, repository.PresentationPoll}
+
+// SetQuizPresentation сохраняет способ показа вопросов /quiz пользователя
+func (s *UserService) SetQuizPresentation(userID int64, presentation string) error {
+	if presentation != repository.PresentationButtons && presentation != repository.PresentationPoll {
+		return fmt.Errorf("unknown quiz presentation %q", presentation)
+	}
+
+	return s.userRepo.UpdateUserQuizPresentation(userID, presentation)
+}
+
+// GetQuizPresentation возвращает способ показа вопросов /quiz пользователя (кнопки по умолчанию)
+func (s *UserService) GetQuizPresentation(userID int64) (string, error) {
+	user, err := s.userRepo.GetUser(userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil || user.QuizPresentation == "" {
+		return repository.PresentationButtons, nil
+	}
+
+	return user.QuizPresentation, nil
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// Ограничения импорта слов из CSV/TSV файла
+const (
+	MaxImportFileSize = 1 << 20 // Байт
+	MaxImportRows     = 2000
+	ImportPreviewRows = 5 // Сколько строк показывать до подтверждения импорта
+)
+
+// ImportState — состояние пользователя, которому показан предпросмотр файла
+// со словами; в данных состояния хранится file_id файла в Telegram
+const ImportState = "import"
+
+// Поля слова, которым сопоставляются столбцы таблицы
+const (
+	ColumnWord        = "word"
+	ColumnTranslation = "translation"
+	ColumnContext     = "context"
+	ColumnTags        = "tags"
+)
+
+// Ошибки разбора таблицы слов
+var (
+	ErrEmptyTable   = errors.New("table has no rows")
+	ErrTableColumns = errors.New("table must have word and translation columns")
+	ErrTooManyRows  = errors.New("table has too many rows")
+	ErrTableCharset = errors.New("table must be in UTF-8")
+)
+
+// importDelimiters перечисляет разделители в порядке предпочтения при равенстве
+var importDelimiters = []rune{'\t', ';', ','}
+
+// defaultColumns — поля столбцов таблицы без заголовка
+var defaultColumns = []string{ColumnWord, ColumnTranslation, ColumnContext, ColumnTags}
+
+// columnAliases сопоставляет названия столбцов в заголовке полям слова
+var columnAliases = map[string]string{
+	"word":        ColumnWord,
+	"english":     ColumnWord,
+	"en":          ColumnWord,
+	"term":        ColumnWord,
+	"front":       ColumnWord,
+	"слово":       ColumnWord,
+	"translation": ColumnTranslation,
+	"russian":     ColumnTranslation,
+	"ru":          ColumnTranslation,
+	"meaning":     ColumnTranslation,
+	"back":        ColumnTranslation,
+	"перевод":     ColumnTranslation,
+	"значение":    ColumnTranslation,
+	"context":     ColumnContext,
+	"example":     ColumnContext,
+	"sentence":    ColumnContext,
+	"контекст":    ColumnContext,
+	"пример":      ColumnContext,
+	"tags":        ColumnTags,
+	"tag":         ColumnTags,
+	"теги":        ColumnTags,
+	"тег":         ColumnTags,
+}
+
+// TableRow — строка данных таблицы
+type TableRow struct {
+	Line  int // Номер строки в файле, с единицы
+	Cells []string
+}
+
+// WordTable — таблица слов из CSV/TSV файла
+type WordTable struct {
+	Delimiter rune
+	Header    bool     // Первая строка файла — заголовок
+	Columns   []string // Поле слова для каждого столбца; пустая строка — столбец пропускается
+	Rows      []TableRow
+}
+
+// ParseWordTable разбирает CSV или TSV файл со словами. Разделитель (табуляция,
+// «;» или «,») определяется по первым строкам, а заголовок — по названиям
+// столбцов вроде word/translation или слово/перевод. Без заголовка столбцы
+// идут в порядке: слово, перевод, контекст, теги.
+func ParseWordTable(data []byte) (*WordTable, error) {
+	data = bytes.TrimPrefix(data, []byte("\uFEFF")) // Excel сохраняет UTF-8 с BOM
+	if !utf8.Valid(data) {
+		return nil, ErrTableCharset
+	}
+
+	table := &WordTable{Delimiter: detectDelimiter(data)}
+	reader := newTableReader(data, table.Delimiter)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		if table.Columns == nil {
+			if columns, ok := headerColumns(record); ok {
+				table.Header, table.Columns = true, columns
+				continue
+			}
+			table.Columns = defaultColumns
+		}
+
+		if len(table.Rows) == MaxImportRows {
+			return nil, ErrTooManyRows
+		}
+		table.Rows = append(table.Rows, TableRow{Line: line, Cells: record})
+	}
+
+	if len(table.Rows) == 0 {
+		return nil, ErrEmptyTable
+	}
+	if !hasColumn(table.Columns, ColumnWord) || !hasColumn(table.Columns, ColumnTranslation) {
+		return nil, ErrTableColumns
+	}
+	return table, nil
+}
+
+// Column возвращает номер столбца с полем field или -1, если такого столбца нет
+func (t *WordTable) Column(field string) int {
+	for i, column := range t.Columns {
+		if column == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// RowResult разбирает строку таблицы в слово пользователя userID. Если в строке
+// нет слова или перевода либо тег неверный, возвращается результат LineInvalid.
+func (t *WordTable) RowResult(userID int64, row TableRow) LineResult {
+	result := LineResult{Line: row.Line}
+	cell := func(field string) string {
+		if i := t.Column(field); i >= 0 && i < len(row.Cells) {
+			return strings.TrimSpace(row.Cells[i])
+		}
+		return ""
+	}
+
+	word := &repository.Word{
+		UserID:      userID,
+		Word:        cell(ColumnWord),
+		Translation: cell(ColumnTranslation),
+		Context:     cell(ColumnContext),
+	}
+	if word.Word == "" || word.Translation == "" {
+		result.Status, result.Err = LineInvalid, ErrAddFieldRequired
+		return result
+	}
+	if tags := splitTagCell(cell(ColumnTags)); len(tags) > 0 {
+		var err error
+		if word.Tags, err = normalizeTags(tags); err != nil {
+			result.Status, result.Err = LineInvalid, err
+			return result
+		}
+	}
+
+	result.Word = word
+	return result
+}
+
+// ImportWordTable добавляет слова из таблицы в активную колоду одной транзакцией
+// и возвращает результат по каждой строке: добавлено, уже было или ошибка
+func (s *WordService) ImportWordTable(userID int64, table *WordTable) ([]LineResult, error) {
+	results := make([]LineResult, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		results = append(results, table.RowResult(userID, row))
+	}
+	return s.addWordLines(userID, results)
+}
+
+// detectDelimiter выбирает разделитель, с которым первые строки файла делятся
+// на одинаковое число столбцов, не меньше двух
+func detectDelimiter(data []byte) rune {
+	best, bestScore := importDelimiters[0], -1
+	for _, delimiter := range importDelimiters {
+		reader := newTableReader(data, delimiter)
+		score, width := 0, 0
+		for i := 0; i < 10; i++ {
+			record, err := reader.Read()
+			if err != nil {
+				break
+			}
+			if isBlankRecord(record) {
+				continue
+			}
+			if width == 0 {
+				width = len(record)
+			}
+			if len(record) >= 2 && len(record) == width {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = delimiter, score
+		}
+	}
+	return best
+}
+
+// newTableReader возвращает терпимый к неровным строкам читатель CSV
+func newTableReader(data []byte, delimiter rune) *csv.Reader {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	return reader
+}
+
+// headerColumns сопоставляет столбцы заголовка полям слова; ok = false,
+// если запись не похожа на заголовок
+func headerColumns(record []string) ([]string, bool) {
+	columns := make([]string, len(record))
+	known := 0
+	for i, name := range record {
+		if field, ok := columnAliases[strings.ToLower(strings.TrimSpace(name))]; ok && !hasColumn(columns, field) {
+			columns[i] = field
+			known++
+		}
+	}
+	return columns, known > 0
+}
+
+// splitTagCell делит ячейку тегов на теги: они разделяются пробелами, запятыми
+// или «;», а «#» в начале необязателен
+func splitTagCell(cell string) []string {
+	return strings.FieldsFunc(cell, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == ';'
+	})
+}
+
+// isBlankRecord сообщает, что в записи нет ни одной непустой ячейки
+func isBlankRecord(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasColumn сообщает, есть ли среди столбцов поле field
+func hasColumn(columns []string, field string) bool {
+	for _, column := range columns {
+		if column == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseWordTable(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		delimiter rune
+		header    bool
+		columns   []string
+		rows      int
+		firstLine int
+	}{
+		{
+			name:      "tsv without header",
+			data:      "apple\tяблоко\n\npear\tгруша\ta ripe pear\n",
+			delimiter: '\t',
+			columns:   defaultColumns,
+			rows:      2,
+			firstLine: 1,
+		},
+		{
+			name:      "csv with quoted commas",
+			data:      "apple,яблоко,\"red, sweet\"\npear,груша,\n",
+			delimiter: ',',
+			columns:   defaultColumns,
+			rows:      2,
+			firstLine: 1,
+		},
+		{
+			name:      "excel csv with russian header",
+			data:      "\uFEFFПеревод;Слово;Пример;Теги\nяблоко;apple;an apple a day;food\n",
+			delimiter: ';',
+			header:    true,
+			columns:   []string{ColumnTranslation, ColumnWord, ColumnContext, ColumnTags},
+			rows:      1,
+			firstLine: 2,
+		},
+		{
+			name:      "header with unknown column",
+			data:      "word,notes,translation\napple,fruit,яблоко\n",
+			delimiter: ',',
+			header:    true,
+			columns:   []string{ColumnWord, "", ColumnTranslation},
+			rows:      1,
+			firstLine: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		table, err := ParseWordTable([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: failed to parse table: %v", tt.name, err)
+			continue
+		}
+		if table.Delimiter != tt.delimiter || table.Header != tt.header || len(table.Rows) != tt.rows {
+			t.Errorf("%s: got delimiter %q, header %v, %d rows", tt.name, table.Delimiter, table.Header, len(table.Rows))
+			continue
+		}
+		if strings.Join(table.Columns, ",") != strings.Join(tt.columns, ",") {
+			t.Errorf("%s: expected columns %v, got %v", tt.name, tt.columns, table.Columns)
+		}
+		if table.Rows[0].Line != tt.firstLine {
+			t.Errorf("%s: expected the first row on line %d, got %d", tt.name, tt.firstLine, table.Rows[0].Line)
+		}
+	}
+}
+
+func TestParseWordTable_Errors(t *testing.T) {
+	tests := []struct {
+		data string
+		err  error
+	}{
+		{"\n \n", ErrEmptyTable},
+		{"apple;\xff\xfe\n", ErrTableCharset}, // Windows-1251 вместо UTF-8
+		{"word,translation\n", ErrEmptyTable},
+		{"context,tags\nan apple a day,food\n", ErrTableColumns},
+		{strings.Repeat("apple,яблоко\n", MaxImportRows+1), ErrTooManyRows},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseWordTable([]byte(tt.data)); !errors.Is(err, tt.err) {
+			t.Errorf("ParseWordTable(%.30q): expected %v, got %v", tt.data, tt.err, err)
+		}
+	}
+}
+
+func TestWordService_ImportWordTable(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+
+	data := "word;translation;context;tags\n" +
+		"Apple;яблоко;;\n" +
+		"pear;груша;a ripe pear;#Food, fruit\n" +
+		"plum;;;\n" +
+		"lemon;лимон;;1st\n" +
+		"PEAR;груша;;\n" +
+		"train;поезд\n"
+	table, err := ParseWordTable([]byte(data))
+	if err != nil {
+		t.Fatalf("Failed to parse table: %v", err)
+	}
+	results, err := wordService.ImportWordTable(testUserID, table)
+	if err != nil {
+		t.Fatalf("Failed to import table: %v", err)
+	}
+
+	want := []struct {
+		line   int
+		status string
+	}{
+		{2, LineDuplicate},
+		{3, LineAdded},
+		{4, LineInvalid},
+		{5, LineInvalid}, // Тег не может начинаться с цифры
+		{6, LineDuplicate},
+		{7, LineAdded},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %+v", len(want), results)
+	}
+	for i, w := range want {
+		if results[i].Line != w.line || results[i].Status != w.status {
+			t.Errorf("Result %d: expected line %d %s, got line %d %s", i, w.line, w.status, results[i].Line, results[i].Status)
+		}
+	}
+	if !errors.Is(results[2].Err, ErrAddFieldRequired) || !errors.Is(results[3].Err, ErrInvalidTag) {
+		t.Errorf("Unexpected errors: %v, %v", results[2].Err, results[3].Err)
+	}
+
+	words, _ := wordService.GetDeckWords(testUserID)
+	if len(words) != 3 {
+		t.Fatalf("Expected 3 words in the active deck, got %v", words)
+	}
+	pear, _ := wordService.FindDuplicate(testUserID, "pear")
+	if pear == nil || pear.Context != "a ripe pear" {
+		t.Fatalf("Expected pear with context, got %+v", pear)
+	}
+	if tags, _ := wordService.wordTags(testUserID, pear.ID); strings.Join(tags, ",") != "food,fruit" {
+		t.Errorf("Expected pear tags food and fruit, got %v", tags)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+// WordsPageSize — сколько слов показывается на одной странице /words
+const WordsPageSize = 10
+
+// WordSorts перечисляет порядки списка /words в порядке показа кнопок
+var WordSorts = []string{
+	repository.WordSortRecent, repository.WordSortAlpha, repository.WordSortHardest, repository.WordSortDue,
+}
+
+// ErrUnknownWordSort возвращается для неизвестного порядка списка слов
+var ErrUnknownWordSort = errors.New("unknown word sort")
+
+// WordPage — страница списка слов пользователя
+type WordPage struct {
+	Words  []*repository.Word // Слова страницы вместе с их тегами
+	Sort   string
+	Tag    *repository.Tag  // Список только слов с этим тегом из всех колод; nil — все слова колоды
+	Deck   *repository.Deck // Колода списка без тега; nil, если колод у пользователя еще нет
+	FromID int              // Первое слово страницы; 0 — начало списка
+	Offset int              // Сколько слов идет перед страницей
+	Total  int
+
+	HasPrev bool
+	PrevID  int // С какого слова начинается предыдущая страница
+	HasNext bool
+	NextID  int // С какого слова начинается следующая страница
+}
+
+// ListWords возвращает страницу из size слов в порядке sort, начиная со слова fromID.
+// Если такого слова у пользователя больше нет или оно в другой колоде, список
+// показывается с начала.
+// Если задан tagID, в списке только слова с этим тегом, иначе — слова активной колоды.
+func (s *WordService) ListWords(userID int64, sort string, fromID, size, tagID int) (*WordPage, error) {
+	if !isKnownWordSort(sort) {
+		return nil, ErrUnknownWordSort
+	}
+
+	var tag *repository.Tag
+	var deck *repository.Deck
+	var err error
+	if tagID != 0 {
+		if tag, err = s.GetTag(userID, tagID); err != nil {
+			return nil, err
+		}
+	} else if deck, err = s.ActiveDeck(userID); err != nil {
+		return nil, err
+	}
+	deckID := 0
+	if deck != nil {
+		deckID = deck.ID
+	}
+
+	if fromID != 0 {
+		anchor, err := s.GetWord(userID, fromID)
+		if err != nil {
+			return nil, err
+		}
+		if anchor == nil || (deck != nil && anchor.DeckID != deck.ID) {
+			fromID = 0
+		}
+	}
+
+	query := repository.WordListQuery{UserID: userID, Sort: sort, FromID: fromID, Limit: size + 1, TagID: tagID,
+		DeckID: deckID}
+	words, err := s.wordRepo.ListWords(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &WordPage{Words: words, Sort: sort, Tag: tag, Deck: deck, FromID: fromID}
+	if len(words) > size {
+		page.Words, page.HasNext, page.NextID = words[:size], true, words[size].ID
+	}
+	if err := s.LoadTags(userID, page.Words); err != nil {
+		return nil, err
+	}
+
+	if fromID != 0 {
+		before := repository.WordListQuery{UserID: userID, Sort: sort, FromID: fromID, Backward: true, Limit: size,
+			TagID: tagID, DeckID: deckID}
+		prev, err := s.wordRepo.ListWords(before)
+		if err != nil {
+			return nil, err
+		}
+		if len(prev) > 0 {
+			page.HasPrev, page.PrevID = true, prev[0].ID
+		}
+
+		before.Limit = 0
+		if page.Offset, err = s.wordRepo.CountWords(before); err != nil {
+			return nil, err
+		}
+	}
+
+	page.Total, err = s.wordRepo.CountWords(repository.WordListQuery{UserID: userID, Sort: sort, TagID: tagID,
+		DeckID: deckID})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// CountWords возвращает, сколько слов в активной колоде пользователя; если задан
+// tagID — сколько у него слов с этим тегом во всех колодах
+func (s *WordService) CountWords(userID int64, tagID int) (int, error) {
+	query := repository.WordListQuery{UserID: userID, Sort: repository.WordSortRecent, TagID: tagID}
+	if tagID == 0 {
+		deckID, err := s.activeDeckID(userID)
+		if err != nil {
+			return 0, err
+		}
+		query.DeckID = deckID
+	}
+	return s.wordRepo.CountWords(query)
+}
+
+// isKnownWordSort сообщает, есть ли порядок списка слов с таким именем
+func isKnownWordSort(sort string) bool {
+	for _, known := range WordSorts {
+		if sort == known {
+			return true
+		}
+	}
+	return false
+}
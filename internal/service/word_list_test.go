@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
+)
+
+func TestWordService_ListWords_Pages(t *testing.T) {
+	_, wordService := newTestServices(t)
+	for i := 1; i <= 5; i++ {
+		addTestWords(t, wordService, fmt.Sprintf("word%d", i), fmt.Sprintf("слово%d", i))
+	}
+
+	first, err := wordService.ListWords(testUserID, repository.WordSortAlpha, 0, 2, 0)
+	if err != nil {
+		t.Fatalf("Failed to list words: %v", err)
+	}
+	if len(first.Words) != 2 || first.Words[0].Word != "word1" || first.HasPrev || !first.HasNext || first.Total != 5 {
+		t.Fatalf("Unexpected first page: %+v", first)
+	}
+
+	second, _ := wordService.ListWords(testUserID, repository.WordSortAlpha, first.NextID, 2, 0)
+	if second.Words[0].Word != "word3" || second.Offset != 2 || !second.HasPrev || second.PrevID != first.Words[0].ID {
+		t.Fatalf("Unexpected second page: %+v", second)
+	}
+
+	last, _ := wordService.ListWords(testUserID, repository.WordSortAlpha, second.NextID, 2, 0)
+	if len(last.Words) != 1 || last.Words[0].Word != "word5" || last.HasNext || last.Offset != 4 {
+		t.Fatalf("Unexpected last page: %+v", last)
+	}
+
+	// Если первое слово страницы удалено, список начинается сначала
+	if err := wordService.DeleteWord(last.Words[0].ID, testUserID); err != nil {
+		t.Fatalf("Failed to delete word: %v", err)
+	}
+	page, _ := wordService.ListWords(testUserID, repository.WordSortAlpha, last.FromID, 2, 0)
+	if page.FromID != 0 || page.Words[0].Word != "word1" || page.Total != 4 {
+		t.Errorf("Expected the list to restart, got %+v", page)
+	}
+
+	if _, err := wordService.ListWords(testUserID, "random", 0, 2, 0); !errors.Is(err, ErrUnknownWordSort) {
+		t.Errorf("Expected ErrUnknownWordSort, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"fmt"
 	"log"
+	"math/rand"
 	"strings"
 	"time"
 
@@ -10,25 +11,51 @@ import (
 )
 
 type WordService struct {
-	wordRepo *repository.WordRepository
+	wordRepo     repository.WordStore
+	reviewLog    repository.ReviewLogStore
+	newWordRatio float64 // Доля вопросов теста по новым словам
 }
 
-func NewWordService(wordRepo *repository.WordRepository) *WordService {
-	return &WordService{wordRepo: wordRepo}
+func NewWordService(wordRepo repository.WordStore, reviewLog repository.ReviewLogStore) *WordService {
+	return &WordService{wordRepo: wordRepo, reviewLog: reviewLog, newWordRatio: DefaultNewWordRatio}
 }
 
-// AddWord добавляет новое слово
-func (s *WordService) AddWord(userID int64, word, translation, context string) error {
+// AddWord добавляет новое слово с тегами tags (см. NormalizeTag) в активную колоду.
+// Если такое слово у пользователя уже есть (в любой колоде), возвращается
+// *DuplicateWordError с найденным словом.
+func (s *WordService) AddWord(userID int64, word, translation, context string, tags ...string) error {
 	// Проверяем, что слово и перевод не пустые
 	if strings.TrimSpace(word) == "" || strings.TrimSpace(translation) == "" {
 		return fmt.Errorf("word and translation cannot be empty")
 	}
 
+	if len(tags) > 0 {
+		var err error
+		if tags, err = normalizeTags(tags); err != nil {
+			return err
+		}
+	}
+
+	existing, err := s.FindDuplicate(userID, word)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return &DuplicateWordError{Existing: existing}
+	}
+
+	deck, err := s.ensureActiveDeck(userID)
+	if err != nil {
+		return err
+	}
+
 	newWord := &repository.Word{
 		UserID:      userID,
+		DeckID:      deck.ID,
 		Word:        strings.TrimSpace(word),
 		Translation: strings.TrimSpace(translation),
 		Context:     strings.TrimSpace(context),
+		Tags:        tags,
 	}
 
 	return s.wordRepo.SaveWord(newWord)
@@ -39,14 +66,205 @@ func (s *WordService) GetUserWords(userID int64) ([]*repository.Word, error) {
 	return s.wordRepo.GetUserWords(userID)
 }
 
+// GetWord получает слово пользователя по ID; для чужого или удаленного слова возвращает nil
+func (s *WordService) GetWord(userID int64, wordID int) (*repository.Word, error) {
+	word, err := s.wordRepo.GetWord(wordID)
+	if err != nil {
+		return nil, err
+	}
+	if word == nil || word.UserID != userID {
+		return nil, nil
+	}
+	return word, nil
+}
+
 // GetWordsForReview получает слова для повторения
 func (s *WordService) GetWordsForReview(userID int64) ([]*repository.Word, error) {
 	return s.wordRepo.GetWordsForReview(userID)
 }
 
-// UpdateWordReview обновляет статус повторения слова
-func (s *WordService) UpdateWordReview(wordID int, correct bool) error {
-	return s.wordRepo.UpdateWordReview(wordID, correct)
+// ReviewWord пересчитывает расписание слова пользователя в прямом направлении
+// алгоритмом scheduler и сохраняет новое состояние
+func (s *WordService) ReviewWord(scheduler string, userID int64, wordID int,
+	result ReviewResult) (*repository.ReviewState, error) {
+	_, state, err := s.reviewWord(scheduler, userID, wordID, repository.DirectionForward, result)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// QuizAnswer описывает ответ пользователя в тесте с вариантами ответа
+type QuizAnswer struct {
+	UserID       int64
+	WordID       int
+	ChosenOption string
+	Direction    string // Направление вопроса; пустое значение означает прямое
+	Correct      bool
+	Almost       bool          // Ответ засчитан, но с опечаткой: слово повторяется как трудное
+	Latency      time.Duration // Время от показа вопроса до ответа
+}
+
+// ReviewChange описывает, как повторение изменило состояние слова
+type ReviewChange struct {
+	Before repository.ReviewState
+	After  repository.ReviewState
+}
+
+// RecordAnswer обновляет расписание слова в направлении вопроса по результату теста
+// и записывает ответ в журнал. Если ответ не удалось записать в журнал, расписание
+// возвращается к прежнему, чтобы повторный ответ не сдвинул его дважды.
+func (s *WordService) RecordAnswer(scheduler string, answer QuizAnswer) (*ReviewChange, error) {
+	result := ResultFromAnswer(answer.Correct)
+	if answer.Correct && answer.Almost {
+		result = ResultFromQuality(QualityHard)
+	}
+
+	direction := QuizDirection(answer.Direction, 0)
+	before, after, err := s.reviewWord(scheduler, answer.UserID, answer.WordID, direction, result)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.reviewLog.SaveQuiz(&repository.Quiz{
+		UserID:         answer.UserID,
+		WordID:         answer.WordID,
+		ChosenOption:   answer.ChosenOption,
+		Direction:      direction,
+		Correct:        answer.Correct,
+		IntervalBefore: before.Interval,
+		IntervalAfter:  after.Interval,
+		LatencyMs:      answer.Latency.Milliseconds(),
+	})
+	if err != nil {
+		if restoreErr := s.saveDirectionState(answer.WordID, direction, *before); restoreErr != nil {
+			log.Printf("Failed to restore review state: %v", restoreErr)
+		}
+		return nil, err
+	}
+
+	return &ReviewChange{Before: *before, After: *after}, nil
+}
+
+// GetAnswerHistory возвращает последние ответы пользователя, начиная с новых
+func (s *WordService) GetAnswerHistory(userID int64, limit int) ([]*repository.Quiz, error) {
+	return s.reviewLog.GetUserQuizzes(userID, limit)
+}
+
+// reviewWord сохраняет новое состояние слова в направлении direction и возвращает
+// состояния до и после повторения
+func (s *WordService) reviewWord(scheduler string, userID int64, wordID int, direction string,
+	result ReviewResult) (*repository.ReviewState, *repository.ReviewState, error) {
+	before, state, err := s.planReview(scheduler, userID, wordID, direction, result)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.saveDirectionState(wordID, direction, *state); err != nil {
+		return nil, nil, err
+	}
+
+	return before, state, nil
+}
+
+// planReview возвращает состояние слова в направлении direction до и после
+// повторения, ничего не сохраняя. Чужое слово считается ненайденным, чтобы
+// не раскрывать его существование.
+func (s *WordService) planReview(scheduler string, userID int64, wordID int, direction string,
+	result ReviewResult) (*repository.ReviewState, *repository.ReviewState, error) {
+	if err := result.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	word, err := s.wordRepo.GetWord(wordID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if word == nil || word.UserID != userID {
+		return nil, nil, fmt.Errorf("word %d not found", wordID)
+	}
+
+	before, err := s.directionState(word, direction)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Алгоритм колоды важнее алгоритма пользователя из /settings
+	if word.DeckID != 0 {
+		deck, err := s.wordRepo.GetDeck(word.DeckID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if deck != nil && deck.Scheduler != "" {
+			scheduler = deck.Scheduler
+		}
+	}
+
+	state, _ := SchedulerByName(scheduler).Schedule(before, result, time.Now())
+	return &before, &state, nil
+}
+
+// ConvertWordsToScheduler переводит состояние повторения слов пользователя
+// в обоих направлениях в параметры нового алгоритма. Слова колод со своим
+// алгоритмом (см. SetDeckScheduler) не меняются.
+func (s *WordService) ConvertWordsToScheduler(userID int64, scheduler string) error {
+	decks, err := s.wordRepo.GetUserDecks(userID)
+	if err != nil {
+		return err
+	}
+	own := make(map[int]bool, len(decks))
+	for _, deck := range decks {
+		if deck.Scheduler != "" {
+			own[deck.ID] = true
+		}
+	}
+
+	return s.convertWords(userID, scheduler, func(word *repository.Word) bool { return !own[word.DeckID] })
+}
+
+// convertWords переводит в параметры алгоритма scheduler состояние тех слов
+// пользователя, для которых include возвращает true
+func (s *WordService) convertWords(userID int64, scheduler string, include func(*repository.Word) bool) error {
+	states, reverse, err := s.convertedStates(userID, scheduler, include)
+	if err != nil {
+		return err
+	}
+	if err := s.wordRepo.SaveReviewStates(states); err != nil {
+		return err
+	}
+	return s.wordRepo.SaveReverseReviewStates(reverse)
+}
+
+// convertedStates возвращает состояния слов, отобранных include, переведенные
+// в параметры алгоритма scheduler, в прямом и обратном направлениях, не сохраняя их
+func (s *WordService) convertedStates(userID int64, scheduler string,
+	include func(*repository.Word) bool) (states, reverse map[int]repository.ReviewState, err error) {
+	words, err := s.wordRepo.GetUserWords(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target := SchedulerByName(scheduler)
+	now := time.Now()
+	states = make(map[int]repository.ReviewState, len(words))
+	for _, word := range words {
+		if include(word) {
+			states[word.ID] = target.Convert(word.ReviewState, now)
+		}
+	}
+
+	reverse, err = s.wordRepo.GetReverseReviewStates(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for wordID, state := range reverse {
+		if _, ok := states[wordID]; !ok {
+			delete(reverse, wordID)
+			continue
+		}
+		reverse[wordID] = target.Convert(state, now)
+	}
+
+	return states, reverse, nil
 }
 
 // DeleteWord удаляет слово
@@ -57,59 +275,164 @@ func (s *WordService) DeleteWord(wordID int, userID int64) error {
 // QuizQuestion представляет вопрос для теста
 type QuizQuestion struct {
 	WordID     int
+	Direction  string
 	Question   string
 	Options    []string
 	CorrectIdx int
 }
 
-// GenerateQuiz генерирует тест для пользователя
-func (s *WordService) GenerateQuiz(userID int64) (*QuizQuestion, error) {
+// quizOptionCount — количество вариантов ответа в тесте
+const quizOptionCount = 4
+
+// GenerateQuiz генерирует тест для пользователя в направлении direction. Загадываемое
+// слово выбирается по расписанию повторений в этом направлении (см. pickQuizTarget),
+// а неправильные варианты подбираются по сходству с ответом (см. pickDistractors).
+// Слова из exclude не загадываются, но могут попасть в варианты. Если задан tagID,
+// загадываются только слова с этим тегом, иначе — слова активной колоды;
+// варианты в обоих случаях берутся из всего словаря.
+func (s *WordService) GenerateQuiz(userID int64, exclude map[int]bool, direction string,
+	tagID int) (*QuizQuestion, error) {
 	log.Printf("Generating quiz for user %d", userID)
 	words, err := s.wordRepo.GetUserWords(userID) // Используем GetUserWords
 	if err != nil {
 		log.Printf("Failed to get words for quiz: %v", err)
 		return nil, fmt.Errorf("failed to get words for quiz: %w", err)
 	}
-	if len(words) < 4 {
-		log.Printf("Not enough words for quiz: %d", len(words))
-		return nil, fmt.Errorf("need at least 4 words to generate quiz")
+	if len(words) == 0 {
+		log.Printf("No words for quiz")
+		return nil, fmt.Errorf("no words to generate quiz")
 	}
 
-	// Выбираем случайное слово
-	r := randv2.New(randv2.NewSource(time.Now().UnixNano()))
-	targetIdx := r.Intn(len(words))
-	targetWord := words[targetIdx]
-
-	// Создаем варианты ответов
-	options := make([]string, 4)
-	correctIdx := r.Intn(4)
-	options[correctIdx] = targetWord.Translation
-
-	// Отслеживаем использованные индексы
-	usedIndices := map[int]bool{targetIdx: true}
-	optionIdx := 0
-
-	// Заполняем остальные варианты
-	for optionIdx < 4 {
-		if optionIdx == correctIdx {
-			optionIdx++
-			continue
+	if tagID != 0 {
+		tagged, err := s.taggedWords(userID, tagID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get words for quiz: %w", err)
+		}
+		untagged := make(map[int]bool, len(words))
+		for _, word := range words {
+			if exclude[word.ID] || !tagged[word.ID] {
+				untagged[word.ID] = true
+			}
+		}
+		exclude = untagged
+	} else {
+		deckID, err := s.activeDeckID(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get words for quiz: %w", err)
 		}
-		randIdx := r.Intn(len(words))
-		if !usedIndices[randIdx] {
-			options[optionIdx] = words[randIdx].Translation
-			usedIndices[randIdx] = true
-			optionIdx++
+		if deckID != 0 {
+			outside := make(map[int]bool, len(words))
+			for _, word := range words {
+				if exclude[word.ID] || word.DeckID != deckID {
+					outside[word.ID] = true
+				}
+			}
+			exclude = outside
+		}
+	}
+
+	// Теги нужны, чтобы предпочесть варианты из той же темы (см. distractorScore)
+	if err := s.LoadTags(userID, words); err != nil {
+		return nil, fmt.Errorf("failed to get words for quiz: %w", err)
+	}
+
+	// Загадываем слово с учетом расписания повторений
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	targetWord, err := s.pickWord(r, userID, words, exclude, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	// Неправильные варианты подбираем похожими на правильный ответ: сначала из
+	// словаря пользователя, затем из встроенного списка частотных слов
+	others := make([]*repository.Word, 0, len(words)-1)
+	for _, word := range words {
+		if word.ID != targetWord.ID {
+			others = append(others, word)
 		}
 	}
+	correct := quizOption(targetWord, direction)
+	distractors := pickDistractors(r, wordDistractor(targetWord, direction), wordDistractors(others, direction),
+		frequencyDistractors(direction), quizOptionCount-1)
+	if len(distractors) < quizOptionCount-1 {
+		return nil, fmt.Errorf("not enough distractors for quiz: %d", len(distractors))
+	}
+
+	// Создаем варианты ответов
+	correctIdx := r.Intn(quizOptionCount)
+	options := make([]string, 0, quizOptionCount)
+	options = append(options, distractors[:correctIdx]...)
+	options = append(options, correct)
+	options = append(options, distractors[correctIdx:]...)
 
 	// Логируем варианты для отладки
 	log.Printf("Quiz options: %v, correctIdx: %d", options, correctIdx)
 
+	question := fmt.Sprintf("Как переводится слово: %s?", targetWord.Word)
+	if direction == repository.DirectionReverse {
+		question = fmt.Sprintf("Как по-английски: %s?", targetWord.Translation)
+	}
+
 	return &QuizQuestion{
 		WordID:     targetWord.ID,
-		Question:   fmt.Sprintf("Как переводится слово: %s?", targetWord.Word),
+		Direction:  QuizDirection(direction, 0),
+		Question:   question,
 		Options:    options,
 		CorrectIdx: correctIdx,
 	}, nil
 }
+
+// PickWord выбирает слово для вопроса без вариантов ответа в направлении direction
+// из активной колоды так же, как GenerateQuiz. Для такого вопроса хватает одного слова.
+func (s *WordService) PickWord(userID int64, direction string) (*repository.Word, error) {
+	words, err := s.wordRepo.GetUserWords(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get words for quiz: %w", err)
+	}
+	deckID, err := s.activeDeckID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get words for quiz: %w", err)
+	}
+	if deckID != 0 {
+		inDeck := words[:0]
+		for _, word := range words {
+			if word.DeckID == deckID {
+				inDeck = append(inDeck, word)
+			}
+		}
+		words = inDeck
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return s.pickWord(r, userID, words, nil, direction)
+}
+
+// pickWord выбирает загадываемое слово среди words, кроме слов из exclude
+func (s *WordService) pickWord(r *rand.Rand, userID int64, words []*repository.Word, exclude map[int]bool,
+	direction string) (*repository.Word, error) {
+	scheduled, err := s.wordsInDirection(userID, words, direction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get words for quiz: %w", err)
+	}
+
+	candidates := make([]*repository.Word, 0, len(words))
+	for _, word := range scheduled {
+		if !exclude[word.ID] {
+			candidates = append(candidates, word)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no words left for quiz")
+	}
+
+	return pickQuizTarget(r, candidates, s.newWordRatio, time.Now()), nil
+}
+
+// quizOption возвращает вариант ответа для слова: перевод в прямом направлении
+// и само слово в обратном
+func quizOption(word *repository.Word, direction string) string {
+	if direction == repository.DirectionReverse {
+		return word.Word
+	}
+	return word.Translation
+}
@@ -2,8 +2,27 @@ package service
 
 import (
 	"testing"
+
+	"github.com/AndrePim/telegram_english_learn_bot/internal/repository"
 )
 
+const testUserID int64 = 123
+
+// newTestServices создает сервисы поверх хранилища в памяти с зарегистрированным пользователем
+func newTestServices(t *testing.T) (*UserService, *WordService) {
+	t.Helper()
+
+	db := repository.NewMemoryDatabase()
+	userService := NewUserService(repository.NewMemoryUserRepository(db))
+	wordService := NewWordService(repository.NewMemoryWordRepository(db), repository.NewMemoryReviewLogRepository(db))
+
+	if err := userService.RegisterUser(testUserID, "tester", "Test", ""); err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	return userService, wordService
+}
+
 func TestWordService_AddWord_EmptyWord(t *testing.T) {
 	// Создаем мок репозитория (в реальном проекте лучше использовать интерфейсы)
 	wordService := &WordService{}
@@ -30,3 +49,254 @@ func TestWordService_AddWord_EmptyTranslation(t *testing.T) {
 		t.Errorf("Expected specific error message, got: %s", err.Error())
 	}
 }
+
+func TestWordService_AddWord_EmptyFieldsSaveNothing(t *testing.T) {
+	_, wordService := newTestServices(t)
+
+	if err := wordService.AddWord(testUserID, " ", "translation", "context"); err == nil {
+		t.Error("Expected error for blank word, got nil")
+	}
+	if err := wordService.AddWord(testUserID, "word", " ", "context"); err == nil {
+		t.Error("Expected error for blank translation, got nil")
+	}
+
+	words, err := wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	if len(words) != 0 {
+		t.Errorf("Expected no words to be saved, got %+v", words)
+	}
+}
+
+func TestWordService_AddWord_TrimsFields(t *testing.T) {
+	_, wordService := newTestServices(t)
+
+	if err := wordService.AddWord(testUserID, "  apple ", " яблоко ", " red apple "); err != nil {
+		t.Fatalf("Failed to add word: %v", err)
+	}
+
+	words, err := wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	if len(words) != 1 {
+		t.Fatalf("Expected 1 word, got %d", len(words))
+	}
+	if w := words[0]; w.Word != "apple" || w.Translation != "яблоко" || w.Context != "red apple" {
+		t.Errorf("Expected trimmed fields, got: %+v", w)
+	}
+}
+
+func TestWordService_GenerateQuiz_NoWords(t *testing.T) {
+	_, wordService := newTestServices(t)
+
+	if _, err := wordService.GenerateQuiz(testUserID, nil, repository.DirectionForward, 0); err == nil {
+		t.Error("Expected error without words, got nil")
+	}
+}
+
+func TestWordService_GenerateQuiz_TopsUpFewWords(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко", "pear", "груша")
+
+	for _, direction := range []string{repository.DirectionForward, repository.DirectionReverse} {
+		quiz, err := wordService.GenerateQuiz(testUserID, nil, direction, 0)
+		if err != nil {
+			t.Fatalf("GenerateQuiz(%s) failed: %v", direction, err)
+		}
+		if len(quiz.Options) != 4 {
+			t.Fatalf("Expected 4 options, got %v", quiz.Options)
+		}
+
+		seen := make(map[string]bool)
+		for _, option := range quiz.Options {
+			if option == "" || seen[option] {
+				t.Errorf("Options must be distinct and non-empty: %v", quiz.Options)
+			}
+			seen[option] = true
+		}
+
+		// Второе слово пользователя похоже на ответ больше частотных и должно попасть в варианты
+		own := map[string]bool{"яблоко": true, "груша": true, "apple": true, "pear": true}
+		count := 0
+		for _, option := range quiz.Options {
+			if own[option] {
+				count++
+			}
+		}
+		if count != 2 {
+			t.Errorf("Expected both user words among options, got %v", quiz.Options)
+		}
+	}
+}
+
+func TestWordService_GenerateQuiz(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService,
+		"apple", "яблоко",
+		"pear", "груша",
+		"plum", "слива",
+		"cherry", "вишня",
+		"lemon", "лимон",
+	)
+
+	words, err := wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	byID := make(map[int]*repository.Word)
+	for _, w := range words {
+		byID[w.ID] = w
+	}
+
+	for i := 0; i < 20; i++ {
+		quiz, err := wordService.GenerateQuiz(testUserID, nil, repository.DirectionForward, 0)
+		if err != nil {
+			t.Fatalf("Failed to generate quiz: %v", err)
+		}
+
+		target, ok := byID[quiz.WordID]
+		if !ok {
+			t.Fatalf("Quiz refers to unknown word %d", quiz.WordID)
+		}
+		if len(quiz.Options) != 4 {
+			t.Fatalf("Expected 4 options, got %d", len(quiz.Options))
+		}
+		if quiz.Options[quiz.CorrectIdx] != target.Translation {
+			t.Errorf("Expected correct option %q, got %q", target.Translation, quiz.Options[quiz.CorrectIdx])
+		}
+
+		seen := make(map[string]bool)
+		for _, option := range quiz.Options {
+			if option == "" || seen[option] {
+				t.Fatalf("Expected 4 distinct non-empty options, got %v", quiz.Options)
+			}
+			seen[option] = true
+		}
+	}
+}
+
+func TestWordService_ReviewWord(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко")
+
+	words, err := wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := wordService.ReviewWord(repository.SchedulerSM2, testUserID, words[0].ID, ResultFromQuality(QualityGood))
+		if err != nil {
+			t.Fatalf("Failed to review word: %v", err)
+		}
+	}
+
+	words, err = wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	if words[0].Interval != 6 {
+		t.Errorf("Expected interval 6 after two correct answers, got %d", words[0].Interval)
+	}
+
+	_, err = wordService.ReviewWord(repository.SchedulerSM2, testUserID, words[0].ID, ResultFromQuality(6))
+	if err == nil {
+		t.Error("Expected error for quality above 5, got nil")
+	}
+	_, err = wordService.ReviewWord(repository.SchedulerSM2, testUserID, words[0].ID+100, ResultFromAnswer(true))
+	if err == nil {
+		t.Error("Expected error for unknown word, got nil")
+	}
+	_, err = wordService.ReviewWord(repository.SchedulerSM2, testUserID+1, words[0].ID, ResultFromAnswer(true))
+	if err == nil {
+		t.Error("Expected error for someone else's word, got nil")
+	}
+}
+
+func TestWordService_ConvertWordsToScheduler(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService, "apple", "яблоко", "pear", "груша")
+
+	words, err := wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	// words[0] — последнее добавленное слово (pear), его повторяем дважды
+	learned := words[0]
+	for i := 0; i < 2; i++ {
+		_, err := wordService.ReviewWord(repository.SchedulerSM2, testUserID, learned.ID, ResultFromQuality(QualityGood))
+		if err != nil {
+			t.Fatalf("Failed to review word: %v", err)
+		}
+	}
+	before, err := wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+
+	if err := wordService.ConvertWordsToScheduler(testUserID, repository.SchedulerFSRS); err != nil {
+		t.Fatalf("Failed to convert words: %v", err)
+	}
+
+	after, err := wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	for i := range after {
+		if after[i].Interval != before[i].Interval || !after[i].NextReview.Equal(before[i].NextReview) {
+			t.Errorf("Expected schedule of %q to be kept, got interval %d next %v",
+				after[i].Word, after[i].Interval, after[i].NextReview)
+		}
+	}
+	if after[0].Stability != 6 || after[0].FSRSDifficulty != 5 {
+		t.Errorf("Expected stability 6 and difficulty 5, got %v and %v",
+			after[0].Stability, after[0].FSRSDifficulty)
+	}
+	if after[1].Stability != 0 {
+		t.Errorf("Expected new word to stay unlearned, got stability %v", after[1].Stability)
+	}
+}
+
+func TestWordService_GenerateQuiz_Exclude(t *testing.T) {
+	_, wordService := newTestServices(t)
+	addTestWords(t, wordService,
+		"apple", "яблоко",
+		"pear", "груша",
+		"plum", "слива",
+		"cherry", "вишня",
+	)
+
+	words, err := wordService.GetUserWords(testUserID)
+	if err != nil {
+		t.Fatalf("Failed to get words: %v", err)
+	}
+	exclude := map[int]bool{words[0].ID: true, words[1].ID: true, words[2].ID: true}
+
+	for i := 0; i < 10; i++ {
+		quiz, err := wordService.GenerateQuiz(testUserID, exclude, repository.DirectionForward, 0)
+		if err != nil {
+			t.Fatalf("Failed to generate quiz: %v", err)
+		}
+		if quiz.WordID != words[3].ID {
+			t.Fatalf("Expected the only not excluded word %d, got %d", words[3].ID, quiz.WordID)
+		}
+	}
+
+	exclude[words[3].ID] = true
+	if _, err := wordService.GenerateQuiz(testUserID, exclude, repository.DirectionForward, 0); err == nil {
+		t.Error("Expected error when every word is excluded, got nil")
+	}
+}
+
+// addTestWords добавляет пары слово/перевод
+func addTestWords(t *testing.T, wordService *WordService, pairs ...string) {
+	t.Helper()
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if err := wordService.AddWord(testUserID, pairs[i], pairs[i+1], ""); err != nil {
+			t.Fatalf("Failed to add word %q: %v", pairs[i], err)
+		}
+	}
+}
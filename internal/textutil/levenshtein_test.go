@@ -0,0 +1,21 @@
+package textutil
+
+import "testing"
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "кот", 3},
+		{"kitten", "sitting", 3},
+		{"яблоко", "яблако", 1},
+	}
+
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
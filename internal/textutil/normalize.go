@@ -0,0 +1,11 @@
+package textutil
+
+import "strings"
+
+// NormalizeWord приводит слово к виду, по которому проверяется уникальность:
+// нижний регистр, ё как е, без пробелов по краям и повторяющихся пробелов.
+// «Apple», «apple» и «apple » считаются одним словом.
+func NormalizeWord(word string) string {
+	word = strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+	return strings.Join(strings.Fields(word), " ")
+}
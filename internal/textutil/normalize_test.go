@@ -0,0 +1,21 @@
+package textutil
+
+import "testing"
+
+func TestNormalizeWord(t *testing.T) {
+	tests := map[string]string{
+		"Apple":           "apple",
+		" apple ":         "apple",
+		"Ice   Cream":     "ice cream",
+		"Ёлка":            "елка",
+		"look\tafter":     "look after",
+		"don't":           "don't",
+		"apple-tree":      "apple-tree",
+		"  TO  Give UP  ": "to give up",
+	}
+	for word, want := range tests {
+		if got := NormalizeWord(word); got != want {
+			t.Errorf("NormalizeWord(%q) = %q, want %q", word, got, want)
+		}
+	}
+}